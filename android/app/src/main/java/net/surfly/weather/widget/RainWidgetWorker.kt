@@ -293,8 +293,8 @@ class RainWidgetWorker(
      * the **rainy** state draws the dual-provider chart into the [R.id.chart]
      * bitmap; the **dry** state hides the chart and shows the native Material
      * [R.id.dry_body] island cells (crisp text, no fitXY bitmap squish).
-     * Returns the caption for the shared headline line: the Buienalarm
-     * nowcast message when present, else the dry fallback or a rainy peak
+     * Returns the caption for the shared headline line: the server's rain
+     * summary (or Buienalarm's nowcast message) when present, else the dry fallback or a rainy peak
      * summary; "no nowcast data" when neither provider returned points.
      */
     private fun applyBody(
@@ -306,9 +306,11 @@ class RainWidgetWorker(
         views.setImageViewResource(R.id.condition, conditionDrawable(response.condition))
         val alarmData = response.buienalarm?.data ?: emptyList()
         val radarData = response.buienradar?.data ?: emptyList()
-        // Prefer Buienalarm's nowcast message ("It will stay dry for now") —
-        // authoritative and human-readable.
-        val alarmMessage = response.buienalarm?.desc?.takeIf { it.isNotBlank() && it != "Buienalarm" }
+        // Prefer the server's merged rain summary ("Light rain from 14:10 (in
+        // 10 min) for 25 min, …"), which reads both providers; fall back to
+        // Buienalarm's own message for servers that predate it.
+        val alarmMessage = response.analysis?.summary?.takeIf { it.isNotBlank() }
+            ?: response.buienalarm?.desc?.takeIf { it.isNotBlank() && it != "Buienalarm" }
         val hasNowcast = ChartRenderer.hasNowcast(alarmData, radarData)
         val dryWindow = hasNowcast && ChartRenderer.isDryWindow(alarmData, radarData)
 
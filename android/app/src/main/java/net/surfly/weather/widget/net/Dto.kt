@@ -23,12 +23,27 @@ data class SeriesDto(
     val type: Int = 0,
 )
 
+// The server's merged reading of both rain providers; the widget only needs
+// a subset of it.
+@Serializable
+data class RainAnalysisDto(
+    val dry: Boolean = true,
+    val raining: Boolean = false,
+    @SerialName("starts_at") val startsAt: String? = null,   // RFC3339
+    @SerialName("stops_at") val stopsAt: String? = null,     // RFC3339
+    @SerialName("peak_mm_h") val peakMmH: Double = 0.0,
+    @SerialName("total_mm") val totalMm: Double = 0.0,
+    val agreement: Double? = null,   // 0..1, null with one provider
+    val summary: String = "",
+)
+
 // Note: the server JSON key is the misspelled "buineradar" — match it exactly.
 @Serializable
 data class RainResponse(
     val location: LocationDto = LocationDto(),
     val buienalarm: SeriesDto? = null,
     @SerialName("buineradar") val buienradar: SeriesDto? = null,
+    val analysis: RainAnalysisDto? = null,
 )
 
 @Serializable
@@ -55,6 +70,18 @@ data class SunEventDto(
     val time: String = "",   // RFC3339 with offset
 )
 
+// Next leg of the server's configured commute; absent when none is set up.
+@Serializable
+data class CommuteDto(
+    val leg: String = "",      // "morning" | "evening"
+    val depart: String = "",   // usual departure, RFC3339 with offset
+    @SerialName("shift_min") val shiftMin: Int = 0,
+    @SerialName("rain_mm") val rainMm: Double = 0.0,
+    val dry: Boolean = true,
+    @SerialName("tailwind_kmh") val tailwindKmh: Int = 0,
+    val advice: String = "",
+)
+
 @Serializable
 data class GlanceResponse(
     val location: LocationDto = LocationDto(),
@@ -67,4 +94,6 @@ data class GlanceResponse(
     val sun: List<SunEventDto> = emptyList(),
     val sunset: String? = null,
     val condition: String = "clear",
+    val commute: CommuteDto? = null,
+    val analysis: RainAnalysisDto? = null,
 )
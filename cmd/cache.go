@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"image"
 	"sync"
 	"time"
 )
@@ -93,6 +94,29 @@ func memo[T any](c *ttlCache[T], key string, fn func() (T, error)) (T, error) {
 //     stay current.
 //   - Open-Meteo hourly: the model refreshes roughly hourly.
 //   - Open-Meteo daily: refreshes a few times a day.
+//   - Open-Meteo 15-minute: the rapid-update models behind it re-run hourly
+//     too.
+//   - Open-Meteo ensemble: the members are re-run a few times a day, and a
+//     fetch is ~50× the payload of a deterministic one, so it stays longer.
+//   - elevation: terrain doesn't change, so a day is only bounded by memory.
+//   - archive (past weather): settled once the reanalysis covers it.
+//   - climate normals: fixed, and also kept on disk (see GetClimatology); the
+//     in-memory copy only saves re-reading the file.
+//   - radar index (RainViewer): a new frame every 10 min, so a few minutes
+//     keeps the loop current; the frames themselves never change once
+//     published, so the tile cache only bounds memory.
+//   - radar base map: streets don't move; a day keeps the tile host happy.
+//   - radar GIF: the rendered loop per view, as short-lived as the index.
+//   - radar nowcast: the motion analysis per window and newest frame; the
+//     key moves on with each new frame, so the TTL only bounds memory.
+//   - rain trust: the provider weights scored from the verification store,
+//     which only moves a little with each recording pass.
+//   - verification scores: the /verification tables, re-scored from the
+//     whole store; a few minutes behind the recorder is fine.
+//   - config: the file behind per-request server paths, re-read after a
+//     minute so an edit doesn't need a restart.
+//   - glance commute: the configured commute briefed for the widget, per
+//     commute and departure-shift slot.
 //
 // Size caps bound memory under the multiday/today fan-out (many distinct points).
 var (
@@ -100,6 +124,20 @@ var (
 	buineradarCache     = newTTLCache[*Forecast](2*time.Minute, 512)
 	openMeteoRangeCache = newTTLCache[*OpenMeteoData](10*time.Minute, 4096)
 	openMeteoDailyCache = newTTLCache[[]DailyAggregate](30*time.Minute, 512)
+	minutely15Cache     = newTTLCache[[]ForecastDataPoint](10*time.Minute, 512)
+	ensembleCache       = newTTLCache[*EnsembleData](30*time.Minute, 512)
+	elevationCache      = newTTLCache[float64](24*time.Hour, 1<<16)
+	climateCache        = newTTLCache[*Climatology](24*time.Hour, 256)
+	historyCache        = newTTLCache[[]HourlyForecast](24*time.Hour, 256)
+	radarIndexCache     = newTTLCache[*rainViewerIndex](3*time.Minute, 4)
+	radarTileCache      = newTTLCache[[]uint8](30*time.Minute, 512)
+	radarBaseCache      = newTTLCache[*image.Gray](24*time.Hour, 64)
+	radarGIFCache       = newTTLCache[[]byte](2*time.Minute, 64)
+	radarNowcastCache   = newTTLCache[*RadarNowcast](15*time.Minute, 16)
+	rainTrustCache      = newTTLCache[rainTrust](time.Hour, 1)
+	verificationCache   = newTTLCache[*verificationScore](5*time.Minute, 64)
+	configCache         = newTTLCache[Config](time.Minute, 1)
+	commuteGlanceCache  = newTTLCache[*commuteReport](commuteShiftStep, 16)
 )
 
 // locationZones remembers the timezone Open-Meteo reported per rounded
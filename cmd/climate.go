@@ -0,0 +1,331 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jsnjack/termplt"
+)
+
+const (
+	climateFromYear  = 1991 // WMO standard normal period, 1991–2020
+	climateToYear    = 2020
+	climateWindow    = 7    // days either side of a date pooled into its normal
+	climateGridDeg   = 0.25 // ERA5 resolution; finer keys only duplicate fetches
+	climateQuantStep = 5    // percent between stored quantiles
+	climateVersion   = 1    // bump when the on-disk layout changes
+	climateAnomalyC  = 3.0  // departures at least this large are coloured
+)
+
+// ClimateNormal is the climate for one calendar date: means over the normal
+// period and the distribution of daily highs and lows, pooled over
+// ±climateWindow days so thirty years give a few hundred samples. The zero
+// value (no quantiles) means the archive had no data for the date.
+type ClimateNormal struct {
+	TempMax    float64   `json:"tempMax"`    // mean daily high, °C
+	TempMin    float64   `json:"tempMin"`    // mean daily low, °C
+	PrecipMean float64   `json:"precipMean"` // mean mm per day
+	WetDays    float64   `json:"wetDays"`    // share of days with ≥ wetDayMm
+	MaxQ       []float64 `json:"maxQ"`       // quantiles of the high at 0, 5, …, 100 %
+	MinQ       []float64 `json:"minQ"`       // same for the low
+}
+
+// Climatology holds a normal per calendar date for one grid cell. Days is
+// indexed by climateDayIndex: the day of a leap year, so 29 Feb has a slot.
+type Climatology struct {
+	Version  int             `json:"version"`
+	Lat      float64         `json:"lat"`
+	Lon      float64         `json:"lon"`
+	FromYear int             `json:"fromYear"`
+	ToYear   int             `json:"toYear"`
+	Days     []ClimateNormal `json:"days"`
+}
+
+// For returns the normal for t's calendar date.
+func (c *Climatology) For(t time.Time) ClimateNormal {
+	return c.Days[climateDayIndex(t)]
+}
+
+// climateDayIndex maps a date to 0..365 via its month and day in a leap year.
+func climateDayIndex(t time.Time) int {
+	return time.Date(2000, t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).YearDay() - 1
+}
+
+// quantileRank returns where v falls in a distribution given as evenly spaced
+// quantiles (0 … 100 %), interpolating between them: 50 is a typical value,
+// 95 one only one year in twenty beats. Clamped to 0–100; NaN without data.
+func quantileRank(q []float64, v float64) float64 {
+	if len(q) < 2 {
+		return math.NaN()
+	}
+	step := 100 / float64(len(q)-1)
+	if v <= q[0] {
+		return 0
+	}
+	for i := 1; i < len(q); i++ {
+		if v <= q[i] {
+			if q[i] == q[i-1] {
+				return float64(i) * step
+			}
+			return (float64(i-1) + (v-q[i-1])/(q[i]-q[i-1])) * step
+		}
+	}
+	return 100
+}
+
+// GetClimatology returns the 1991–2020 normals for the ERA5 cell around the
+// coordinates. Normals never change, so once computed they live on disk
+// under the user cache dir (~/.cache/weather/climate) as well as in
+// climateCache; only the first request for a cell pays for thirty years of
+// archive data.
+func GetClimatology(lat, lon float64) (*Climatology, error) {
+	lat, lon = snapToGrid(lat, climateGridDeg), snapToGrid(lon, climateGridDeg)
+	key := fmt.Sprintf("%.2f|%.2f", lat, lon)
+	return memo(climateCache, key, func() (*Climatology, error) {
+		path, err := climateCachePath(lat, lon)
+		if err != nil {
+			return nil, err
+		}
+		if c, ok := readClimateFile(path); ok {
+			return c, nil
+		}
+		c, err := fetchClimatology(lat, lon)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeJSONFile(path, c); err != nil {
+			// Still usable this run; the next one just fetches again.
+			slog.Debug("climate: could not cache normals", "path", path, "err", err)
+		}
+		return c, nil
+	})
+}
+
+func climateCachePath(lat, lon float64) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("cache dir: %w", err)
+	}
+	return filepath.Join(dir, "weather", "climate", fmt.Sprintf("%.2f_%.2f.json", lat, lon)), nil
+}
+
+func readClimateFile(path string) (*Climatology, bool) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false
+	}
+	if err != nil {
+		slog.Debug("climate: read cache", "path", path, "err", err)
+		return nil, false
+	}
+	var c Climatology
+	if err := json.Unmarshal(raw, &c); err != nil || c.Version != climateVersion || len(c.Days) != 366 {
+		slog.Debug("climate: ignoring stale or corrupt cache", "path", path, "err", err)
+		return nil, false
+	}
+	return &c, true
+}
+
+// writeJSONFile writes a disk cache entry via a temp file and rename so a
+// crash never leaves a half-written cache behind.
+func writeJSONFile(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// archiveDay is one day of the reanalysis daily aggregates.
+type archiveDay struct {
+	Date    time.Time
+	TempMax float64 // NaN when missing
+	TempMin float64
+	Precip  float64
+}
+
+// getOpenMeteoArchiveDaily fetches daily highs, lows and precipitation from
+// the Open-Meteo historical API for [start, end].
+func getOpenMeteoArchiveDaily(lat, lon float64, start, end time.Time) ([]archiveDay, error) {
+	url := fmt.Sprintf("https://archive-api.open-meteo.com/v1/archive?latitude=%.4f&longitude=%.4f"+
+		"&daily=temperature_2m_max,temperature_2m_min,precipitation_sum&timezone=auto&start_date=%s&end_date=%s",
+		lat, lon, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	body, err := openMeteoGetBody(url)
+	if err != nil {
+		return nil, fmt.Errorf("archive request: %w", err)
+	}
+	var parsed struct {
+		Daily struct {
+			Time    []string   `json:"time"`
+			TempMax []*float64 `json:"temperature_2m_max"`
+			TempMin []*float64 `json:"temperature_2m_min"`
+			Precip  []*float64 `json:"precipitation_sum"`
+		} `json:"daily"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("decode archive: %w", err)
+	}
+	d := parsed.Daily
+	n := len(d.Time)
+	if len(d.TempMax) != n || len(d.TempMin) != n || len(d.Precip) != n {
+		return nil, fmt.Errorf("archive returned inconsistent daily array lengths")
+	}
+	val := func(p *float64) float64 {
+		if p == nil {
+			return math.NaN()
+		}
+		return *p
+	}
+	out := make([]archiveDay, 0, n)
+	for i, ds := range d.Time {
+		day, err := time.Parse("2006-01-02", ds)
+		if err != nil {
+			slog.Debug("archive daily: skipping unparseable date", "date", ds, "err", err)
+			continue
+		}
+		out = append(out, archiveDay{Date: day, TempMax: val(d.TempMax[i]), TempMin: val(d.TempMin[i]), Precip: val(d.Precip[i])})
+	}
+	return out, nil
+}
+
+func fetchClimatology(lat, lon float64) (*Climatology, error) {
+	slog.Debug("climate: fetching normals", "lat", lat, "lon", lon)
+	days, err := getOpenMeteoArchiveDaily(lat, lon,
+		time.Date(climateFromYear, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(climateToYear, 12, 31, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return nil, err
+	}
+	c := computeClimatology(days)
+	c.Lat, c.Lon = lat, lon
+	return c, nil
+}
+
+// computeClimatology pools the archive days into a normal per calendar date.
+// Each date takes every day within ±climateWindow of it in any year,
+// wrapping around the new year.
+func computeClimatology(days []archiveDay) *Climatology {
+	byIndex := make([][]archiveDay, 366)
+	for _, d := range days {
+		i := climateDayIndex(d.Date)
+		byIndex[i] = append(byIndex[i], d)
+	}
+	c := &Climatology{Version: climateVersion, FromYear: climateFromYear, ToYear: climateToYear, Days: make([]ClimateNormal, 366)}
+	for i := range c.Days {
+		var hi, lo, rain []float64
+		for off := -climateWindow; off <= climateWindow; off++ {
+			for _, d := range byIndex[(i+off+366)%366] {
+				hi = append(hi, d.TempMax)
+				lo = append(lo, d.TempMin)
+				rain = append(rain, d.Precip)
+			}
+		}
+		his, los := summarize(hi), summarize(lo)
+		if math.IsNaN(his.Mean) || math.IsNaN(los.Mean) {
+			continue // no data for the date: leave the zero ClimateNormal
+		}
+		n := ClimateNormal{
+			TempMax:    his.Mean,
+			TempMin:    los.Mean,
+			PrecipMean: summarize(rain).Mean,
+			WetDays:    fractionAbove(rain, func(v float64) bool { return v >= wetDayMm }),
+		}
+		if math.IsNaN(n.PrecipMean) {
+			n.PrecipMean = 0
+		}
+		for p := 0; p <= 100; p += climateQuantStep {
+			n.MaxQ = append(n.MaxQ, percentile(hi, float64(p)/100))
+			n.MinQ = append(n.MinQ, percentile(lo, float64(p)/100))
+		}
+		c.Days[i] = n
+	}
+	return c
+}
+
+// withClimate returns a copy of daily with the anomaly fields filled from the
+// normals. On any failure it logs and returns daily unchanged — climate
+// context is an extra, never a reason to fail the forecast. The input may be
+// the shared cached slice, so it is not modified.
+func withClimate(lat, lon float64, daily []DailyAggregate) []DailyAggregate {
+	c, err := GetClimatology(lat, lon)
+	if err != nil {
+		slog.Debug("climate: normals unavailable", "err", err)
+		return daily
+	}
+	out := make([]DailyAggregate, len(daily))
+	for i, d := range daily {
+		n := c.For(d.Date)
+		if len(n.MaxQ) > 0 {
+			d.Climate = &DailyClimate{
+				NormalMax:     n.TempMax,
+				NormalMin:     n.TempMin,
+				MaxAnomaly:    d.TempMax - n.TempMax,
+				MinAnomaly:    d.TempMin - n.TempMin,
+				MaxPercentile: quantileRank(n.MaxQ, d.TempMax),
+				MinPercentile: quantileRank(n.MinQ, d.TempMin),
+				NormalPrecip:  n.PrecipMean,
+			}
+		}
+		out[i] = d
+	}
+	return out
+}
+
+// anomalyLabel formats an anomaly as "+8°" / "−3°", or "±0°".
+func anomalyLabel(a float64) string {
+	r := int(round(a))
+	switch {
+	case r > 0:
+		return fmt.Sprintf("+%d°", r)
+	case r < 0:
+		return fmt.Sprintf("−%d°", -r)
+	}
+	return "±0°"
+}
+
+// percentileLabel formats a rank as "p97"; "record" at either end, where the
+// day beats every year in the normal period.
+func percentileLabel(p float64) string {
+	if p >= 100 || p <= 0 {
+		return "record"
+	}
+	return fmt.Sprintf("p%d", int(round(p)))
+}
+
+// anomalyColor is red for a day climateAnomalyC or more above normal, blue
+// for one as far below, and plain otherwise.
+func anomalyColor(a float64) string {
+	switch anomalyClass(a) {
+	case "anom-warm":
+		return termplt.ColorRed
+	case "anom-cold":
+		return termplt.ColorBlue
+	}
+	return ""
+}
+
+// anomalyClass is the CSS class for an anomaly: "anom-warm", "anom-cold" or
+// "muted".
+func anomalyClass(a float64) string {
+	switch {
+	case a >= climateAnomalyC:
+		return "anom-warm"
+	case a <= -climateAnomalyC:
+		return "anom-cold"
+	}
+	return "muted"
+}
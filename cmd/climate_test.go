@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClimatology(t *testing.T) {
+	// Thirty Januaries: highs on 1 Jan are 1..30 °C across the years, every
+	// other day is 0 °C, so the 1 Jan normal pools both within its window.
+	var days []archiveDay
+	for y := 0; y < 30; y++ {
+		for d := -3; d <= 3; d++ {
+			date := time.Date(1991+y, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, d)
+			hi := 0.0
+			if d == 0 {
+				hi = float64(y + 1)
+			}
+			days = append(days, archiveDay{Date: date, TempMax: hi, TempMin: -5, Precip: 2})
+		}
+	}
+	c := computeClimatology(days)
+	tests := []struct {
+		name    string
+		date    time.Time
+		empty   bool
+		tempMax float64
+		tempMin float64
+		wetDays float64
+	}{
+		{"own date", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), false, 15.5 / 7, -5, 1},
+		{"pools across the new year", time.Date(2026, 12, 30, 0, 0, 0, 0, time.UTC), false, 15.5 / 7, -5, 1},
+		{"no data", time.Date(2026, 7, 20, 0, 0, 0, 0, time.UTC), true, 0, 0, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n := c.For(tc.date)
+			if tc.empty {
+				if len(n.MaxQ) != 0 {
+					t.Fatalf("normal = %+v, want empty", n)
+				}
+				return
+			}
+			if math.Abs(n.TempMax-tc.tempMax) > 1e-9 || n.TempMin != tc.tempMin || n.WetDays != tc.wetDays {
+				t.Fatalf("normal high %v low %v wet %v, want %v, %v and %v", n.TempMax, n.TempMin, n.WetDays, tc.tempMax, tc.tempMin, tc.wetDays)
+			}
+		})
+	}
+}
+
+func TestQuantileRank(t *testing.T) {
+	q := []float64{0, 10, 20}
+	tests := []struct {
+		name string
+		v    float64
+		want float64
+	}{
+		{"below", -1, 0},
+		{"between", 5, 25},
+		{"on the median", 10, 50},
+		{"upper half", 15, 75},
+		{"above", 25, 100},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := quantileRank(q, tc.v); math.Abs(got-tc.want) > 1e-9 {
+				t.Fatalf("quantileRank(%v) = %v, want %v", tc.v, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRenderForecastTableNormalColumns(t *testing.T) {
+	day := func(c *DailyClimate) DailyAggregate {
+		return DailyAggregate{Date: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), TempMax: 18, TempMin: 8, Climate: c}
+	}
+	tests := []struct {
+		name  string
+		daily []DailyAggregate
+		want  bool
+	}{
+		{"without climate", []DailyAggregate{day(nil), day(nil)}, false},
+		{"with climate", []DailyAggregate{day(&DailyClimate{NormalMax: 16, NormalMin: 7, MaxAnomaly: 2}), day(nil)}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			renderForecastTable(&buf, tc.daily, nil)
+			if got := strings.Contains(buf.String(), "ΔHi"); got != tc.want {
+				t.Fatalf("ΔHi column shown = %v, want %v:\n%s", got, tc.want, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jsnjack/termplt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	FlagCommuteFrom    string
+	FlagCommuteTo      string
+	FlagCommuteMorning string
+	FlagCommuteEvening string
+)
+
+var commuteCmd = &cobra.Command{
+	Use:   "commute",
+	Short: "Rain and wind briefing for the next legs of a daily bike commute",
+	Long: `commute briefs the next morning and evening legs of the commute in the
+config file ("commute": {"from", "to", "morning", "evening"}, optionally
+"speed_kmh" and "flex_min"); from and to are saved place names or anything
+that geocodes. The flags override the config. Each leg is sampled along the
+route at the time you pass each point — from the Buienalarm nowcast when the
+ride is within two hours, the Open-Meteo forecast otherwise — and every
+departure within flex_min (default 15) of the usual one is tried, so the
+briefing can say "leave 5 min earlier to stay dry". Also shows the head- or
+tailwind per leg. Mirrors the /commute page and the glance API's commute.`,
+	RunE: runCommute,
+}
+
+func init() {
+	rootCmd.AddCommand(commuteCmd)
+	commuteCmd.Flags().StringVar(&FlagCommuteFrom, "from", "", "home end of the commute (overrides the config)")
+	commuteCmd.Flags().StringVar(&FlagCommuteTo, "to", "", "work end of the commute (overrides the config)")
+	commuteCmd.Flags().StringVar(&FlagCommuteMorning, "morning", "", "usual morning departure, HH:MM (overrides the config)")
+	commuteCmd.Flags().StringVar(&FlagCommuteEvening, "evening", "", "usual evening departure, HH:MM (overrides the config)")
+}
+
+// configuredCommute returns the config's commute with any non-empty
+// overrides (flags, or the /commute query) applied, or an error saying what
+// is missing.
+func configuredCommute(cfg Config, from, to, morning, evening string) (Commute, error) {
+	var c Commute
+	if cfg.Commute != nil {
+		c = *cfg.Commute
+	}
+	for _, o := range []struct {
+		val string
+		dst *string
+	}{{from, &c.From}, {to, &c.To}, {morning, &c.Morning}, {evening, &c.Evening}} {
+		if o.val != "" {
+			*o.dst = o.val
+		}
+	}
+	if c.From == "" || c.To == "" || c.Morning == "" || c.Evening == "" {
+		return c, fmt.Errorf(`no commute configured: add "commute": {"from", "to", "morning", "evening"} to the config, or pass --from, --to, --morning and --evening`)
+	}
+	for _, t := range []string{c.Morning, c.Evening} {
+		if _, err := parseClock(t); err != nil {
+			return c, fmt.Errorf("commute times: %w", err)
+		}
+	}
+	return c, nil
+}
+
+func runCommute(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	c, err := configuredCommute(cfg, FlagCommuteFrom, FlagCommuteTo, FlagCommuteMorning, FlagCommuteEvening)
+	if err != nil {
+		return err
+	}
+
+	prog := NewCLIProgress("weather along the commute")
+	report, err := PlanCommute(c, cfg, time.Now(), prog)
+	prog.Finish()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(termplt.ColorBold+"Commute %s ⇄ %s"+termplt.ColorReset+
+		"  ·  %.1f km  ·  about %.0f min at %.0f km/h\n",
+		report.From.Description, report.To.Description, report.DistanceKm,
+		report.DistanceKm/report.SpeedKmh*60, report.SpeedKmh)
+	for _, l := range report.Legs {
+		fmt.Println()
+		renderCommuteLeg(l)
+	}
+	return nil
+}
+
+func renderCommuteLeg(l commuteLeg) {
+	b, rst := termplt.ColorBold, termplt.ColorReset
+	fmt.Printf("%s%s%s  ·  %s → %s  ·  %s → %s  ·  %s\n",
+		b, l.Name, rst, l.Depart.Format("Mon 15:04"), l.Arrive.Format("15:04"),
+		l.From.Description, l.To.Description, l.Source)
+
+	color := termplt.ColorGreen
+	if l.Options[l.Best].WetMin > 0 {
+		color = termplt.ColorYellow
+	}
+	fmt.Printf("  %s\n", wrap(commuteAdviceSentence(l.Advice), color))
+	if l.HasWind {
+		fmt.Printf("  Wind: %s %s, %.0f°\n", windLabel(l.Tailwind), commuteHeadingLabel(l), l.Temp)
+	}
+
+	var leave, rain strings.Builder
+	for i, o := range l.Options {
+		t := o.Depart.Format("15:04")
+		mm := fmt.Sprintf("%5.1f", o.RainMm)
+		if i == l.Usual {
+			t = b + t + rst
+		}
+		switch {
+		case i == l.Best:
+			mm = wrap(mm, termplt.ColorGreen)
+		case o.WetMin > 0:
+			mm = wrap(mm, termplt.ColorRed)
+		}
+		leave.WriteString(" " + t)
+		rain.WriteString(" " + mm)
+	}
+	fmt.Printf("  Leave at %s\n", leave.String())
+	fmt.Printf("  Rain mm  %s\n", rain.String())
+}
+
+// commuteAdviceSentence capitalises advice for use as a sentence.
+func commuteAdviceSentence(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:] + "."
+}
+
+// commuteHeadingLabel is "heading NE ↗" for the leg's direction of travel.
+func commuteHeadingLabel(l commuteLeg) string {
+	return fmt.Sprintf("heading %s %s", CompassName(l.Bearing), CompassArrow(l.Bearing))
+}
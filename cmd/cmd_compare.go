@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jsnjack/termplt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	FlagCompareHours  int
+	FlagCompareModels []string
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "Compare weather models side by side (HARMONIE, ICON, ECMWF, GFS)",
+	Long: `compare overlays the hourly temperature and precipitation of several
+Open-Meteo models — HARMONIE (KNMI), ICON (DWD), ECMWF IFS and GFS — with
+their mean as a consensus line, and lists the hours where they disagree: 3 °C
+or more apart, or one model wet while another is dry. Mirrors the /compare
+web page.`,
+	RunE: runCompare,
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+	compareCmd.Flags().IntVar(&FlagCompareHours, "hours", 48, "comparison window in hours (6–168)")
+	compareCmd.Flags().StringSliceVar(&FlagCompareModels, "models", nil, "models to compare: harmonie, icon, ecmwf, gfs (default all)")
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	hours := FlagCompareHours
+	if hours < 6 || hours > 168 {
+		return fmt.Errorf("--hours must be between 6 and 168")
+	}
+	models, err := parseCompareModels(FlagCompareModels)
+	if err != nil {
+		return fmt.Errorf("--models: %w", err)
+	}
+
+	loc, err := ResolveLocation()
+	if err != nil {
+		return err
+	}
+	start := time.Now().Truncate(time.Hour)
+	end := start.Add(time.Duration(hours) * time.Hour)
+
+	prog := NewCLIProgress("model forecasts")
+	runs := fetchModelRuns(loc.Latitude, loc.Longitude, start, end, models, prog)
+	prog.Finish()
+
+	var ok []modelRun
+	for _, r := range runs {
+		if r.Err != nil {
+			fmt.Printf("%s%s unavailable: %v%s\n", termplt.ColorYellow, r.Model.Label, r.Err, termplt.ColorReset)
+			continue
+		}
+		ok = append(ok, r)
+	}
+	if len(ok) == 0 {
+		return fmt.Errorf("compare: no model returned data")
+	}
+	consensus := modelConsensus(ok)
+
+	fmt.Printf(termplt.ColorBold+"Model comparison for %s"+termplt.ColorReset+
+		"  ·  %s → %s\n\n",
+		loc.Description, start.Format("Mon 15:04"), end.Format("Mon 15:04"))
+
+	renderCompareChart(ok, consensus, "Temperature", "°C", func(h HourlyForecast) float64 { return h.Temperature },
+		func(c consensusHour) float64 { return c.Temp })
+	fmt.Println()
+	renderCompareChart(ok, consensus, "Precipitation", "mm", func(h HourlyForecast) float64 { return h.Precipitation },
+		func(c consensusHour) float64 { return c.Precip })
+	fmt.Println()
+	renderCompareTable(ok, consensus, hours)
+
+	spans := describeSpans(disagreementSpans(consensus))
+	fmt.Println()
+	if len(spans) == 0 {
+		fmt.Println("The models agree across the window.")
+	} else {
+		fmt.Printf("%sModels disagree:%s %s\n", termplt.ColorYellow, termplt.ColorReset, strings.Join(spans, ", "))
+	}
+	return nil
+}
+
+// renderCompareChart draws one line per model plus the consensus in white.
+func renderCompareChart(runs []modelRun, consensus []consensusHour, title, unit string,
+	val func(HourlyForecast) float64, cval func(consensusHour) float64) {
+	keys := make([]string, 0, len(runs)+1)
+	for _, r := range runs {
+		keys = append(keys, r.Model.Term+r.Model.Label+termplt.ColorReset)
+	}
+	keys = append(keys, termplt.ColorWhite+"consensus"+termplt.ColorReset)
+	fmt.Printf("%s (%s) · %s\n", title, unit, strings.Join(keys, " · "))
+
+	chart := termplt.NewLineChart()
+	for _, r := range runs {
+		x := make([]float64, len(r.Hourly))
+		y := make([]float64, len(r.Hourly))
+		for i, h := range r.Hourly {
+			x[i], y[i] = float64(h.Time.Unix()), val(h)
+		}
+		chart.AddLine(x, y, r.Model.Term)
+	}
+	x := make([]float64, len(consensus))
+	y := make([]float64, len(consensus))
+	for i, c := range consensus {
+		x[i], y[i] = float64(c.Time.Unix()), cval(c)
+	}
+	chart.AddLine(x, y, termplt.ColorWhite)
+	chart.SetXLabelAsTime("", "Mon 15h")
+	chart.SetYLabel(unit)
+	fmt.Print(chart.String())
+}
+
+// renderCompareTable prints temperature and rain per model at a readable
+// cadence — every hour for a day or less, every three beyond — plus every
+// hour the models disagree.
+func renderCompareTable(runs []modelRun, consensus []consensusHour, hours int) {
+	b, rst := termplt.ColorBold, termplt.ColorReset
+	every := 1
+	if hours > 24 {
+		every = 3
+	}
+
+	byModel := make([]map[int64]HourlyForecast, len(runs))
+	fmt.Printf("%s  %-10s", b, "Time")
+	for i, r := range runs {
+		byModel[i] = map[int64]HourlyForecast{}
+		for _, h := range r.Hourly {
+			byModel[i][h.Time.Unix()] = h
+		}
+		fmt.Printf(" %11s", r.Model.Key)
+	}
+	fmt.Printf(" %11s%s\n", "consensus", rst)
+
+	for _, c := range consensus {
+		if c.Time.Hour()%every != 0 && !c.Disagree {
+			continue
+		}
+		fmt.Printf("  %-10s", c.Time.Format("Mon 15:04"))
+		for i := range runs {
+			h, ok := byModel[i][c.Time.Unix()]
+			if !ok {
+				fmt.Printf(" %11s", "—")
+				continue
+			}
+			fmt.Printf(" %11s", compareCellText(h.Temperature, h.Precipitation))
+		}
+		fmt.Printf(" %11s", compareCellText(c.Temp, c.Precip))
+		if c.Disagree {
+			fmt.Printf("  %s≠ %s%s", termplt.ColorYellow, c.Reason, rst)
+		}
+		fmt.Println()
+	}
+}
+
+// compareCellText formats one model's hour as "12° 0.4", or "12°   ·" when dry.
+func compareCellText(temp, precip float64) string {
+	rain := formatPrecip(precip)
+	if rain == "" {
+		rain = "·"
+	}
+	return fmt.Sprintf("%3.0f° %5s", temp, rain)
+}
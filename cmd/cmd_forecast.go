@@ -2,27 +2,53 @@ package cmd
 
 import (
 	"fmt"
-	"strings"
+	"io"
+	"math"
+	"os"
+	"slices"
 	"time"
 
 	"github.com/jsnjack/termplt"
 	"github.com/spf13/cobra"
 )
 
-var FlagForecastDays int
+var (
+	FlagForecastDays     int
+	FlagForecastEnsemble bool
+	FlagForecastClimate  bool
+)
+
+// ensembleMaxDays is how far ahead the ensemble model runs; later forecast
+// days simply show no spread.
+const ensembleMaxDays = 15
 
 var forecastCmd = &cobra.Command{
 	Use:   "forecast",
 	Short: "Multi-day daily outlook (temp hi/lo, rain, wind, UV)",
 	Long: `forecast shows a day-by-day outlook up to 16 days — daily high/low and
 feels-like temperature, total precipitation, wind, gusts, and peak UV index.
-Mirrors the /forecast (14-day) web page so the CLI and browser stay in sync.`,
+Mirrors the /forecast (14-day) web page so the CLI and browser stay in sync.
+
+With --ensemble the ECMWF ensemble adds the spread: whiskers on the
+temperature bar reach from the 10th-percentile low to the 90th-percentile
+high across members, and Wet is the share of members with at least 1 mm that
+day. It downloads all 51 members, about 50 times the deterministic payload,
+so it's off unless asked for. If the ensemble can't be fetched the table is
+shown without them.
+
+With --climate each day is set against the 1991–2020 normals for its date:
+ΔHi is the high's departure from the normal high, Pctl where it ranks among
+the highs of those thirty years, and "┃" on the bar marks the normal high.
+The first run for a location downloads thirty years of archive to build the
+normals, which are then cached on disk.`,
 	RunE: runForecast,
 }
 
 func init() {
 	rootCmd.AddCommand(forecastCmd)
 	forecastCmd.Flags().IntVar(&FlagForecastDays, "days", 14, "number of days (3–16)")
+	forecastCmd.Flags().BoolVar(&FlagForecastEnsemble, "ensemble", false, "show ensemble spread: temperature whiskers and wet-day chance (fetches all 51 members)")
+	forecastCmd.Flags().BoolVar(&FlagForecastClimate, "climate", false, "compare each day with the 1991–2020 normals for its date (downloads 30 years of archive once per location)")
 }
 
 func runForecast(cmd *cobra.Command, args []string) error {
@@ -38,27 +64,52 @@ func runForecast(cmd *cobra.Command, args []string) error {
 	}
 
 	prog := NewCLIProgress("daily forecast")
+	daily, spread, err := dailyOutlook(loc, days, prog)
+	prog.Finish()
+	if err != nil {
+		return err
+	}
+	printForecast(os.Stdout, daily, spread, loc)
+	return nil
+}
+
+// dailyOutlook fetches the daily forecast with the ensemble spread and the
+// climate normals, as the --ensemble and --climate flags ask.
+func dailyOutlook(loc Location, days int, prog Progress) ([]DailyAggregate, map[string]EnsembleDay, error) {
 	prog.AddTotal(1)
 	daily, err := GetOpenMeteoDailyRange(loc.Latitude, loc.Longitude, days)
 	prog.Inc(1)
-	prog.Finish()
 	if err != nil || len(daily) == 0 {
-		return fmt.Errorf("daily forecast: %w", err)
+		return nil, nil, fmt.Errorf("daily forecast: %w", err)
+	}
+	var spread map[string]EnsembleDay
+	if FlagForecastEnsemble {
+		prog.AddTotal(1)
+		spread = ensembleDaysByDate(loc.Latitude, loc.Longitude, min(days, ensembleMaxDays))
+		prog.Inc(1)
+	}
+	if FlagForecastClimate {
+		prog.AddTotal(1)
+		daily = withClimate(loc.Latitude, loc.Longitude, daily)
+		prog.Inc(1)
 	}
+	return daily, spread, nil
+}
 
-	fmt.Printf(termplt.ColorBold+"%d-day forecast for %s"+termplt.ColorReset+
+// printForecast prints the temperature chart and the daily table.
+func printForecast(w io.Writer, daily []DailyAggregate, spread map[string]EnsembleDay, loc Location) {
+	fmt.Fprintf(w, termplt.ColorBold+"%d-day forecast for %s"+termplt.ColorReset+
 		"  ·  %s → %s\n\n",
 		len(daily), loc.Description,
 		daily[0].Date.Format("Mon 2 Jan"), daily[len(daily)-1].Date.Format("Mon 2 Jan"))
 
-	renderForecastTempChart(daily)
-	fmt.Println()
-	renderForecastTable(daily)
-	return nil
+	renderForecastTempChart(w, daily)
+	fmt.Fprintln(w)
+	renderForecastTable(w, daily, spread)
 }
 
-func renderForecastTempChart(daily []DailyAggregate) {
-	fmt.Printf("%sHigh%s · %sLow%s (°C)\n",
+func renderForecastTempChart(w io.Writer, daily []DailyAggregate) {
+	fmt.Fprintf(w, "%sHigh%s · %sLow%s (°C)\n",
 		termplt.ColorRed, termplt.ColorReset, termplt.ColorBlue, termplt.ColorReset)
 	chart := termplt.NewLineChart()
 	x := make([]float64, len(daily))
@@ -76,33 +127,51 @@ func renderForecastTempChart(daily []DailyAggregate) {
 	chart.AddLine(x, lo, termplt.ColorBlue)
 	chart.SetXLabelAsTime("", "Mon 2")
 	chart.SetYLabel("°C")
-	fmt.Print(chart.String())
+	fmt.Fprint(w, chart.String())
 }
 
-func renderForecastTable(daily []DailyAggregate) {
+// renderForecastTable prints the day-by-day table. spread holds the ensemble
+// summary per "2006-01-02" date; days missing from it (or a nil map) get a
+// plain bar and no wet-day chance. The ΔHi and Pctl columns only appear when
+// some day carries climate context.
+func renderForecastTable(w io.Writer, daily []DailyAggregate, spread map[string]EnsembleDay) {
 	b, rst := termplt.ColorBold, termplt.ColorReset
 
 	// Global temperature span drives the ASCII range bars (mirrors the web bars).
-	gMin, gMax := daily[0].TempMin, daily[0].TempMax
-	for _, d := range daily {
-		if d.TempMin < gMin {
-			gMin = d.TempMin
-		}
-		if d.TempMax > gMax {
-			gMax = d.TempMax
-		}
-	}
+	gMin, gMax := forecastTempSpan(daily, spread)
 	span := gMax - gMin
 	if span < 1 {
 		span = 1
 	}
 
-	fmt.Printf("%s  %-10s %5s %5s  %-12s %6s %6s  %-11s %5s %3s  %s%s\n",
-		b, "Day", "Hi", "Lo", "Temp range", "Rain", "Rain%", "Wind", "Gust", "UV", "Sky", rst)
+	climate := slices.ContainsFunc(daily, func(d DailyAggregate) bool { return d.Climate != nil })
+	normalCols := ""
+	if climate {
+		normalCols = fmt.Sprintf(" %5s %6s", "ΔHi", "Pctl")
+	}
+	fmt.Fprintf(w, "%s  %-10s %5s %5s%s  %-12s %6s %6s %4s  %-11s %5s %3s  %s%s\n",
+		b, "Day", "Hi", "Lo", normalCols, "Temp range", "Rain", "Rain%", "Wet", "Wind", "Gust", "UV", "Sky", rst)
 	for _, d := range daily {
 		hi := fmt.Sprintf("%d°", int(round(d.TempMax)))
 		lo := fmt.Sprintf("%d°", int(round(d.TempMin)))
-		bar := tempRangeBar(d.TempMin, d.TempMax, gMin, span, 12)
+		mark := math.NaN()
+		normal := ""
+		if climate {
+			anom := fmt.Sprintf("%5s", "—")
+			pctl := "—"
+			if c := d.Climate; c != nil {
+				mark = c.NormalMax
+				anom = wrap(fmt.Sprintf("%5s", anomalyLabel(c.MaxAnomaly)), anomalyColor(c.MaxAnomaly))
+				pctl = percentileLabel(c.MaxPercentile)
+			}
+			normal = fmt.Sprintf(" %s %6s", anom, pctl)
+		}
+		bar := tempRangeBar(d.TempMin, d.TempMax, gMin, span, 12, mark)
+		wet := "—"
+		if e, ok := spread[d.Date.Format("2006-01-02")]; ok {
+			bar = tempSpreadBar(d.TempMin, d.TempMax, e.TempMin.P10, e.TempMax.P90, gMin, span, 12, mark)
+			wet = fmt.Sprintf("%d%%", int(round(e.WetChance*100)))
+		}
 		rain := formatPrecip(d.PrecipSum)
 		if rain == "" {
 			rain = "·"
@@ -119,17 +188,79 @@ func renderForecastTable(daily []DailyAggregate) {
 
 		windCell := wrap(fmt.Sprintf("%-11s", windPlain), windColor(kmh))
 		uvCell := wrap(fmt.Sprintf("%3d", uvVal), uvColor(uvVal))
-		fmt.Printf("  %-10s %5s %5s  %s %6s %6s  %s %5s  %s  %s\n",
-			d.Date.Format("Mon 2 Jan"), hi, lo, bar, rain, pct, windCell, gust, uvCell, sky)
+		fmt.Fprintf(w, "  %-10s %5s %5s%s  %s %6s %6s %4s  %s %5s  %s  %s\n",
+			d.Date.Format("Mon 2 Jan"), hi, lo, normal, bar, rain, pct, wet, windCell, gust, uvCell, sky)
+	}
+}
+
+// forecastTempSpan returns the lowest and highest temperature the range bars
+// have to fit: the daily lows and highs, widened by any ensemble whiskers and
+// normal-high marks.
+func forecastTempSpan(daily []DailyAggregate, spread map[string]EnsembleDay) (float64, float64) {
+	gMin, gMax := daily[0].TempMin, daily[0].TempMax
+	for _, d := range daily {
+		gMin = math.Min(gMin, d.TempMin)
+		gMax = math.Max(gMax, d.TempMax)
+		if c := d.Climate; c != nil {
+			gMin = math.Min(gMin, c.NormalMax)
+			gMax = math.Max(gMax, c.NormalMax)
+		}
+		if e, ok := spread[d.Date.Format("2006-01-02")]; ok {
+			if !math.IsNaN(e.TempMin.P10) {
+				gMin = math.Min(gMin, e.TempMin.P10)
+			}
+			if !math.IsNaN(e.TempMax.P90) {
+				gMax = math.Max(gMax, e.TempMax.P90)
+			}
+		}
 	}
+	return gMin, gMax
 }
 
 // tempRangeBar renders a width-w ASCII bar with this day's min→max segment
 // positioned within the [gMin, gMin+span] range — the terminal equivalent of
-// the web table's coloured range bars.
-func tempRangeBar(minT, maxT, gMin, span float64, w int) string {
-	left := int((minT - gMin) / span * float64(w))
-	barLen := int((maxT-minT)/span*float64(w) + 0.5)
+// the web table's coloured range bars. mark, unless NaN, puts a "┃" at that
+// temperature: the normal high for the date.
+func tempRangeBar(minT, maxT, gMin, span float64, w int, mark float64) string {
+	return tempSpreadBar(minT, maxT, minT, maxT, gMin, span, w, mark)
+}
+
+// tempSpreadBar is tempRangeBar with whiskers: "─" runs from the ensemble's
+// cold end (loW) up to the bar, and from the bar out to its warm end (hiW).
+func tempSpreadBar(minT, maxT, loW, hiW, gMin, span float64, w int, mark float64) string {
+	left, barLen := tempBarBounds(minT, maxT, gMin, span, w)
+	lo := left
+	if loW < minT {
+		lo = max(0, min(left, int((loW-gMin)/span*float64(w))))
+	}
+	hi := left + barLen
+	if hiW > maxT {
+		hi = max(hi, min(w, int((hiW-gMin)/span*float64(w)+0.5)))
+	}
+	cells := make([]rune, w)
+	for i := range cells {
+		switch {
+		case i < lo || i >= hi:
+			cells[i] = '░'
+		case i < left || i >= left+barLen:
+			cells[i] = '─'
+		default:
+			cells[i] = '█'
+		}
+	}
+	if !math.IsNaN(mark) {
+		cells[max(0, min(w-1, int((mark-gMin)/span*float64(w))))] = '┃'
+	}
+	return string(cells[:left]) +
+		termplt.ColorYellow + string(cells[left:left+barLen]) + termplt.ColorReset +
+		string(cells[left+barLen:])
+}
+
+// tempBarBounds returns where a range bar starts and how many cells it
+// covers, clamped to the width.
+func tempBarBounds(minT, maxT, gMin, span float64, w int) (left, barLen int) {
+	left = int((minT - gMin) / span * float64(w))
+	barLen = int((maxT-minT)/span*float64(w) + 0.5)
 	if barLen < 1 {
 		barLen = 1
 	}
@@ -142,11 +273,5 @@ func tempRangeBar(minT, maxT, gMin, span float64, w int) string {
 	if left+barLen > w {
 		barLen = w - left
 	}
-	right := w - left - barLen
-	if right < 0 {
-		right = 0
-	}
-	return strings.Repeat("░", left) +
-		termplt.ColorYellow + strings.Repeat("█", barLen) + termplt.ColorReset +
-		strings.Repeat("░", right)
+	return left, barLen
 }
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jsnjack/termplt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	FlagHistoryDate string
+	FlagHistoryFrom string
+	FlagHistoryTo   string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Hour-by-hour weather that actually happened on a past date",
+	Long: `history looks backwards: the hourly temperature, rain and wind that
+occurred on --date, optionally narrowed to --from/--to (local clock, e.g. a
+ride from 14:00 to 18:00). Days older than about five days come from the
+ERA5 reanalysis in the Open-Meteo archive, which has no UV index or rain
+chance; more recent days from Open-Meteo's analysis. Same charts and table as
+weather hourly; mirrors the /history web page.`,
+	RunE: runHistory,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().StringVar(&FlagHistoryDate, "date", "", "day to look up, YYYY-MM-DD (required)")
+	historyCmd.Flags().StringVar(&FlagHistoryFrom, "from", "", "first hour to show, HH:MM (default 00:00)")
+	historyCmd.Flags().StringVar(&FlagHistoryTo, "to", "", "last hour to show, HH:MM (default 23:00)")
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	if FlagHistoryDate == "" {
+		return fmt.Errorf("--date is required")
+	}
+	cmd.SilenceUsage = true
+	day, err := time.ParseInLocation("2006-01-02", FlagHistoryDate, time.Local)
+	if err != nil {
+		return fmt.Errorf("--date: want YYYY-MM-DD, got %q", FlagHistoryDate)
+	}
+	from, to, err := parseHistoryWindow(FlagHistoryFrom, FlagHistoryTo)
+	if err != nil {
+		return err
+	}
+
+	loc, err := ResolveLocation()
+	if err != nil {
+		return err
+	}
+
+	prog := NewCLIProgress("past weather")
+	prog.AddTotal(1)
+	hours, source, err := GetOpenMeteoHistory(loc.Latitude, loc.Longitude, day, day)
+	prog.Inc(1)
+	prog.Finish()
+	if err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	rows := clipHours(hours, from, to)
+	if len(rows) == 0 {
+		return fmt.Errorf("history: no data between %s and %s", FlagHistoryFrom, FlagHistoryTo)
+	}
+
+	fmt.Printf(termplt.ColorBold+"Weather in %s"+termplt.ColorReset+
+		"  ·  %s %s → %s  ·  %s\n\n",
+		loc.Description, rows[0].Time.Format("Mon 2 Jan 2006"),
+		rows[0].Time.Format("15:04"), rows[len(rows)-1].Time.Format("15:04"), source)
+
+	renderHourlyTempChart(os.Stdout, rows)
+	fmt.Println()
+	renderHourlyPrecipChart(os.Stdout, rows)
+	fmt.Println()
+	renderHourlyTable(os.Stdout, rows)
+	return nil
+}
+
+// parseHistoryWindow turns the optional --from/--to (or ?from=&to=) clock
+// times into minutes after midnight, defaulting to the whole day.
+func parseHistoryWindow(fromS, toS string) (from, to int, err error) {
+	from, to = 0, 23*60+59
+	if fromS != "" {
+		if from, err = parseClock(fromS); err != nil {
+			return 0, 0, fmt.Errorf("from: %w", err)
+		}
+	}
+	if toS != "" {
+		if to, err = parseClock(toS); err != nil {
+			return 0, 0, fmt.Errorf("to: %w", err)
+		}
+	}
+	return from, to, nil
+}
@@ -2,13 +2,18 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"github.com/jsnjack/termplt"
 	"github.com/spf13/cobra"
 )
 
-var FlagHourlyHours int
+var (
+	FlagHourlyHours int
+	FlagHourlyPNG   string
+)
 
 var hourlyCmd = &cobra.Command{
 	Use:   "hourly",
@@ -22,6 +27,7 @@ browser stay in sync.`,
 func init() {
 	rootCmd.AddCommand(hourlyCmd)
 	hourlyCmd.Flags().IntVar(&FlagHourlyHours, "hours", 24, "forecast window in hours (6–48)")
+	hourlyCmd.Flags().StringVar(&FlagHourlyPNG, "png", "", "also write the temperature and rain charts to this PNG file")
 }
 
 func runHourly(cmd *cobra.Command, args []string) error {
@@ -35,19 +41,39 @@ func runHourly(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+
+	prog := NewCLIProgress("hourly forecast")
+	rows, err := hourlyRows(loc, hours, prog)
+	prog.Finish()
+	if err != nil {
+		return err
+	}
+	printHourly(os.Stdout, rows, loc)
+	if FlagHourlyPNG != "" {
+		charts := hourlyCharts(rows)
+		if charts == nil {
+			charts = []lineChart{{}}
+		}
+		img, err := RenderLineChartsPNG(charts, RasterOpts{DPI: cliPNGDPI})
+		return writePNGFile(FlagHourlyPNG, img, err)
+	}
+	return nil
+}
+
+// hourlyRows fetches the next hours of the hourly forecast, starting at the
+// top of the current hour.
+func hourlyRows(loc Location, hours int, prog Progress) ([]HourlyForecast, error) {
 	now := time.Now()
 	start := now.Truncate(time.Hour)
 	end := start.Add(time.Duration(hours) * time.Hour)
 
-	prog := NewCLIProgress("hourly forecast")
 	prog.AddTotal(1)
 	// Fetch a little past the window so the last hour is covered across the
 	// local-midnight boundary. Shares the cached Open-Meteo layer with /hourly.
 	data, err := GetOpenMeteoRange(loc.Latitude, loc.Longitude, now, end.Add(2*time.Hour))
 	prog.Inc(1)
-	prog.Finish()
 	if err != nil || data == nil || len(data.Hourly) == 0 {
-		return fmt.Errorf("hourly forecast: %w", err)
+		return nil, fmt.Errorf("hourly forecast: %w", err)
 	}
 
 	rows := make([]HourlyForecast, 0, hours)
@@ -58,23 +84,27 @@ func runHourly(cmd *cobra.Command, args []string) error {
 		rows = append(rows, h)
 	}
 	if len(rows) == 0 {
-		return fmt.Errorf("hourly forecast: no data in the requested window")
+		return nil, fmt.Errorf("hourly forecast: no data in the requested window")
 	}
+	return rows, nil
+}
 
-	fmt.Printf(termplt.ColorBold+"Hourly forecast for %s"+termplt.ColorReset+
+// printHourly prints the charts and table for rows from hourlyRows.
+func printHourly(w io.Writer, rows []HourlyForecast, loc Location) {
+	start, end := rows[0].Time, rows[len(rows)-1].Time
+	fmt.Fprintf(w, termplt.ColorBold+"Hourly forecast for %s"+termplt.ColorReset+
 		"  ·  %s → %s\n\n",
 		loc.Description, start.Format("Mon 15:04"), end.Format("Mon 15:04"))
 
-	renderHourlyTempChart(rows)
-	fmt.Println()
-	renderHourlyPrecipChart(rows)
-	fmt.Println()
-	renderHourlyTable(rows)
-	return nil
+	renderHourlyTempChart(w, rows)
+	fmt.Fprintln(w)
+	renderHourlyPrecipChart(w, rows)
+	fmt.Fprintln(w)
+	renderHourlyTable(w, rows)
 }
 
-func renderHourlyTempChart(rows []HourlyForecast) {
-	fmt.Printf("%sTemp%s · %sFeels like%s (°C)\n",
+func renderHourlyTempChart(w io.Writer, rows []HourlyForecast) {
+	fmt.Fprintf(w, "%sTemp%s · %sFeels like%s (°C)\n",
 		termplt.ColorYellow, termplt.ColorReset, termplt.ColorCyan, termplt.ColorReset)
 	chart := termplt.NewLineChart()
 	x := make([]float64, len(rows))
@@ -89,10 +119,10 @@ func renderHourlyTempChart(rows []HourlyForecast) {
 	chart.AddLine(x, feels, termplt.ColorCyan)
 	chart.SetXLabelAsTime("", "Mon 15h")
 	chart.SetYLabel("°C")
-	fmt.Print(chart.String())
+	fmt.Fprint(w, chart.String())
 }
 
-func renderHourlyPrecipChart(rows []HourlyForecast) {
+func renderHourlyPrecipChart(w io.Writer, rows []HourlyForecast) {
 	maxP := 0.0
 	for _, h := range rows {
 		if h.Precipitation > maxP {
@@ -100,11 +130,11 @@ func renderHourlyPrecipChart(rows []HourlyForecast) {
 		}
 	}
 	if maxP < DryThresholdMmH {
-		fmt.Printf("%sPrecipitation%s — none expected in the window.\n",
+		fmt.Fprintf(w, "%sPrecipitation%s — none expected in the window.\n",
 			termplt.ColorCyan, termplt.ColorReset)
 		return
 	}
-	fmt.Printf("%sPrecipitation%s (mm/h)\n", termplt.ColorCyan, termplt.ColorReset)
+	fmt.Fprintf(w, "%sPrecipitation%s (mm/h)\n", termplt.ColorCyan, termplt.ColorReset)
 	chart := termplt.NewLineChart()
 	x := make([]float64, len(rows))
 	precip := make([]float64, len(rows))
@@ -115,12 +145,12 @@ func renderHourlyPrecipChart(rows []HourlyForecast) {
 	chart.AddLine(x, precip, termplt.ColorCyan)
 	chart.SetXLabelAsTime("", "Mon 15h")
 	chart.SetYLabel("mm")
-	fmt.Print(chart.String())
+	fmt.Fprint(w, chart.String())
 }
 
-func renderHourlyTable(rows []HourlyForecast) {
+func renderHourlyTable(w io.Writer, rows []HourlyForecast) {
 	b, rst := termplt.ColorBold, termplt.ColorReset
-	fmt.Printf("%s  %-10s %5s %6s %6s %6s  %-11s %3s  %s%s\n",
+	fmt.Fprintf(w, "%s  %-10s %5s %6s %6s %6s  %-11s %3s  %s%s\n",
 		b, "Time", "Temp", "Feels", "Rain", "Rain%", "Wind", "UV", "Sky", rst)
 
 	lastDay := -1
@@ -130,7 +160,7 @@ func renderHourlyTable(rows []HourlyForecast) {
 		if day != lastDay {
 			label = h.Time.Format("Mon 15:04")
 			if lastDay != -1 {
-				fmt.Println() // blank line between calendar days
+				fmt.Fprintln(w) // blank line between calendar days
 			}
 		}
 		lastDay = day
@@ -154,7 +184,7 @@ func renderHourlyTable(rows []HourlyForecast) {
 		// colour, so ANSI escapes don't throw off column alignment.
 		windCell := wrap(fmt.Sprintf("%-11s", windPlain), windColor(kmh))
 		uvCell := wrap(fmt.Sprintf("%3d", uvVal), uvColor(uvVal))
-		fmt.Printf("  %-10s %5s %6s %6s %6s  %s %s  %s\n",
+		fmt.Fprintf(w, "  %-10s %5s %6s %6s %6s  %s %s  %s\n",
 			label, temp, feels, rain, pct, windCell, uvCell, sky)
 	}
 }
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	FlagLineFormat string
+	FlagLineOutput string
+)
+
+const lineDefaultFormat = `{{.Emoji}} {{.Temp}}°{{if .Rain}} {{.Rain}}{{end}}`
+
+var lineCmd = &cobra.Command{
+	Use:   "line",
+	Short: "One compact line for status bars (i3blocks, waybar, tmux, starship)",
+	Long: `line prints the 2-hour outlook as a single line for a status bar, through a
+Go template (--format). The fields are:
+
+  .Place .Condition      place name and the sky, e.g. "Partly cloudy"
+  .Icon .Emoji           Nerd Font and emoji condition icons (night-aware)
+  .Temp .TempEnd         °C now and at +2h (.Feels .FeelsEnd: feels-like)
+  .Wind .WindArrow       km/h and the arrow it blows towards (.WindEnd ...)
+  .UV                    UV index now
+  .Rain                  "rain in 12m", "rain, stops in 25m", "rain"; "" if dry
+  .RainIn .RainStops     "12m"/"now" and "25m"; "" when they don't apply
+  .RainStartMin          minutes until it rains, 0 raining now, -1 dry
+  .RainStopMin           minutes until it stops, -1 if it doesn't in 2h
+  .Peak                  the highest rate in the window, mm/h
+  .Class                 "dry", "rain-soon" or "rain"
+
+--output waybar prints waybar's custom-module JSON (text, tooltip, class,
+alt) and --output i3bar an i3bar block (full_text, short_text, color) for
+i3blocks' format=json. The forecast is kept on disk for as long as the
+nowcast cache holds it, so a bar polling every few seconds costs a file
+read, not a fetch.`,
+	Example: `  weather line -n Utrecht --format '{{.Temp}}° {{.RainIn}}'
+  weather line --output waybar`,
+	RunE: runLine,
+}
+
+func init() {
+	rootCmd.AddCommand(lineCmd)
+	lineCmd.Flags().StringVar(&FlagLineFormat, "format", lineDefaultFormat, "Go template for the line")
+	lineCmd.Flags().StringVar(&FlagLineOutput, "output", "text", "text, waybar or i3bar")
+}
+
+func runLine(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	switch FlagLineOutput {
+	case "text", "waybar", "i3bar":
+	default:
+		return fmt.Errorf("--output must be text, waybar or i3bar")
+	}
+	tmpl, err := template.New("line").Parse(FlagLineFormat)
+	if err != nil {
+		return fmt.Errorf("--format: %w", err)
+	}
+
+	glance, err := lineGlance(cmd.Context())
+	if err != nil {
+		return err
+	}
+	d := newLineData(glance, time.Now())
+	var text strings.Builder
+	if err := tmpl.Execute(&text, d); err != nil {
+		return fmt.Errorf("--format: %w", err)
+	}
+	line := strings.TrimSpace(text.String())
+
+	var out any
+	switch FlagLineOutput {
+	case "text":
+		fmt.Println(line)
+		return nil
+	case "waybar":
+		out = struct {
+			Text    string `json:"text"`
+			Tooltip string `json:"tooltip"`
+			Class   string `json:"class"`
+			Alt     string `json:"alt"`
+		}{line, d.Tooltip(), d.Class, d.Class}
+	case "i3bar":
+		out = struct {
+			FullText  string `json:"full_text"`
+			ShortText string `json:"short_text"`
+			Color     string `json:"color,omitempty"`
+			Name      string `json:"name"`
+			Instance  string `json:"instance"`
+		}{line, fmt.Sprintf("%d°", d.Temp), lineClassColor[d.Class], "weather", d.Class}
+	}
+	raw, err := json.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", FlagLineOutput, err)
+	}
+	fmt.Println(string(raw))
+	return nil
+}
+
+// lineClassColor is the i3bar text colour per class; dry keeps the bar's.
+var lineClassColor = map[string]string{
+	"rain-soon": "#f59e0b",
+	"rain":      buienalarmColor,
+}
+
+// lineGlance returns the glance for the --name/--lat/--lon query, from the
+// disk cache when it's younger than the nowcast TTL. The cache is keyed on
+// the query rather than the coordinates, so a hit skips geocoding and IP
+// lookup too.
+func lineGlance(ctx context.Context) (*glanceAPIResponse, error) {
+	path, err := lineCachePath(FlagLat, FlagLon, FlagStrLocation)
+	if err != nil {
+		slog.Debug("line: no cache dir", "err", err)
+	}
+	if path != "" {
+		if g, ok := readLineCache(path, buienalarmCache.ttl); ok {
+			return g, nil
+		}
+	}
+	loc, err := ResolveLocation()
+	if err != nil {
+		return nil, fmt.Errorf("resolve location: %w", err)
+	}
+	g, err := buildGlanceResponse(ctx, loc, false, NoProgress)
+	if g == nil {
+		return nil, fmt.Errorf("forecast: %w", err)
+	}
+	if path != "" && err == nil {
+		if err := writeJSONFile(path, g); err != nil {
+			slog.Debug("line: could not cache glance", "path", path, "err", err)
+		}
+	}
+	return g, nil
+}
+
+func lineCachePath(lat, lon float64, name string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("cache dir: %w", err)
+	}
+	sum := sha1.Sum([]byte(fmt.Sprintf("%.4f|%.4f|%s", lat, lon, strings.ToLower(name))))
+	return filepath.Join(dir, "weather", "line", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+func readLineCache(path string, ttl time.Duration) (*glanceAPIResponse, bool) {
+	st, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false
+	}
+	if err != nil || time.Since(st.ModTime()) > ttl {
+		return nil, false
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		slog.Debug("line: read cache", "path", path, "err", err)
+		return nil, false
+	}
+	var g glanceAPIResponse
+	if err := json.Unmarshal(raw, &g); err != nil {
+		slog.Debug("line: ignoring corrupt cache", "path", path, "err", err)
+		return nil, false
+	}
+	return &g, true
+}
+
+// lineData is the template data of `weather line`; see lineCmd's help.
+type lineData struct {
+	Place, Condition string
+	Icon, Emoji      string
+
+	Temp, TempEnd   int
+	Feels, FeelsEnd int
+	Wind, WindEnd   int
+	WindArrow       string
+	WindArrowEnd    string
+	UV              int
+
+	Rain, RainIn, RainStops   string
+	RainStartMin, RainStopMin int
+	Peak                      float64
+	Class                     string
+}
+
+func newLineData(g *glanceAPIResponse, now time.Time) lineData {
+	d := lineData{
+		Place:        g.Location.Description,
+		Condition:    conditionHumanLabel(g.Condition),
+		Icon:         conditionNerdIcon(g.Condition, g.Night),
+		Emoji:        conditionEmoji(g.Condition, g.Night),
+		Temp:         g.Temperature.Now,
+		TempEnd:      g.Temperature.End,
+		Feels:        g.FeelsLike.Now,
+		FeelsEnd:     g.FeelsLike.End,
+		Wind:         g.Wind.Now.SpeedKmh,
+		WindEnd:      g.Wind.End.SpeedKmh,
+		WindArrow:    windArrowFor(g.Wind.Now.DirectionDeg),
+		WindArrowEnd: windArrowFor(g.Wind.End.DirectionDeg),
+		UV:           g.UVIndex.Now,
+		Class:        "dry",
+	}
+	d.RainStartMin, d.RainStopMin = -1, -1
+	a := g.Analysis
+	if a == nil {
+		// Glances cached by an older version don't carry the analysis.
+		a = AnalyzeRain(g.Buienalarm, g.Buineradar, now)
+	}
+	if a != nil && !a.Dry {
+		d.RainStartMin, d.Peak = minutesFrom(now, *a.StartsAt), a.PeakMmH
+		if a.StopsAt != nil {
+			d.RainStopMin = minutesFrom(now, *a.StopsAt)
+		}
+	}
+	switch {
+	case d.RainStartMin < 0:
+	case d.RainStartMin == 0:
+		d.Class, d.RainIn = "rain", "now"
+		d.Rain = "rain"
+		if d.RainStopMin >= 0 {
+			d.RainStops = fmt.Sprintf("%dm", d.RainStopMin)
+			d.Rain = "rain, stops in " + d.RainStops
+		}
+	default:
+		d.Class, d.RainIn = "rain-soon", fmt.Sprintf("%dm", d.RainStartMin)
+		d.Rain = "rain in " + d.RainIn
+		if d.RainStopMin >= 0 {
+			d.RainStops = fmt.Sprintf("%dm", d.RainStopMin)
+		}
+	}
+	return d
+}
+
+// Tooltip is the multi-line hover text of the waybar module.
+func (d lineData) Tooltip() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s · %s\n", d.Place, d.Condition)
+	fmt.Fprintf(&b, "now  %d° (feels %d°)  %s %d km/h  UV %d\n", d.Temp, d.Feels, d.WindArrow, d.Wind, d.UV)
+	fmt.Fprintf(&b, "+2h  %d° (feels %d°)  %s %d km/h", d.TempEnd, d.FeelsEnd, d.WindArrowEnd, d.WindEnd)
+	if d.Rain != "" {
+		fmt.Fprintf(&b, "\n%s%s, peak %.1f mm/h", strings.ToUpper(d.Rain[:1]), d.Rain[1:], d.Peak)
+	} else {
+		b.WriteString("\nDry for the next two hours")
+	}
+	return b.String()
+}
+
+// conditionNerdIcon is the Nerd Font weather glyph for a wmoCondition token.
+func conditionNerdIcon(token string, night bool) string {
+	switch token {
+	case "partly_cloudy":
+		if night {
+			return "\ue37e" // nf-weather-night_alt_cloudy
+		}
+		return "\ue302" // nf-weather-day_cloudy
+	case "overcast":
+		return "\ue312" // nf-weather-cloudy
+	case "fog":
+		return "\ue313" // nf-weather-fog
+	case "drizzle":
+		return "\ue319" // nf-weather-sprinkle
+	case "rain":
+		return "\ue318" // nf-weather-rain
+	case "snow":
+		return "\ue31a" // nf-weather-snow
+	case "thunderstorm":
+		return "\ue31d" // nf-weather-thunderstorm
+	default:
+		if night {
+			return "\ue32b" // nf-weather-night_clear
+		}
+		return "\ue30d" // nf-weather-day_sunny
+	}
+}
+
+// conditionEmoji is conditionNerdIcon for fonts without Nerd Font glyphs.
+func conditionEmoji(token string, night bool) string {
+	switch token {
+	case "partly_cloudy":
+		if night {
+			return "☁️"
+		}
+		return "⛅"
+	case "overcast":
+		return "☁️"
+	case "fog":
+		return "🌫️"
+	case "drizzle":
+		return "🌦️"
+	case "rain":
+		return "🌧️"
+	case "snow":
+		return "🌨️"
+	case "thunderstorm":
+		return "⛈️"
+	default:
+		if night {
+			return "🌙"
+		}
+		return "☀️"
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestNewLineData(t *testing.T) {
+	now := time.Date(2026, 5, 1, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		alarm       *Forecast
+		class, rain string
+		start, stop int
+	}{
+		{name: "dry", alarm: testRainLine(now, 0, 0, 0), class: "dry", start: -1, stop: -1},
+		{name: "soon", alarm: testRainLine(now, 0, 0, 0.4, 1.2, 0), class: "rain-soon", rain: "rain in 10m", start: 10, stop: 20},
+		{name: "now, stopping", alarm: testRainLine(now, 0.5, 0.2, 0, 0), class: "rain", rain: "rain, stops in 10m", start: 0, stop: 10},
+		{name: "now, all window", alarm: testRainLine(now, 1, 1), class: "rain", rain: "rain", start: 0, stop: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &glanceAPIResponse{Buienalarm: tt.alarm, Condition: "rain", Night: true}
+			d := newLineData(g, now)
+			if d.Class != tt.class || d.Rain != tt.rain || d.RainStartMin != tt.start || d.RainStopMin != tt.stop {
+				t.Fatalf("class %q rain %q start %d stop %d, want %q %q %d %d",
+					d.Class, d.Rain, d.RainStartMin, d.RainStopMin, tt.class, tt.rain, tt.start, tt.stop)
+			}
+		})
+	}
+}
+
+func TestLineTemplate(t *testing.T) {
+	now := time.Date(2026, 5, 1, 14, 0, 0, 0, time.UTC)
+	g := &glanceAPIResponse{Buienalarm: testRainLine(now, 0, 0.3, 1.5), Temperature: glancePair{Now: 12}}
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{name: "temperature, rain and peak", tmpl: `{{.Temp}}° {{.RainIn}} {{printf "%.1f" .Peak}}`, want: "12° 5m 1.5"},
+		{name: "class", tmpl: `{{.Class}}`, want: "rain-soon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			tmpl := template.Must(template.New("line").Parse(tt.tmpl))
+			if err := tmpl.Execute(&out, newLineData(g, now)); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Fatalf("template = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionEmoji(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		night bool
+		want  string
+	}{
+		{name: "clear day", token: "clear", want: "☀️"},
+		{name: "clear night", token: "clear", night: true, want: "🌙"},
+		{name: "partly cloudy night", token: "partly_cloudy", night: true, want: "☁️"},
+		{name: "rain", token: "rain", want: "🌧️"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := conditionEmoji(tt.token, tt.night); got != tt.want {
+				t.Fatalf("conditionEmoji(%q, %v) = %q, want %q", tt.token, tt.night, got, tt.want)
+			}
+		})
+	}
+}
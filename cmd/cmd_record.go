@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jsnjack/termplt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	FlagRecordReport bool
+	FlagRecordPlace  string
+)
+
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Snapshot forecasts for saved places and score past ones",
+	Long: `record takes one verification pass over the saved places in the config
+file (or the --lat/--lon/--name location when none are saved): it stores the
+Buienalarm and Buienradar nowcasts and the Open-Meteo hourly forecast, and
+collects the observations they are scored against — Buienradar's measured
+radar values and the Open-Meteo reanalysis, which trails by a few days.
+Run it from cron, or use serve --record-every. The store keeps the last 90
+days. With --report it prints the error per provider and lead time instead.
+Mirrors the /verification page.`,
+	RunE: runRecord,
+}
+
+func init() {
+	rootCmd.AddCommand(recordCmd)
+	recordCmd.Flags().BoolVar(&FlagRecordReport, "report", false, "print the verification scores instead of recording")
+	recordCmd.Flags().StringVar(&FlagRecordPlace, "place", "", "with --report, score only this saved place")
+}
+
+func runRecord(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	store, err := openVerificationStore()
+	if err != nil {
+		return err
+	}
+	if FlagRecordReport {
+		return printVerification(store, FlagRecordPlace)
+	}
+
+	places, err := recordPlaces()
+	if err != nil {
+		return err
+	}
+	rec, err := newRecorder(store, places)
+	if err != nil {
+		return err
+	}
+	stats, err := rec.RecordOnce(time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recorded %d forecast snapshots and %d observations for %d places", stats.Snapshots, stats.Observations, len(places))
+	if stats.Errors > 0 {
+		fmt.Printf(" (%s%d fetches failed%s, see --debug)", termplt.ColorYellow, stats.Errors, termplt.ColorReset)
+	}
+	fmt.Println()
+	return nil
+}
+
+// printVerification prints the metrics table, one block per variable.
+func printVerification(store *verificationStore, place string) error {
+	snaps, err := store.Snapshots()
+	if err != nil {
+		return err
+	}
+	obs, err := store.Observations()
+	if err != nil {
+		return err
+	}
+	metrics := computeVerification(snaps, obs, place)
+	if len(metrics) == 0 {
+		fmt.Println("Nothing to score yet: record for a few hours (nowcasts) or days (Open-Meteo) first.")
+		return nil
+	}
+
+	b, rst := termplt.ColorBold, termplt.ColorReset
+	variable := ""
+	for _, m := range metrics {
+		if m.Variable != variable {
+			if variable != "" {
+				fmt.Println()
+			}
+			variable = m.Variable
+			unit := "mm/h"
+			if variable == varTemp {
+				unit = "°C"
+			}
+			fmt.Printf("%s%s (%s)%s\n", b, variable, unit, rst)
+			fmt.Printf("%s  %-11s %-10s %6s %6s %6s %6s %6s%s\n", b, "Provider", "Lead", "N", "MAE", "Bias", "RMSE", "Agree", rst)
+		}
+		agree := "—"
+		if m.Agree >= 0 {
+			agree = fmt.Sprintf("%.0f%%", m.Agree*100)
+		}
+		fmt.Printf("  %-11s %-10s %6d %6.2f %+6.2f %6.2f %6s\n", m.Provider, m.Lead, m.N, m.MAE, m.Bias, m.RMSE, agree)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/jsnjack/termplt"
+	"github.com/spf13/cobra"
+)
+
+var (
+	FlagRideSegmentKm float64
+	FlagRideSVG       string
+)
+
+var rideReportCmd = &cobra.Command{
+	Use:   "ride-report FILE.gpx|FILE.fit",
+	Short: "Annotate a recorded ride with the weather it was ridden in",
+	Long: `ride-report reads a recorded ride (GPX track or Garmin FIT activity),
+looks up the hourly weather that actually occurred along it, and reports the
+wind each stretch was ridden into — the same tail/headwind projection the
+multiday planner scores with — plus where it rained and how warm it got.
+Prints a summary, a per-kilometre table and a chart; --svg also writes the
+chart as an SVG file. Mirrors the /ride upload page.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRideReport,
+}
+
+func init() {
+	rootCmd.AddCommand(rideReportCmd)
+	rideReportCmd.Flags().Float64Var(&FlagRideSegmentKm, "segment-km", 1, "length of each table row, km (0.2–50)")
+	rideReportCmd.Flags().StringVar(&FlagRideSVG, "svg", "", "also write the chart to this SVG file")
+}
+
+func runRideReport(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	if FlagRideSegmentKm < 0.2 || FlagRideSegmentKm > 50 {
+		return fmt.Errorf("--segment-km must be between 0.2 and 50")
+	}
+	pts, err := readTrack(args[0])
+	if err != nil {
+		return err
+	}
+
+	prog := NewCLIProgress("past weather along the ride")
+	report, err := buildRideReport(pts, FlagRideSegmentKm, prog)
+	prog.Finish()
+	if err != nil {
+		return fmt.Errorf("ride report: %w", err)
+	}
+
+	fmt.Printf(termplt.ColorBold+"Ride of %s"+termplt.ColorReset+
+		"  ·  %s → %s  ·  %.1f km\n\n",
+		report.Start.Format("Mon 2 Jan 2006"), report.Start.Format("15:04"), report.End.Format("15:04"), report.DistanceKm)
+	for _, line := range rideSummaryLines(report) {
+		fmt.Println("  " + line)
+	}
+	fmt.Println()
+	renderRideChart(report)
+	fmt.Println()
+	renderRideTable(report)
+
+	if FlagRideSVG != "" {
+		if err := os.WriteFile(FlagRideSVG, []byte(rideChartSVG(report)), 0o644); err != nil {
+			return fmt.Errorf("write svg: %w", err)
+		}
+		fmt.Printf("\nChart written to %s\n", FlagRideSVG)
+	}
+	return nil
+}
+
+// rideSummaryLines is the plain-text summary shared by the CLI and /ride.
+func rideSummaryLines(r *rideReport) []string {
+	lines := []string{"Wind: on average a " + windLabel(r.AvgTailwind)}
+	if r.Headwind >= 0 {
+		s := r.Segments[r.Headwind]
+		lines = append(lines, fmt.Sprintf("Hardest stretch: km %.0f–%.0f heading %s, %s",
+			s.FromKm, s.ToKm, CompassName(s.Bearing), windLabel(s.Tailwind)))
+	}
+	if r.Wettest >= 0 {
+		s := r.Segments[r.Wettest]
+		lines = append(lines, fmt.Sprintf("Wettest: km %.0f–%.0f at %s, %s mm/h · about %.1f mm on you over the ride",
+			s.FromKm, s.ToKm, s.Start.Format("15:04"), formatPrecip(s.Precip), r.RainMm))
+	} else {
+		lines = append(lines, "Rain: dry throughout")
+	}
+	if !math.IsNaN(r.TempMin) {
+		lines = append(lines, fmt.Sprintf("Temperature: %.0f–%.0f °C", r.TempMin, r.TempMax))
+	}
+	if r.Covered < len(r.Segments) {
+		lines = append(lines, fmt.Sprintf("No weather for %d of %d stretches", len(r.Segments)-r.Covered, len(r.Segments)))
+	}
+	lines = append(lines, "Source: "+strings.Join(r.Sources, ", "))
+	return lines
+}
+
+func renderRideChart(r *rideReport) {
+	fmt.Printf("%sTailwind%s (km/h, negative is headwind) · %sTemp%s (°C)\n",
+		termplt.ColorCyan, termplt.ColorReset, termplt.ColorYellow, termplt.ColorReset)
+	var x, tail, temp []float64
+	for _, s := range r.Segments {
+		if !s.HasWeather {
+			continue
+		}
+		x = append(x, float64(s.Mid().Unix()))
+		tail = append(tail, s.Tailwind)
+		temp = append(temp, s.Temp)
+	}
+	chart := termplt.NewLineChart()
+	chart.AddLine(x, tail, termplt.ColorCyan)
+	chart.AddLine(x, temp, termplt.ColorYellow)
+	chart.SetXLabelAsTime("", "15:04")
+	fmt.Print(chart.String())
+}
+
+func renderRideTable(r *rideReport) {
+	b, rst := termplt.ColorBold, termplt.ColorReset
+	fmt.Printf("%s  %-11s %-6s %-8s %-11s %9s %5s %6s%s\n",
+		b, "Km", "Time", "Heading", "Wind", "Along", "Temp", "Rain", rst)
+	for _, s := range r.Segments {
+		km := fmt.Sprintf("%.1f–%.1f", s.FromKm, s.ToKm)
+		heading := CompassArrow(s.Bearing) + " " + CompassName(s.Bearing)
+		if !s.HasWeather {
+			fmt.Printf("  %-11s %-6s %-8s %s\n", km, s.Start.Format("15:04"), heading, "—")
+			continue
+		}
+		kmh := int(round(s.WindSpeed))
+		windCell := wrap(fmt.Sprintf("%-11s", fmt.Sprintf("%s %2d km/h", windArrowFor(int(round(s.WindDir))), kmh)), windColor(kmh))
+		along := fmt.Sprintf("%+9.0f", s.Tailwind)
+		if s.Tailwind <= -windCalmKmh {
+			along = wrap(along, termplt.ColorRed)
+		} else if s.Tailwind >= windCalmKmh {
+			along = wrap(along, termplt.ColorGreen)
+		}
+		rain := formatPrecip(s.Precip)
+		if rain == "" {
+			rain = "·"
+		}
+		fmt.Printf("  %-11s %-6s %-8s %s %s %4.0f° %6s\n",
+			km, s.Start.Format("15:04"), heading, windCell, along, s.Temp, rain)
+	}
+}
@@ -4,8 +4,11 @@ Copyright © 2025 YAUHEN SHULITSKI
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/jsnjack/termplt"
@@ -19,6 +22,8 @@ var (
 	FlagLon         float64
 	FlagDebug       bool
 	FlagTrace       bool
+	FlagRainPNG     string
+	FlagWatch       time.Duration
 )
 
 // Version is set at build time via ldflags; defaults to "dev".
@@ -45,6 +50,23 @@ User can also specify the location manually.`,
 		loggerCleanup = initLogger(tracePath, level)
 		return nil
 	},
+	// The only positional argument is the --watch interval, so that
+	// `weather --watch 5m` works as well as --watch=5m. Anything else is a
+	// mistyped command, reported the way cobra would.
+	SuggestionsMinimumDistance: 2,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return nil
+		}
+		if d, err := time.ParseDuration(args[0]); len(args) == 1 && cmd.Flags().Changed("watch") && err == nil && d > 0 {
+			return nil
+		}
+		msg := fmt.Sprintf("unknown command %q for %q", args[0], cmd.CommandPath())
+		if s := cmd.SuggestionsFor(args[0]); len(s) > 0 {
+			msg += "\n\nDid you mean this?\n\t" + strings.Join(s, "\n\t")
+		}
+		return errors.New(msg)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
 
@@ -52,49 +74,91 @@ User can also specify the location manually.`,
 			fmt.Println(Version)
 			return nil
 		}
+		if len(args) == 1 {
+			d, err := time.ParseDuration(args[0])
+			if err != nil {
+				return fmt.Errorf("--watch interval: %w", err)
+			}
+			FlagWatch = d
+		}
+		if FlagWatch > 0 && FlagRainPNG != "" {
+			return fmt.Errorf("--watch and --png are mutually exclusive")
+		}
 
 		loc, err := ResolveLocation()
 		if err != nil {
 			return fmt.Errorf("resolve location: %w", err)
 		}
+		if FlagWatch > 0 {
+			return watchRain(cmd.Context(), loc, FlagWatch)
+		}
 
 		prog := NewCLIProgress("rain forecast")
-		glance, glanceErr := buildGlanceResponse(cmd.Context(), loc, prog)
+		glance, glanceErr := buildGlanceResponse(cmd.Context(), loc, true, prog)
 		prog.Finish()
 		if glanceErr != nil && glance == nil {
 			return fmt.Errorf("forecast: %w", glanceErr)
 		}
 
-		fmt.Printf(termplt.ColorBold+"Weather in %s\n"+termplt.ColorReset, loc.Description)
-		buinealarmForecast := glance.Buienalarm
-		buineradarForecast := glance.Buineradar
-		var desc string
-		if buinealarmForecast != nil {
-			desc = buinealarmForecast.Desc
+		printGlance(os.Stdout, glance, loc, nil)
+		if FlagRainPNG != "" {
+			ch := rainChart(glance)
+			img, err := RenderLineChartPNG(ch.Series, ch.Opts, RasterOpts{DPI: cliPNGDPI})
+			return writePNGFile(FlagRainPNG, img, err)
 		}
-		printConditionLine(glance, desc)
-
-		// Chart first — mirrors the Android widget layout, where the chart
-		// fills the top and the per-corner stats sit beneath it.
-		// termplt's chart string already ends in a blank line, so we don't
-		// add one before the stats; in the dry branch we add one ourselves.
-		if !glance.IsDry() {
-			renderRainChart(buinealarmForecast, buineradarForecast)
-		} else {
-			fmt.Println()
-		}
-
-		printGlanceStats(glance)
 		return nil
 	},
 }
 
-func renderRainChart(alarm, radar *Forecast) {
-	fmt.Printf("%sBuienalarm%s · %sBuineradar%s\n",
+// printGlance prints the 2-hour rain outlook: the condition line, the rain
+// chart and the now/+2h stats. Shared by the root command, --watch and the
+// TUI; prev, when set, is the previous Buienalarm line to draw dimmed.
+func printGlance(w io.Writer, glance *glanceAPIResponse, loc Location, prev *Forecast) {
+	fmt.Fprintf(w, termplt.ColorBold+"Weather in %s\n"+termplt.ColorReset, loc.Description)
+	printConditionLine(w, glance)
+
+	// Chart first — mirrors the Android widget layout, where the chart
+	// fills the top and the per-corner stats sit beneath it.
+	// termplt's chart string already ends in a blank line, so we don't
+	// add one before the stats; in the dry branch we add one ourselves.
+	if !glance.IsDry() {
+		renderRainChart(w, glance.Buienalarm, glance.Buineradar, glance.Motion, prev)
+	} else {
+		fmt.Fprintln(w)
+	}
+
+	printGlanceStats(w, glance)
+}
+
+func renderRainChart(w io.Writer, alarm, radar, motion, prev *Forecast) {
+	fmt.Fprintf(w, "%sBuienalarm%s · %sBuineradar%s",
 		termplt.ColorCyan, termplt.ColorReset,
 		termplt.ColorPurple, termplt.ColorReset)
+	if motion != nil && len(motion.Data) > 0 {
+		fmt.Fprintf(w, " · %sRadar motion%s", termplt.ColorGreen, termplt.ColorReset)
+	}
+	if prev != nil && len(prev.Data) > 0 {
+		fmt.Fprintf(w, " · %sprevious Buienalarm%s", ansiGrey, termplt.ColorReset)
+	}
+	fmt.Fprintln(w)
 	chart := termplt.NewLineChart()
 	var unit string
+	// The previous line goes in first so the current ones draw over it, and
+	// starts where the current one does so the x range doesn't stretch back.
+	if prev != nil && alarm != nil && len(alarm.Data) > 0 {
+		first := alarm.Data[0].Time
+		var px, py []float64
+		for _, p := range prev.Data {
+			if p.Time.Before(first) {
+				continue
+			}
+			px = append(px, float64(p.Time.Unix()))
+			py = append(py, p.Value)
+		}
+		if len(px) > 0 {
+			chart.AddLine(px, py, ansiGrey)
+		}
+	}
 	if alarm != nil && len(alarm.Data) > 0 {
 		ax, ay := make([]float64, 0, len(alarm.Data)), make([]float64, 0, len(alarm.Data))
 		for _, p := range alarm.Data {
@@ -107,13 +171,6 @@ func renderRainChart(alarm, radar *Forecast) {
 	if radar != nil && len(radar.Data) > 0 {
 		rx, ry := make([]float64, 0, len(radar.Data)), make([]float64, 0, len(radar.Data))
 		for _, p := range radar.Data {
-			// Buineradar runs past Buienalarm's horizon — cap so both lines
-			// share the same x range.
-			if alarm != nil && len(alarm.Data) > 0 {
-				if p.Time.After(alarm.Data[len(alarm.Data)-1].Time) {
-					break
-				}
-			}
 			rx = append(rx, float64(p.Time.Unix()))
 			ry = append(ry, p.Value)
 		}
@@ -122,23 +179,36 @@ func renderRainChart(alarm, radar *Forecast) {
 			unit = radar.Type.Unit()
 		}
 	}
+	if motion != nil && len(motion.Data) > 0 {
+		mdata := capToHorizon(motion.Data, rainHorizon(alarm, radar))
+		mx, my := make([]float64, 0, len(mdata)), make([]float64, 0, len(mdata))
+		for _, p := range mdata {
+			mx = append(mx, float64(p.Time.Unix()))
+			my = append(my, p.Value)
+		}
+		if len(mx) > 0 {
+			chart.AddLine(mx, my, termplt.ColorGreen)
+		}
+	}
 	chart.SetXLabelAsTime("", "15:04")
 	chart.SetYLabel(unit)
-	fmt.Print(chart.String())
+	fmt.Fprint(w, chart.String())
 }
 
-// printConditionLine prints the headline "Overcast — In 5 minutes, it will
-// dry up" line. Kept separate from printGlanceStats so the caller can slot
-// the chart between condition and stats.
-func printConditionLine(g *glanceAPIResponse, desc string) {
+// printConditionLine prints the headline "Overcast — Light rain from 14:10
+// (in 10 min) for 25 min, up to 2.1 mm/h" line, from the merged rain
+// analysis rather than either provider's own text. Kept separate from
+// printGlanceStats so the caller can slot the chart between condition and
+// stats.
+func printConditionLine(w io.Writer, g *glanceAPIResponse) {
 	if g == nil {
 		return
 	}
 	headline := conditionHumanLabel(g.Condition)
-	if desc != "" {
-		headline = headline + " — " + desc
+	if g.Analysis != nil {
+		headline = headline + " — " + g.Analysis.Summary
 	}
-	fmt.Println(headline)
+	fmt.Fprintln(w, headline)
 }
 
 // printGlanceStats prints two compact lines mirroring the Android widget's
@@ -154,13 +224,13 @@ func printConditionLine(g *glanceAPIResponse, desc string) {
 //
 // No rain probability — Buinealarm's nowcast already gives an exact
 // minute-by-minute picture across the 2h window.
-func printGlanceStats(g *glanceAPIResponse) {
+func printGlanceStats(w io.Writer, g *glanceAPIResponse) {
 	if g == nil {
 		return
 	}
-	fmt.Println(formatGlanceLine("now", g.Temperature.Now, g.FeelsLike.Now,
+	fmt.Fprintln(w, formatGlanceLine("now", g.Temperature.Now, g.FeelsLike.Now,
 		g.Wind.Now.DirectionDeg, g.Wind.Now.SpeedKmh, g.UVIndex.Now))
-	fmt.Println(formatGlanceLine("+2h", g.Temperature.End, g.FeelsLike.End,
+	fmt.Fprintln(w, formatGlanceLine("+2h", g.Temperature.End, g.FeelsLike.End,
 		g.Wind.End.DirectionDeg, g.Wind.End.SpeedKmh, g.UVIndex.End))
 
 	for _, ev := range g.Sun {
@@ -174,7 +244,7 @@ func printGlanceStats(g *glanceAPIResponse) {
 			glyph = "↓"
 			kind = "sunset"
 		}
-		fmt.Printf("  %s%s %s %s%s\n",
+		fmt.Fprintf(w, "  %s%s %s %s%s\n",
 			termplt.ColorYellow, glyph, kind, t.In(time.Local).Format("15:04"),
 			termplt.ColorReset)
 	}
@@ -238,7 +308,11 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&FlagVersion, "version", false, "print version and exit")
 	rootCmd.PersistentFlags().BoolVarP(&FlagDebug, "debug", "d", false, "Debug-level logging on stderr.")
 	rootCmd.PersistentFlags().BoolVar(&FlagTrace, "trace", false, "Trace-level logs to /tmp/weather.log (truncated each run).")
+	rootCmd.PersistentFlags().StringVarP(&FlagConfig, "config", "c", "", "config file (default ~/.config/weather/config.json)")
 	rootCmd.PersistentFlags().Float64VarP(&FlagLat, "lat", "a", 0, "latitude")
 	rootCmd.PersistentFlags().Float64VarP(&FlagLon, "lon", "o", 0, "longitude")
 	rootCmd.PersistentFlags().StringVarP(&FlagStrLocation, "name", "n", "", "location name, e.g. 'Amsterdam'")
+	rootCmd.Flags().StringVar(&FlagRainPNG, "png", "", "also write the rain chart to this PNG file")
+	rootCmd.Flags().DurationVar(&FlagWatch, "watch", 0, "redraw every interval until Ctrl-C (bare --watch: as often as the nowcast changes)")
+	rootCmd.Flags().Lookup("watch").NoOptDefVal = buienalarmCache.ttl.String()
 }
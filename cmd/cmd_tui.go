@@ -0,0 +1,510 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jsnjack/termplt"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Full-screen interactive dashboard over rain, hourly, forecast, today and multiday",
+	Long: `tui is a full-screen dashboard with one tab per view of the other commands:
+rain (the 2-hour nowcast), hourly, forecast, the today heatmap and the
+multiday heatmap. Tabs are fetched the first time they're shown; the rain tab
+refetches itself whenever its forecast ages out of the cache.
+
+Keys:
+  tab, 1–5     switch tabs (shift-tab goes back)
+  ← → (h l)    scrub through the hours of the today heatmap (the first stop
+               is the whole window) or the days of the multiday one
+  ↑ ↓ (k j)    scroll, PgUp/PgDn (space) by the page
+  p / P        next / previous place: the --name/--lat/--lon location, then
+               the saved places in the config file
+  r            refetch the tab
+  q            quit
+
+Logging would draw over the screen, so it's off while the TUI runs; use
+--trace to log to its file instead.`,
+	RunE: runTUI,
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
+
+type tuiTab int
+
+const (
+	tuiRain tuiTab = iota
+	tuiHourly
+	tuiForecast
+	tuiToday
+	tuiMultiday
+	tuiTabCount
+)
+
+var tuiTabNames = [tuiTabCount]string{"Rain", "Hourly", "Forecast", "Today", "Multiday"}
+
+// The tabs' windows; the TUI parses none of the commands' flags, so these
+// stand in for their defaults.
+const (
+	tuiHourlyHours      = 24
+	tuiForecastDays     = 14
+	tuiMultidayDays     = 5
+	tuiMultidayKmPerDay = 100
+	tuiMultidayMinTemp  = 15
+	tuiHeatmapGrid      = 21
+	tuiPollEvery        = 250 * time.Millisecond // resize and loading-count redraws
+)
+
+// tuiKey addresses one tab's data for one place.
+type tuiKey struct {
+	place int
+	tab   tuiTab
+}
+
+// tuiView is a tab's fetched data. Only the fields for its tab are set.
+type tuiView struct {
+	loc     Location
+	at      time.Time
+	err     error
+	glance  *glanceAPIResponse
+	hourly  []HourlyForecast
+	daily   []DailyAggregate
+	spread  map[string]EnsembleDay
+	today   *todayResult
+	heatmap *heatmapResult
+}
+
+type tuiFetched struct {
+	key  tuiKey
+	view *tuiView
+}
+
+// tuiProgress counts a background fetch for the footer.
+type tuiProgress struct{ total, n atomic.Int64 }
+
+func (p *tuiProgress) AddTotal(n int) { p.total.Add(int64(n)) }
+func (p *tuiProgress) Inc(n int)      { p.n.Add(int64(n)) }
+func (p *tuiProgress) Finish()        {}
+
+type tui struct {
+	ctx     context.Context
+	out     *os.File
+	places  []Place
+	place   int
+	tab     tuiTab
+	views   map[tuiKey]*tuiView
+	loading map[tuiKey]*tuiProgress
+	fetched chan tuiFetched
+	scrub   [tuiTabCount]int
+	scroll  int
+	lines   []string // the current tab's body, rendered
+}
+
+func runTUI(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	in := int(os.Stdin.Fd())
+	if !term.IsTerminal(in) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("tui needs a terminal")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	loc, err := ResolveLocation()
+	if err != nil {
+		return fmt.Errorf("resolve location: %w", err)
+	}
+	// Cancelled on quit, so fetches still running stop waiting to deliver.
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+	t := &tui{
+		ctx:     ctx,
+		out:     os.Stdout,
+		places:  append([]Place{{Name: loc.Description, Lat: loc.Latitude, Lon: loc.Longitude}}, cfg.Places...),
+		views:   map[tuiKey]*tuiView{},
+		loading: map[tuiKey]*tuiProgress{},
+		fetched: make(chan tuiFetched),
+	}
+
+	// Log lines on stderr would scribble over the screen. --trace already
+	// sends them to its file; otherwise they're dropped while the TUI runs.
+	if !FlagTrace {
+		prev := slog.Default()
+		slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+		defer slog.SetDefault(prev)
+	}
+
+	state, err := term.MakeRaw(in)
+	if err != nil {
+		return fmt.Errorf("raw terminal: %w", err)
+	}
+	// Alternate screen, hidden cursor, no line wrap: long chart lines are
+	// clipped at the edge instead of breaking the layout.
+	t.write("\x1b[?1049h\x1b[?25l\x1b[?7l")
+	defer func() {
+		t.write("\x1b[?7h\x1b[?25h\x1b[?1049l")
+		if err := term.Restore(in, state); err != nil {
+			slog.Log(context.Background(), LevelTrace, "tui: restore terminal", "err", err)
+		}
+	}()
+	return t.run()
+}
+
+func (t *tui) run() error {
+	keys := make(chan []string)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			select {
+			case keys <- parseTUIKeys(buf[:n]):
+			case <-t.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// The rain tab follows the nowcast cache: once Buienalarm's entry has
+	// expired a refetch gets new data, so that's when to ask.
+	refresh := time.NewTicker(buienalarmCache.ttl)
+	defer refresh.Stop()
+	poll := time.NewTicker(tuiPollEvery)
+	defer poll.Stop()
+
+	t.show()
+	lastW, lastH := t.size()
+	for {
+		select {
+		case ks, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			for _, k := range ks {
+				if !t.handleKey(k) {
+					return nil
+				}
+			}
+			t.show()
+		case f := <-t.fetched:
+			delete(t.loading, f.key)
+			t.views[f.key] = f.view
+			if f.key == t.key() {
+				t.show()
+			}
+		case <-refresh.C:
+			for k := range t.views {
+				if k.tab == tuiRain {
+					delete(t.views, k)
+				}
+			}
+			if t.tab == tuiRain {
+				t.show()
+			}
+		case <-poll.C:
+			w, h := t.size()
+			if w != lastW || h != lastH || len(t.loading) > 0 {
+				lastW, lastH = w, h
+				t.draw()
+			}
+		}
+	}
+}
+
+// handleKey applies one key press; false means quit.
+func (t *tui) handleKey(k string) bool {
+	_, rows := t.size()
+	page := max(1, rows-3)
+	switch k {
+	case "q", "ctrl-c":
+		return false
+	case "tab", "backtab":
+		step := tuiTab(1)
+		if k == "backtab" {
+			step = tuiTabCount - 1
+		}
+		t.tab = (t.tab + step) % tuiTabCount
+		t.scroll = 0
+	case "1", "2", "3", "4", "5":
+		t.tab = tuiTab(k[0] - '1')
+		t.scroll = 0
+	case "p", "P":
+		step := 1
+		if k == "P" {
+			step = len(t.places) - 1
+		}
+		t.place = (t.place + step) % len(t.places)
+		t.scroll = 0
+	case "r":
+		if t.loading[t.key()] == nil {
+			delete(t.views, t.key())
+		}
+	case "left", "h":
+		t.scrub[t.tab] = max(0, t.scrub[t.tab]-1)
+	case "right", "l":
+		t.scrub[t.tab] = min(t.scrubMax(), t.scrub[t.tab]+1)
+	case "up", "k":
+		t.scroll--
+	case "down", "j":
+		t.scroll++
+	case "pgup":
+		t.scroll -= page
+	case "pgdn", " ":
+		t.scroll += page
+	}
+	return true
+}
+
+// scrubMax is the last stop of ←/→ on the current tab.
+func (t *tui) scrubMax() int {
+	v := t.views[t.key()]
+	switch {
+	case v == nil:
+		return 0
+	case t.tab == tuiToday && v.today != nil:
+		return v.today.WindowHours
+	case t.tab == tuiMultiday && v.heatmap != nil:
+		return max(0, len(v.heatmap.Days)-1)
+	}
+	return 0
+}
+
+func (t *tui) key() tuiKey { return tuiKey{t.place, t.tab} }
+
+// show renders the current tab, starting its fetch if there's nothing to
+// render yet, and draws the screen.
+func (t *tui) show() {
+	key := t.key()
+	v := t.views[key]
+	if v == nil && t.loading[key] == nil {
+		p := &tuiProgress{}
+		t.loading[key] = p
+		go func() {
+			t.deliver(tuiFetched{key, t.fetch(key, p)})
+		}()
+	}
+	t.lines = nil
+	if v != nil {
+		var buf bytes.Buffer
+		t.render(&buf, v)
+		t.lines = strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	}
+	t.draw()
+}
+
+// deliver hands a finished fetch to the run loop, or drops it once the TUI
+// has quit and nothing is receiving.
+func (t *tui) deliver(f tuiFetched) {
+	select {
+	case t.fetched <- f:
+	case <-t.ctx.Done():
+	}
+}
+
+// fetch gathers a tab's data, as the matching command does.
+func (t *tui) fetch(key tuiKey, prog Progress) *tuiView {
+	v := &tuiView{at: time.Now()}
+	v.loc, v.err = t.places[key.place].Location()
+	if v.err != nil {
+		return v
+	}
+	v.loc.Description = t.places[key.place].Name
+	lat, lon := v.loc.Latitude, v.loc.Longitude
+	switch key.tab {
+	case tuiRain:
+		v.glance, v.err = buildGlanceResponse(t.ctx, v.loc, true, prog)
+		if v.glance != nil {
+			v.err = nil // partial data still draws; the stats show what's missing
+		}
+	case tuiHourly:
+		v.hourly, v.err = hourlyRows(v.loc, tuiHourlyHours, prog)
+	case tuiForecast:
+		v.daily, v.spread, v.err = dailyOutlook(v.loc, tuiForecastDays, prog)
+	case tuiToday:
+		start, err := resolveTodayStart()
+		if err != nil {
+			v.err = err
+			break
+		}
+		r := runTodayGrid(lat, lon, start, todayDefaultHours, todayDefaultGrid, todayDefaultRadius, prog)
+		v.today = &r
+	case tuiMultiday:
+		cfg := beamConfig{KmPerDay: tuiMultidayKmPerDay, MinTemp: tuiMultidayMinTemp}
+		h := RunHeatmap(lat, lon, time.Now(), tuiMultidayDays, cfg, tuiHeatmapGrid, prog)
+		v.heatmap = &h
+	}
+	return v
+}
+
+// render writes a tab with the commands' own renderers.
+func (t *tui) render(w io.Writer, v *tuiView) {
+	if v.err != nil {
+		fmt.Fprintf(w, "%s%s%s\n", termplt.ColorRed, v.err, termplt.ColorReset)
+		return
+	}
+	b, rst := termplt.ColorBold, termplt.ColorReset
+	switch t.tab {
+	case tuiRain:
+		printGlance(w, v.glance, v.loc, nil)
+	case tuiHourly:
+		printHourly(w, v.hourly, v.loc)
+	case tuiForecast:
+		printForecast(w, v.daily, v.spread, v.loc)
+	case tuiToday:
+		r := *v.today
+		end := r.StartTime.Add(time.Duration(r.WindowHours) * time.Hour)
+		i := min(t.scrub[tuiToday], r.WindowHours)
+		if i == 0 {
+			fmt.Fprintf(w, b+"Today around %s"+rst+"  ·  %s–%s, whole window  ·  → hour by hour\n\n",
+				v.loc.Description, r.StartTime.Format("15:04"), end.Format("15:04"))
+			renderToday(w, r)
+			fmt.Fprintln(w)
+			renderWindEvolution(w, r)
+			fmt.Fprintln(w)
+			printTodayRecommendation(w, r)
+			fmt.Fprintln(w)
+			renderTodayLegend(w)
+			return
+		}
+		h := todayHourView(r, i-1)
+		fmt.Fprintf(w, b+"Today around %s"+rst+"  ·  hour %d of %d, %s–%s\n\n",
+			v.loc.Description, i, r.WindowHours,
+			h.StartTime.Format("15:04"), h.StartTime.Add(time.Hour).Format("15:04"))
+		renderToday(w, h)
+		fmt.Fprintln(w)
+		renderTodayLegend(w)
+	case tuiMultiday:
+		h := *v.heatmap
+		if len(h.Days) == 0 {
+			fmt.Fprintln(w, "No days in the heatmap.")
+			return
+		}
+		d := min(t.scrub[tuiMultiday], len(h.Days)-1)
+		fmt.Fprintf(w, b+"Multi-day from %s"+rst+"  ·  day %d of %d  ·  ←/→ for the others\n\n",
+			v.loc.Description, d+1, len(h.Days))
+		renderHeatmapDay(w, h, d)
+		fmt.Fprintln(w)
+		renderHeatmapLegend(w)
+	}
+}
+
+// draw paints the tab bar, the visible slice of the body and the footer.
+func (t *tui) draw() {
+	cols, rows := t.size()
+	rst := termplt.ColorReset
+	var b strings.Builder
+	// Home and overwrite, clearing each line's tail, rather than clearing
+	// the screen: the loading count redraws several times a second.
+	b.WriteString("\x1b[H")
+
+	for i, name := range tuiTabNames {
+		label := fmt.Sprintf(" %d %s ", i+1, name)
+		if tuiTab(i) == t.tab {
+			label = termplt.ColorBold + "\x1b[7m" + label + rst
+		}
+		b.WriteString(label)
+	}
+	fmt.Fprintf(&b, "  ·  %s%s%s", termplt.ColorBold, t.places[t.place].Name, rst)
+	if len(t.places) > 1 {
+		fmt.Fprintf(&b, " (%d/%d)", t.place+1, len(t.places))
+	}
+	b.WriteString("\x1b[K\r\n\x1b[K\r\n")
+
+	body := max(1, rows-3)
+	t.scroll = max(0, min(t.scroll, len(t.lines)-body))
+	end := min(len(t.lines), t.scroll+body)
+	for _, l := range t.lines[t.scroll:end] {
+		b.WriteString(l + rst + "\x1b[K\r\n")
+	}
+	b.WriteString("\x1b[J")
+
+	status := ""
+	if p := t.loading[t.key()]; p != nil {
+		status = "loading…"
+		if total := p.total.Load(); total > 1 {
+			status = fmt.Sprintf("loading %d/%d…", p.n.Load(), total)
+		}
+	} else if v := t.views[t.key()]; v != nil {
+		status = "updated " + v.at.Format("15:04:05")
+	}
+	help := "tab/1-5 tabs · ←/→ scrub · ↑/↓ scroll · p place · r refresh · q quit"
+	if pad := cols - utf8.RuneCountInString(help) - utf8.RuneCountInString(status) - 1; pad > 0 {
+		help += strings.Repeat(" ", pad) + status
+	}
+	fmt.Fprintf(&b, "\x1b[%d;1H%s%s%s\x1b[K", rows, termplt.ColorCyan, help, rst)
+	t.write(b.String())
+}
+
+func (t *tui) size() (cols, rows int) {
+	cols, rows, err := term.GetSize(int(t.out.Fd()))
+	if err != nil || cols <= 0 || rows <= 0 {
+		return 80, 24
+	}
+	return cols, rows
+}
+
+func (t *tui) write(s string) {
+	if _, err := io.WriteString(t.out, s); err != nil {
+		slog.Log(t.ctx, LevelTrace, "tui: write", "err", err)
+	}
+}
+
+// tuiEscKeys names the escape sequences the TUI handles, by what follows
+// "ESC [" (or "ESC O" for the arrows in application mode).
+var tuiEscKeys = map[string]string{
+	"A": "up", "B": "down", "C": "right", "D": "left",
+	"Z": "backtab", "5~": "pgup", "6~": "pgdn",
+}
+
+// parseTUIKeys splits one read from a raw terminal into key names: the
+// tuiEscKeys names, "tab", "ctrl-c", or the typed character itself.
+// Unknown escape sequences are dropped.
+func parseTUIKeys(b []byte) []string {
+	var keys []string
+	for len(b) > 0 {
+		if b[0] == 0x1b && len(b) >= 3 && (b[1] == '[' || b[1] == 'O') {
+			end := 2
+			for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+				end++
+			}
+			if end == len(b) {
+				break
+			}
+			if name, ok := tuiEscKeys[string(b[2:end+1])]; ok {
+				keys = append(keys, name)
+			}
+			b = b[end+1:]
+			continue
+		}
+		r, n := utf8.DecodeRune(b)
+		b = b[n:]
+		switch r {
+		case '\t':
+			keys = append(keys, "tab")
+		case 3:
+			keys = append(keys, "ctrl-c")
+		case 0x1b:
+			// A lone escape: nothing bound to it.
+		default:
+			keys = append(keys, string(r))
+		}
+	}
+	return keys
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTUIKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{name: "plain key", in: "q", want: []string{"q"}},
+		{name: "arrows", in: "\x1b[A\x1b[D", want: []string{"up", "left"}},
+		{name: "application-mode arrow", in: "\x1bOC", want: []string{"right"}},
+		{name: "page up, digit and tab", in: "\x1b[5~3\t", want: []string{"pgup", "3", "tab"}},
+		{name: "backtab and ctrl-c", in: "\x1b[Z\x03", want: []string{"backtab", "ctrl-c"}},
+		{name: "unknown F5 dropped", in: "\x1b[15~p", want: []string{"p"}},
+		{name: "lone escape", in: "\x1b", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseTUIKeys([]byte(tt.in)); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("parseTUIKeys(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTUIRender(t *testing.T) {
+	tests := []struct {
+		name string
+		tab  tuiTab
+		view *tuiView
+		want string
+	}{
+		{name: "fetch error", tab: tuiRain, view: &tuiView{err: errors.New("upstream down")}, want: "upstream down"},
+		{name: "empty multiday", tab: tuiMultiday, view: &tuiView{heatmap: &heatmapResult{}}, want: "No days in the heatmap."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			(&tui{tab: tt.tab}).render(&buf, tt.view)
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Fatalf("render wrote %q, want it to contain %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTodayHourView(t *testing.T) {
+	start := time.Date(2026, 5, 1, 14, 0, 0, 0, time.UTC)
+	cell := todayCell{MaxPrecip: 3, Hours: []todayHour{
+		{Precip: 0, OK: true, hourlyWind: hourlyWind{BlowsTo: 90, Speed: 12}},
+		{Precip: 3, OK: true},
+	}}
+	r := todayResult{Grid: 1, StartTime: start, WindowHours: 2, Cells: [][]todayCell{{cell, {NoData: true}}}}
+	tests := []struct {
+		name      string
+		hour      int
+		start     time.Time
+		maxPrecip float64
+		blowsTo   float64
+	}{
+		{name: "dry easterly first hour", hour: 0, start: start, maxPrecip: 0, blowsTo: 90},
+		{name: "wet second hour", hour: 1, start: start.Add(time.Hour), maxPrecip: 3, blowsTo: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := todayHourView(r, tt.hour)
+			if !h.StartTime.Equal(tt.start) || h.WindowHours != 1 {
+				t.Fatalf("hour view starts %v for %d hours, want %v for 1", h.StartTime, h.WindowHours, tt.start)
+			}
+			if c := h.Cells[0][0]; c.MaxPrecip != tt.maxPrecip || c.WindBlowsTo != tt.blowsTo || len(c.Hours) != 1 {
+				t.Fatalf("cell = %+v, want rain %v and push toward %v", c, tt.maxPrecip, tt.blowsTo)
+			}
+			if !h.Cells[0][1].NoData {
+				t.Fatal("hour view should keep no-data cells")
+			}
+			if r.Cells[0][0].MaxPrecip != 3 {
+				t.Fatal("todayHourView modified its input")
+			}
+		})
+	}
+}
+
+func TestTUIDeliver(t *testing.T) {
+	tests := []struct {
+		name      string
+		quit      bool
+		delivered bool
+	}{
+		{name: "running", delivered: true},
+		{name: "after quit", quit: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ui := &tui{ctx: ctx, fetched: make(chan tuiFetched)}
+			got := make(chan bool, 1)
+			if tt.quit {
+				cancel()
+			} else {
+				go func() { got <- (<-ui.fetched).view != nil }()
+			}
+			done := make(chan struct{})
+			go func() {
+				ui.deliver(tuiFetched{view: &tuiView{}})
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("deliver still blocked")
+			}
+			if tt.delivered && !<-got {
+				t.Fatal("run loop didn't get the view")
+			}
+		})
+	}
+}
@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	commuteDefaultSpeed = 18.0 // km/h, an unhurried ride in work clothes
+	commuteDefaultFlex  = 15   // minutes either side of the usual departure
+	commuteShiftStep    = 5 * time.Minute
+	commuteSampleKm     = 2.0  // the route is sampled about this often
+	commuteMaxSamples   = 12   // …but no more than this per leg
+	commuteGridDeg      = 0.02 // samples closer than ~2 km share a fetch
+	commuteNowcastAhead = 2 * time.Hour
+)
+
+// Sources a commute leg reports, for the views to label the data.
+const (
+	commuteSourceNowcast = "Buienalarm nowcast"
+	commuteSourceMixed   = "Buienalarm nowcast, then Open-Meteo"
+	commuteSourceHourly  = "Open-Meteo hourly forecast"
+)
+
+// Values of commuteLeg.Usual when the usual departure isn't among the
+// options.
+const (
+	commuteUsualPassed    = -1 // it is already in the past
+	commuteUsualUncovered = -2 // no forecast reaches it
+)
+
+// commuteSample is one point along a leg, with the weather fetched for it.
+type commuteSample struct {
+	Lat, Lon float64
+	Frac     float64 // 0 at the start of the leg, 1 at the end
+	nowcast  []ForecastDataPoint
+	hourly   []HourlyForecast
+}
+
+// precipAt is the rain rate at the sample at t: from the 5-minute nowcast
+// when it covers t, else from the hour t falls in. nowcast reports which.
+func (s commuteSample) precipAt(t time.Time) (mmh float64, nowcast, ok bool) {
+	if n := len(s.nowcast); n > 0 {
+		half := commuteShiftStep / 2
+		if !t.Before(s.nowcast[0].Time.Add(-half)) && !t.After(s.nowcast[n-1].Time.Add(half)) {
+			best := s.nowcast[0]
+			for _, p := range s.nowcast[1:] {
+				if p.Time.Sub(t).Abs() < best.Time.Sub(t).Abs() {
+					best = p
+				}
+			}
+			return best.Value, true, true
+		}
+	}
+	for _, h := range s.hourly {
+		if !t.Before(h.Time) && t.Before(h.Time.Add(time.Hour)) {
+			return h.Precipitation, false, true
+		}
+	}
+	return 0, false, false
+}
+
+// commuteOption is one possible departure time for a leg.
+type commuteOption struct {
+	Shift  time.Duration // from the usual departure
+	Depart time.Time
+	RainMm float64 // rain that falls on the rider on the way
+	WetMin int     // minutes ridden in rain
+}
+
+// commuteLeg is the briefing for one direction of the commute.
+type commuteLeg struct {
+	Name       string // "Morning" or "Evening"
+	From, To   Location
+	Depart     time.Time // the usual departure
+	Arrive     time.Time
+	DistanceKm float64
+	Bearing    float64
+	Source     string
+	Options    []commuteOption // departure shifts, earliest first
+	Usual      int             // index of the usual departure in Options, or commuteUsualPassed/Uncovered
+	Best       int             // index of the driest option
+	Tailwind   float64         // km/h at the usual time, negative is headwind
+	Temp       float64
+	HasWind    bool
+	Advice     string
+}
+
+// commuteReport is both legs of the next commute.
+type commuteReport struct {
+	From, To   Location
+	DistanceKm float64
+	SpeedKmh   float64
+	Legs       []commuteLeg // in departure order
+}
+
+// PlanCommute briefs the next morning and evening legs of cfg's commute.
+func PlanCommute(c Commute, cfg Config, now time.Time, prog Progress) (*commuteReport, error) {
+	from, err := cfg.place(c.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := cfg.place(c.To)
+	if err != nil {
+		return nil, err
+	}
+	speed := c.SpeedKmh
+	if speed == 0 {
+		speed = commuteDefaultSpeed
+	}
+	flex := time.Duration(c.FlexMin) * time.Minute
+	if c.FlexMin == 0 {
+		flex = commuteDefaultFlex * time.Minute
+	}
+
+	// The first fetch teaches locationZone the commute's timezone, which
+	// the "HH:MM" departures are read in.
+	prog.AddTotal(1)
+	_, err = GetOpenMeteoRange(from.Latitude, from.Longitude, now, now.AddDate(0, 0, 1))
+	prog.Inc(1)
+	if err != nil {
+		return nil, fmt.Errorf("commute forecast: %w", err)
+	}
+	zone := locationZone(from.Latitude, from.Longitude)
+	local := now.In(zone)
+
+	r := &commuteReport{
+		From: from, To: to, SpeedKmh: speed,
+		DistanceKm: HaversineKm(from.Latitude, from.Longitude, to.Latitude, to.Longitude),
+	}
+	for _, leg := range []struct {
+		name, clock string
+		a, b        Location
+	}{{"Morning", c.Morning, from, to}, {"Evening", c.Evening, to, from}} {
+		m, err := parseClock(leg.clock)
+		if err != nil {
+			return nil, fmt.Errorf("commute %s: %w", leg.name, err)
+		}
+		depart := time.Date(local.Year(), local.Month(), local.Day(), m/60, m%60, 0, 0, zone)
+		if depart.Before(local) {
+			depart = depart.AddDate(0, 0, 1)
+		}
+		l, err := planCommuteLeg(leg.name, leg.a, leg.b, depart, speed, flex, now, prog)
+		if err != nil {
+			return nil, err
+		}
+		r.Legs = append(r.Legs, l)
+	}
+	sort.SliceStable(r.Legs, func(i, j int) bool { return r.Legs[i].Depart.Before(r.Legs[j].Depart) })
+	return r, nil
+}
+
+// commuteSamples spaces points along the straight line from a to b, about
+// commuteSampleKm apart, always including both ends.
+func commuteSamples(a, b Location) []commuteSample {
+	km := HaversineKm(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+	bearing := InitialBearing(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+	n := int(math.Ceil(km/commuteSampleKm)) + 1
+	n = max(2, min(n, commuteMaxSamples))
+	out := make([]commuteSample, n)
+	for i := range out {
+		f := float64(i) / float64(n-1)
+		lat, lon := DestinationPoint(a.Latitude, a.Longitude, bearing, km*f)
+		out[i] = commuteSample{Lat: lat, Lon: lon, Frac: f}
+	}
+	return out
+}
+
+// planCommuteLeg fetches the weather along one leg and scores every
+// departure within flex of depart, in commuteShiftStep steps.
+func planCommuteLeg(name string, from, to Location, depart time.Time, speed float64, flex time.Duration, now time.Time, prog Progress) (commuteLeg, error) {
+	km := HaversineKm(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+	ride := time.Duration(km / speed * float64(time.Hour))
+	leg := commuteLeg{
+		Name: name, From: from, To: to,
+		Depart: depart, Arrive: depart.Add(ride),
+		DistanceKm: km,
+		Bearing:    InitialBearing(from.Latitude, from.Longitude, to.Latitude, to.Longitude),
+		Usual:      commuteUsualUncovered,
+	}
+	if depart.Before(now) {
+		leg.Usual = commuteUsualPassed
+	}
+	samples := commuteSamples(from, to)
+
+	// One fetch per grid cell the route passes through.
+	type cell struct{ lat, lon float64 }
+	cellOf := func(s commuteSample) cell {
+		return cell{snapToGrid(s.Lat, commuteGridDeg), snapToGrid(s.Lon, commuteGridDeg)}
+	}
+	type cellWeather struct {
+		nowcast []ForecastDataPoint
+		hourly  []HourlyForecast
+	}
+	weather := map[cell]*cellWeather{}
+	for _, s := range samples {
+		weather[cellOf(s)] = &cellWeather{}
+	}
+	useNowcast := depart.Add(-flex).Before(now.Add(commuteNowcastAhead))
+	from0, to0 := depart.Add(-flex), leg.Arrive.Add(flex)
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var hourlyErr error
+	for c, w := range weather {
+		prog.AddTotal(1)
+		wg.Add(1)
+		go func(c cell, w *cellWeather) {
+			defer wg.Done()
+			defer prog.Inc(1)
+			data, err := GetOpenMeteoRange(c.lat, c.lon, from0, to0)
+			if err != nil {
+				errMu.Lock()
+				hourlyErr = err
+				errMu.Unlock()
+			} else {
+				w.hourly = data.Hourly
+			}
+			if !useNowcast {
+				return
+			}
+			fc, err := GetBuinealarmForecast(c.lat, c.lon)
+			if err != nil {
+				slog.Debug("commute: nowcast failed, using the hourly forecast", "lat", c.lat, "lon", c.lon, "err", err)
+				return
+			}
+			w.nowcast = fc.Data
+		}(c, w)
+	}
+	wg.Wait()
+	for i := range samples {
+		w := weather[cellOf(samples[i])]
+		samples[i].nowcast, samples[i].hourly = w.nowcast, w.hourly
+	}
+
+	nowcastHits, hourlyHits := 0, 0
+	for shift := -flex; shift <= flex; shift += commuteShiftStep {
+		d := depart.Add(shift)
+		if d.Before(now) {
+			continue // can't leave in the past
+		}
+		opt := commuteOption{Shift: shift, Depart: d}
+		per := ride / time.Duration(len(samples))
+		covered := 0
+		wet := time.Duration(0)
+		for _, s := range samples {
+			t := d.Add(time.Duration(float64(ride) * s.Frac))
+			mmh, nc, ok := s.precipAt(t)
+			if !ok {
+				continue
+			}
+			covered++
+			if nc {
+				nowcastHits++
+			} else {
+				hourlyHits++
+			}
+			opt.RainMm += mmh * per.Hours()
+			if mmh >= DryThresholdMmH {
+				wet += per
+			}
+		}
+		if covered == 0 {
+			continue
+		}
+		opt.WetMin = int(math.Round(wet.Minutes()))
+		if shift == 0 {
+			leg.Usual = len(leg.Options)
+		}
+		leg.Options = append(leg.Options, opt)
+	}
+	if len(leg.Options) == 0 {
+		if hourlyErr != nil {
+			return leg, fmt.Errorf("commute %s: %w", name, hourlyErr)
+		}
+		return leg, fmt.Errorf("commute %s: no forecast covers %s", name, depart.Format("Mon 15:04"))
+	}
+	switch {
+	case hourlyHits == 0 && nowcastHits > 0:
+		leg.Source = commuteSourceNowcast
+	case nowcastHits > 0:
+		leg.Source = commuteSourceMixed
+	default:
+		leg.Source = commuteSourceHourly
+	}
+	leg.Best = bestCommuteOption(leg.Options)
+
+	// Wind and temperature at the usual time, averaged along the route.
+	var tail, temp float64
+	n := 0
+	for _, s := range samples {
+		h, ok := nearestHour(s.hourly, depart.Add(time.Duration(float64(ride)*s.Frac)))
+		if !ok {
+			continue
+		}
+		tail += tailwindComponent(h.WindSpeed, h.WindDirection, leg.Bearing)
+		temp += h.Temperature
+		n++
+	}
+	if n > 0 {
+		leg.HasWind = true
+		leg.Tailwind, leg.Temp = tail/float64(n), temp/float64(n)
+	}
+	leg.Advice = commuteAdvice(leg)
+	return leg, nil
+}
+
+// bestCommuteOption picks the driest departure, breaking ties by the
+// smallest shift so the advice never moves a departure for nothing.
+func bestCommuteOption(opts []commuteOption) int {
+	best := 0
+	for i, o := range opts {
+		b := opts[best]
+		switch {
+		case o.WetMin < b.WetMin,
+			o.WetMin == b.WetMin && o.RainMm < b.RainMm-0.05,
+			o.WetMin == b.WetMin && math.Abs(o.RainMm-b.RainMm) <= 0.05 && o.Shift.Abs() < b.Shift.Abs():
+			best = i
+		}
+	}
+	return best
+}
+
+// commuteAdvice is the one-line recommendation for a leg, e.g. "leave
+// 5 min earlier to stay dry".
+func commuteAdvice(l commuteLeg) string {
+	best := l.Options[l.Best]
+	shift := func() string {
+		m := int(best.Shift.Abs().Minutes())
+		if best.Shift < 0 {
+			return fmt.Sprintf("leave %d min earlier", m)
+		}
+		return fmt.Sprintf("leave %d min later", m)
+	}
+	switch l.Usual {
+	case commuteUsualPassed:
+		if best.WetMin == 0 {
+			return fmt.Sprintf("the usual time has passed; leaving at %s stays dry", best.Depart.Format("15:04"))
+		}
+		return "the usual time has passed; rain whenever you leave now"
+	case commuteUsualUncovered:
+		if best.WetMin == 0 {
+			return fmt.Sprintf("no forecast for the usual time yet; leaving at %s stays dry", best.Depart.Format("15:04"))
+		}
+		return "no forecast for the usual time yet; rain at the times it covers"
+	}
+	usual := l.Options[l.Usual]
+	switch {
+	case usual.WetMin == 0:
+		return "dry at the usual time"
+	case best.WetMin == 0:
+		return shift() + " to stay dry"
+	case l.Best != l.Usual && best.RainMm < usual.RainMm*0.7:
+		return fmt.Sprintf("%s for less rain (%.1f mm instead of %.1f)", shift(), best.RainMm, usual.RainMm)
+	}
+	return "rain whenever you leave — take a jacket"
+}
+
+// commuteGlance is the next commute leg, as the widget shows it.
+type commuteGlance struct {
+	Leg         string  `json:"leg"`    // "morning" | "evening"
+	Depart      string  `json:"depart"` // usual departure, RFC3339 local
+	ShiftMin    int     `json:"shift_min"`
+	RainMm      float64 `json:"rain_mm"` // at the suggested departure
+	Dry         bool    `json:"dry"`     // the suggested departure stays dry
+	TailwindKmh int     `json:"tailwind_kmh"`
+	Advice      string  `json:"advice"`
+}
+
+// glanceCommute condenses the first leg of r for /api/v1/glance.
+func glanceCommute(r *commuteReport) *commuteGlance {
+	if r == nil || len(r.Legs) == 0 {
+		return nil
+	}
+	l := r.Legs[0]
+	best := l.Options[l.Best]
+	return &commuteGlance{
+		Leg:         strings.ToLower(l.Name),
+		Depart:      l.Depart.Format(time.RFC3339),
+		ShiftMin:    int(best.Shift.Minutes()),
+		RainMm:      math.Round(best.RainMm*10) / 10,
+		Dry:         best.WetMin == 0,
+		TailwindKmh: int(round(l.Tailwind)),
+		Advice:      l.Advice,
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCommutePrecipAt(t *testing.T) {
+	at := func(m int) time.Time { return time.Date(2026, 6, 12, 8, m, 0, 0, time.UTC) }
+	s := commuteSample{
+		nowcast: []ForecastDataPoint{{Time: at(0), Value: 0}, {Time: at(5), Value: 0}, {Time: at(10), Value: 1.2}},
+		hourly:  []HourlyForecast{{Time: at(0), Precipitation: 0.4}},
+	}
+	tests := []struct {
+		name    string
+		t       time.Time
+		want    float64
+		nowcast bool
+	}{
+		{"nearest nowcast point", at(1), 0, true},
+		{"rounds onto the next point", at(9), 1.2, true},
+		{"past the nowcast", at(13), 0.4, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, nc, ok := s.precipAt(tc.t); !ok || got != tc.want || nc != tc.nowcast {
+				t.Fatalf("precipAt(%s) = %v (nowcast %v), want %v (nowcast %v)", tc.t.Format("15:04"), got, nc, tc.want, tc.nowcast)
+			}
+		})
+	}
+}
+
+func TestCommuteAdvice(t *testing.T) {
+	usual := time.Date(2026, 6, 12, 8, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		wet   []int // minutes in rain for shifts -10, -5, 0, +5, +10
+		usual int
+		want  string
+	}{
+		{"dry anyway", []int{0, 0, 0, 0, 0}, 2, "dry at the usual time"},
+		{"earlier is dry", []int{0, 0, 6, 9, 9}, 2, "leave 5 min earlier to stay dry"},
+		{"later is dry", []int{9, 9, 6, 3, 0}, 2, "leave 10 min later to stay dry"},
+		{"wet throughout", []int{6, 6, 6, 6, 6}, 2, "rain whenever you leave — take a jacket"},
+		{"usual time passed", []int{6, 6, 6, 6, 0}, commuteUsualPassed, "the usual time has passed; leaving at 08:40 stays dry"},
+		{"usual time not covered", []int{6, 6, 6, 6, 6}, commuteUsualUncovered, "no forecast for the usual time yet; rain at the times it covers"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l := commuteLeg{Usual: tc.usual}
+			for i, w := range tc.wet {
+				shift := time.Duration(i-2) * commuteShiftStep
+				l.Options = append(l.Options, commuteOption{
+					Shift:  shift,
+					Depart: usual.Add(shift),
+					WetMin: w,
+					RainMm: float64(w) / 10,
+				})
+			}
+			l.Best = bestCommuteOption(l.Options)
+			if got := commuteAdvice(l); got != tc.want {
+				t.Fatalf("commuteAdvice = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jsnjack/termplt"
+)
+
+const (
+	compareTempSpreadC = 3.0  // models at least this far apart on temperature disagree
+	compareWetMm       = 0.3  // an hour at or above this is wet for a model…
+	compareDryMm       = 0.05 // …and at or below this dry; wet against dry is a disagreement
+)
+
+// compareModel is one Open-Meteo model offered by the comparison views.
+type compareModel struct {
+	Key   string // short name used by --models and ?model=
+	ID    string // Open-Meteo models= value
+	Label string
+	Color string // web chart colour
+	Term  string // terminal colour
+}
+
+// compareModels are the models worth putting side by side for Dutch weather.
+// The seamless variants start from the high-resolution regional run
+// (HARMONIE-AROME, ICON-D2) and fall back to the coarser parent model past
+// its horizon, so a week-long comparison still has four lines.
+var compareModels = []compareModel{
+	{Key: "harmonie", ID: "knmi_seamless", Label: "HARMONIE (KNMI)", Color: "#f97316", Term: termplt.ColorRed},
+	{Key: "icon", ID: "icon_seamless", Label: "ICON (DWD)", Color: "#22c55e", Term: termplt.ColorGreen},
+	{Key: "ecmwf", ID: "ecmwf_ifs025", Label: "ECMWF IFS", Color: "#60a5fa", Term: termplt.ColorBlue},
+	{Key: "gfs", ID: "gfs_seamless", Label: "GFS (NOAA)", Color: "#a855f7", Term: termplt.ColorPurple},
+}
+
+// parseCompareModels resolves a list of model keys; an empty list means all.
+func parseCompareModels(keys []string) ([]compareModel, error) {
+	if len(keys) == 0 {
+		return compareModels, nil
+	}
+	var out []compareModel
+	seen := map[string]bool{}
+	for _, k := range keys {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k == "" || seen[k] {
+			continue
+		}
+		found := false
+		for _, m := range compareModels {
+			if m.Key == k {
+				out = append(out, m)
+				found = true
+				break
+			}
+		}
+		if !found {
+			valid := make([]string, len(compareModels))
+			for i, m := range compareModels {
+				valid[i] = m.Key
+			}
+			return nil, fmt.Errorf("unknown model %q (want %s)", k, strings.Join(valid, ", "))
+		}
+		seen[k] = true
+	}
+	if len(out) == 0 {
+		return compareModels, nil
+	}
+	return out, nil
+}
+
+// modelRun is one model's hourly forecast over the comparison window, or the
+// error that kept it out.
+type modelRun struct {
+	Model  compareModel
+	Hourly []HourlyForecast
+	Err    error
+}
+
+// fetchModelRuns fetches every model in parallel and trims each run to
+// [start, end]. A model that fails is returned with Err set so the views can
+// say so instead of silently dropping a line.
+func fetchModelRuns(lat, lon float64, start, end time.Time, models []compareModel, prog Progress) []modelRun {
+	runs := make([]modelRun, len(models))
+	prog.AddTotal(len(models))
+	var wg sync.WaitGroup
+	for i, m := range models {
+		wg.Add(1)
+		go func(i int, m compareModel) {
+			defer wg.Done()
+			defer prog.Inc(1)
+			runs[i].Model = m
+			// Fetch a little past the window so the last hour is covered
+			// across the local-midnight boundary.
+			data, err := GetOpenMeteoRangeModel(lat, lon, start, end.Add(2*time.Hour), m.ID)
+			if err != nil {
+				slog.Debug("compare: model fetch failed", "model", m.ID, "err", err)
+				runs[i].Err = err
+				return
+			}
+			for _, h := range data.Hourly {
+				if !h.Time.Before(start) && !h.Time.After(end) {
+					runs[i].Hourly = append(runs[i].Hourly, h)
+				}
+			}
+			if len(runs[i].Hourly) == 0 {
+				runs[i].Err = fmt.Errorf("no data in the window")
+			}
+		}(i, m)
+	}
+	wg.Wait()
+	return runs
+}
+
+// consensusHour is the models' mean for one hour plus how far apart they are.
+type consensusHour struct {
+	Time         time.Time
+	Models       int     // models with data for this hour
+	Temp         float64 // mean °C
+	TempSpread   float64 // warmest − coldest model
+	Precip       float64 // mean mm
+	PrecipSpread float64 // wettest − driest model
+	Disagree     bool
+	Reason       string // "temp", "rain" or "temp+rain"
+}
+
+// modelConsensus lines the runs up by hour and averages them. An hour counts
+// as a disagreement when the models are compareTempSpreadC apart on
+// temperature, or when one calls it wet and another dry. Hours with fewer
+// than two models can't disagree.
+func modelConsensus(runs []modelRun) []consensusHour {
+	byTime := map[int64][]HourlyForecast{}
+	for _, r := range runs {
+		for _, h := range r.Hourly {
+			byTime[h.Time.Unix()] = append(byTime[h.Time.Unix()], h)
+		}
+	}
+	out := make([]consensusHour, 0, len(byTime))
+	for _, hs := range byTime {
+		c := consensusHour{Time: hs[0].Time, Models: len(hs)}
+		tLo, tHi := hs[0].Temperature, hs[0].Temperature
+		pLo, pHi := hs[0].Precipitation, hs[0].Precipitation
+		for _, h := range hs {
+			c.Temp += h.Temperature
+			c.Precip += h.Precipitation
+			tLo, tHi = min(tLo, h.Temperature), max(tHi, h.Temperature)
+			pLo, pHi = min(pLo, h.Precipitation), max(pHi, h.Precipitation)
+		}
+		c.Temp /= float64(len(hs))
+		c.Precip /= float64(len(hs))
+		c.TempSpread, c.PrecipSpread = tHi-tLo, pHi-pLo
+		if c.Models >= 2 {
+			var reasons []string
+			if c.TempSpread >= compareTempSpreadC {
+				reasons = append(reasons, "temp")
+			}
+			if pHi >= compareWetMm && pLo <= compareDryMm {
+				reasons = append(reasons, "rain")
+			}
+			c.Disagree = len(reasons) > 0
+			c.Reason = strings.Join(reasons, "+")
+		}
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out
+}
+
+// disagreementSpans merges consecutive disagreeing hours into spans, each hour
+// covering half an hour either side of its timestamp.
+func disagreementSpans(hours []consensusHour) []SVGSpan {
+	var out []SVGSpan
+	for i, h := range hours {
+		if !h.Disagree {
+			continue
+		}
+		from, to := h.Time.Add(-30*time.Minute), h.Time.Add(30*time.Minute)
+		if len(out) > 0 && i > 0 && hours[i-1].Disagree && !from.After(out[len(out)-1].To) {
+			out[len(out)-1].To = to
+			continue
+		}
+		out = append(out, SVGSpan{From: from, To: to})
+	}
+	return out
+}
+
+// describeSpans renders disagreement spans as "Mon 14:00–18:00", one per span.
+func describeSpans(spans []SVGSpan) []string {
+	out := make([]string, len(spans))
+	for i, s := range spans {
+		from, to := s.From.Add(30*time.Minute), s.To.Add(-30*time.Minute)
+		if from.Equal(to) {
+			out[i] = from.Format("Mon 15:04")
+			continue
+		}
+		layout := "15:04"
+		if from.YearDay() != to.YearDay() {
+			layout = "Mon 15:04"
+		}
+		out[i] = from.Format("Mon 15:04") + "–" + to.Format(layout)
+	}
+	return out
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestModelConsensus(t *testing.T) {
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	hour := func(i int, temp, precip float64) HourlyForecast {
+		return HourlyForecast{Time: t0.Add(time.Duration(i) * time.Hour), Temperature: temp, Precipitation: precip}
+	}
+	runs := []modelRun{
+		{Hourly: []HourlyForecast{hour(0, 10, 0), hour(1, 10, 0), hour(2, 10, 0), hour(3, 10, 0)}},
+		{Hourly: []HourlyForecast{hour(0, 11, 0), hour(1, 14, 0), hour(2, 10, 0.5), hour(3, 11, 0)}},
+		{Hourly: []HourlyForecast{hour(3, 20, 0)}}, // lone extra model, only one hour
+	}
+	got := modelConsensus(runs)
+	if len(got) != 4 {
+		t.Fatalf("modelConsensus() returned %d hours, want 4", len(got))
+	}
+	tests := []struct {
+		name   string
+		hour   int
+		models int
+		temp   float64
+		reason string
+	}{
+		{"agree", 0, 2, 10.5, ""},
+		{"temperature apart", 1, 2, 12, "temp"},
+		{"one wet", 2, 2, 10, "rain"},
+		{"extra model joins", 3, 3, 41.0 / 3, "temp"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h := got[tc.hour]
+			if h.Models != tc.models || math.Abs(h.Temp-tc.temp) > 1e-9 || h.Reason != tc.reason {
+				t.Fatalf("hour %d = %+v, want models %d temp %v reason %q", tc.hour, h, tc.models, tc.temp, tc.reason)
+			}
+		})
+	}
+	t.Run("disagreement spans", func(t *testing.T) {
+		spans := disagreementSpans(got)
+		if len(spans) != 1 || !spans[0].From.Equal(t0.Add(30*time.Minute)) || !spans[0].To.Equal(t0.Add(3*time.Hour+30*time.Minute)) {
+			t.Fatalf("disagreementSpans() = %+v, want one span 12:30–15:30", spans)
+		}
+	})
+}
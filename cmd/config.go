@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FlagConfig is the --config path; empty means defaultConfigPath.
+var FlagConfig string
+
+// Config is the optional on-disk configuration. Every field has a working
+// default, so a missing file is the same as an empty one.
+type Config struct {
+	// Places are the saved places the verification recorder snapshots
+	// forecasts for. Each needs a name and either coordinates or a name
+	// that geocodes.
+	Places []Place `json:"places"`
+
+	// Commute, when set, is the daily ride `weather commute` and /commute
+	// brief on.
+	Commute *Commute `json:"commute,omitempty"`
+
+	// Upstreams overrides the URL of an upstream source by name, e.g. to
+	// point the radar at a mirror; see upstreamDefaults for the names.
+	Upstreams map[string]string `json:"upstreams,omitempty"`
+
+	// Trust weighs the rain nowcast providers ("buienalarm", "buienradar")
+	// in the blended nowcast; a provider left out weighs 1. Unset, the
+	// weights come from the verification history, or are equal without
+	// one. See fusion.go.
+	Trust map[string]float64 `json:"trust,omitempty"`
+}
+
+// Commute is a daily two-leg ride: From → To leaving at Morning, and back
+// leaving at Evening (both "HH:MM", local to From).
+type Commute struct {
+	From     string  `json:"from"` // a saved place's name, or any name that geocodes
+	To       string  `json:"to"`
+	Morning  string  `json:"morning"`
+	Evening  string  `json:"evening"`
+	SpeedKmh float64 `json:"speed_kmh,omitempty"` // default commuteDefaultSpeed
+	FlexMin  int     `json:"flex_min,omitempty"`  // how far the departure may move either way, default commuteDefaultFlex
+}
+
+// place resolves a commute end by saved-place name first, then the geocoder.
+func (c Config) place(name string) (Location, error) {
+	for _, p := range c.Places {
+		if strings.EqualFold(p.Name, name) {
+			loc, err := p.Location()
+			loc.Description = p.Name
+			return loc, err
+		}
+	}
+	return Place{Name: name}.Location()
+}
+
+// Place is a saved, named location.
+type Place struct {
+	Name string  `json:"name"`
+	Lat  float64 `json:"lat,omitempty"`
+	Lon  float64 `json:"lon,omitempty"`
+}
+
+// Location resolves the place: its coordinates when set, else its name via
+// the geocoder.
+func (p Place) Location() (Location, error) {
+	if p.Lat != 0 || p.Lon != 0 {
+		return Location{Description: p.Name, Latitude: p.Lat, Longitude: p.Lon}, nil
+	}
+	loc, err := GetLocationFromString(p.Name)
+	if err != nil {
+		return Location{}, fmt.Errorf("resolve place %q: %w", p.Name, err)
+	}
+	return loc, nil
+}
+
+// defaultConfigPath is ~/.config/weather/config.json (XDG_CONFIG_HOME aware).
+func defaultConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("config dir: %w", err)
+	}
+	return filepath.Join(dir, "weather", "config.json"), nil
+}
+
+// cachedConfig is loadConfig for per-request server paths, re-read at most
+// once per configCache TTL so an edit still shows up without a restart.
+func cachedConfig() (Config, error) {
+	return memo(configCache, "config", loadConfig)
+}
+
+// loadConfig reads the --config file, or the default one. A missing default
+// file yields an empty Config; a missing explicit --config is an error.
+func loadConfig() (Config, error) {
+	path := FlagConfig
+	if path == "" {
+		def, err := defaultConfigPath()
+		if err != nil {
+			return Config{}, err
+		}
+		path = def
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && FlagConfig == "" {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("read config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	for i, p := range cfg.Places {
+		if p.Name == "" {
+			return Config{}, fmt.Errorf("config %s: place %d has no name", path, i+1)
+		}
+	}
+	for name := range cfg.Upstreams {
+		if _, ok := upstreamDefaults[name]; !ok {
+			return Config{}, fmt.Errorf("config %s: unknown upstream %q", path, name)
+		}
+	}
+	var trust float64
+	for name, w := range cfg.Trust {
+		if name != providerBuienalarm && name != providerBuienradar {
+			return Config{}, fmt.Errorf("config %s: trust: unknown provider %q", path, name)
+		}
+		if w < 0 {
+			return Config{}, fmt.Errorf("config %s: trust: %s can't be negative", path, name)
+		}
+		trust += w
+	}
+	if len(cfg.Trust) == 2 && trust == 0 {
+		return Config{}, fmt.Errorf("config %s: trust: at least one provider needs a weight", path)
+	}
+	if c := cfg.Commute; c != nil {
+		if c.From == "" || c.To == "" {
+			return Config{}, fmt.Errorf("config %s: commute needs from and to", path)
+		}
+		for _, t := range []string{c.Morning, c.Evening} {
+			if _, err := parseClock(t); err != nil {
+				return Config{}, fmt.Errorf("config %s: commute times: %w", path, err)
+			}
+		}
+		if c.SpeedKmh < 0 || c.FlexMin < 0 {
+			return Config{}, fmt.Errorf("config %s: commute speed_kmh and flex_min can't be negative", path)
+		}
+	}
+	return cfg, nil
+}
+
+// dataDir is the persistent state directory, ~/.local/share/weather (or
+// $XDG_DATA_HOME/weather). Go has no os.UserDataDir, so the XDG variable is
+// read here directly.
+func dataDir() (string, error) {
+	if d := os.Getenv("XDG_DATA_HOME"); d != "" {
+		return filepath.Join(d, "weather"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("home dir: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "weather"), nil
+}
@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ---------- /dashboard.png (e-ink panels) ----------
+
+// The dashboard is one fixed, high-contrast layout for hallway e-ink panels
+// (Kindle, Inkplate, TRMNL): a header, the now/+2h glance cells with sun
+// times, the 2-hour rain chart and a strip of coming days. Everything is
+// drawn in the foreground colour (dashes tell series apart, not hues) and
+// then dithered down to the panel's grey levels.
+
+const (
+	dashboardDefaultW = 800
+	dashboardDefaultH = 480
+	dashboardDays     = 5 // fetched; the strip shows as many as fit
+
+	// Refresh hints, in seconds: the rain chart moves every few minutes
+	// while it's wet, but a dry dashboard barely changes in a quarter hour,
+	// and every refresh costs battery on a panel.
+	dashboardRefreshWet = 5 * 60
+	dashboardRefreshDry = 15 * 60
+)
+
+// dashboardData is what the dashboard shows, from the same builders as /
+// and /forecast.
+type dashboardData struct {
+	Glance *glanceAPIResponse
+	Daily  []DailyAggregate // may be empty; the strip is then left out
+	Now    time.Time        // local to the location
+}
+
+// dashboardRefresh is the refresh hint sent with the image.
+func dashboardRefresh(g *glanceAPIResponse) int {
+	if g.IsDry() {
+		return dashboardRefreshDry
+	}
+	return dashboardRefreshWet
+}
+
+// RenderDashboardPNG draws the dashboard at w×h viewBox units and reduces it
+// to bpp bits of grey (1, 2 or 4 dithered; 8 plain greyscale).
+func RenderDashboardPNG(d dashboardData, w, h int, ro RasterOpts, bpp int) ([]byte, error) {
+	c := newRasterCanvas(w, h, ro)
+	fw, fh := float64(w), float64(h)
+	const pad = 12.0
+
+	// Header: place and the time of the data, so a stale panel is obvious.
+	headH := 40.0
+	c.Text(pad, 28, d.Glance.Location.Description, 20, textStart, false, true, c.fg, 1)
+	c.Text(fw-pad, 28, "Updated "+d.Now.Format("Mon 15:04"), 12, textEnd, false, false, c.fg, 1)
+	c.Line(0, headH, fw, headH, 2, c.fg, 1)
+
+	stripH := 0.0
+	days := d.Daily
+	if n := max(1, w/130); len(days) > n {
+		days = days[:n]
+	}
+	if len(days) > 0 {
+		stripH = math.Round(fh * 0.28)
+	}
+	glanceH := math.Round(fh * 0.30)
+	drawDashboardGlance(c, d, headH, glanceH, fw)
+
+	chartTop := headH + glanceH
+	chartH := fh - stripH - chartTop
+	c.Line(0, chartTop, fw, chartTop, 1, c.fg, 1)
+	if d.Glance.IsDry() {
+		c.Text(fw/2, chartTop+chartH/2, "Dry for the next two hours", 20, textMiddle, true, true, c.fg, 1)
+	} else {
+		ch := rainChart(d.Glance)
+		for i := range ch.Series {
+			ch.Series[i].Color = "" // the foreground
+			ch.Series[i].Dashed = i > 0
+		}
+		ch.Opts.Width, ch.Opts.Height = w, int(chartH)
+		drawLineChart(c, chartTop, ch.Series, newLineChartLayout(ch.Series, ch.Opts))
+	}
+
+	if len(days) > 0 {
+		drawDashboardDays(c, days, fh-stripH, stripH, fw)
+	}
+	return encodePNG(ditherGray(c.img, bpp))
+}
+
+// drawDashboardGlance draws the now and +2h cells and, in a third column,
+// the sky and the next sunrise and sunset.
+func drawDashboardGlance(c *rasterCanvas, d dashboardData, top, h, w float64) {
+	g := d.Glance
+	colW := w / 3
+	cell := func(x float64, label string, temp, feels int, wind glanceWind, uv int) {
+		c.Text(x+12, top+22, label, 14, textStart, false, true, c.fg, 1)
+		c.Text(x+12, top+h*0.62, fmt.Sprintf("%d°", temp), 40, textStart, true, true, c.fg, 1)
+		c.Text(x+12, top+h-14, fmt.Sprintf("feels %d° · %s %d km/h · UV %d",
+			feels, windArrowFor(wind.DirectionDeg), wind.SpeedKmh, uv), 12, textStart, false, false, c.fg, 1)
+	}
+	cell(0, "Now", g.Temperature.Now, g.FeelsLike.Now, g.Wind.Now, g.UVIndex.Now)
+	cell(colW, "+2h", g.Temperature.End, g.FeelsLike.End, g.Wind.End, g.UVIndex.End)
+	c.Line(colW, top+8, colW, top+h-8, 1, c.fg, 1)
+	c.Line(2*colW, top+8, 2*colW, top+h-8, 1, c.fg, 1)
+
+	x := 2*colW + 12
+	c.Text(x, top+22, conditionHumanLabel(g.Condition), 14, textStart, false, true, c.fg, 1)
+	rise, set := nextSunTimes(d.Daily, d.Now)
+	y := top + h*0.55
+	if !rise.IsZero() {
+		c.Text(x, y, "↑ "+rise.Format("15:04"), 16, textStart, false, false, c.fg, 1)
+	}
+	if !set.IsZero() {
+		c.Text(x, y+26, "↓ "+set.Format("15:04"), 16, textStart, false, false, c.fg, 1)
+	}
+}
+
+// nextSunTimes is the first sunrise and sunset after now.
+func nextSunTimes(daily []DailyAggregate, now time.Time) (rise, set time.Time) {
+	for _, d := range daily {
+		if rise.IsZero() && d.Sunrise.After(now) {
+			rise = d.Sunrise
+		}
+		if set.IsZero() && d.Sunset.After(now) {
+			set = d.Sunset
+		}
+	}
+	return rise, set
+}
+
+// drawDashboardDays draws one column per day: name, sky, high/low, rain and
+// the strongest wind.
+func drawDashboardDays(c *rasterCanvas, days []DailyAggregate, top, h, w float64) {
+	c.Line(0, top, w, top, 2, c.fg, 1)
+	colW := w / float64(len(days))
+	for i, d := range days {
+		x := float64(i)*colW + colW/2
+		if i > 0 {
+			c.Line(float64(i)*colW, top+8, float64(i)*colW, top+h-8, 1, c.fg, 1)
+		}
+		rain := "dry"
+		if p := formatPrecip(d.PrecipSum); p != "" && d.PrecipSum >= DryThresholdMmH {
+			rain = p + " mm"
+		}
+		lines := []struct {
+			s    string
+			size float64
+			bold bool
+		}{
+			{d.Date.Format("Mon"), 14, true},
+			{conditionHumanLabel(wmoCondition(d.WeatherCode)), 12, false},
+			{fmt.Sprintf("%d° / %d°", int(round(d.TempMax)), int(round(d.TempMin))), 18, true},
+			{rain, 12, false},
+			{fmt.Sprintf("%s %d km/h", windArrowFor(int(round(d.WindDirDominant))), int(round(d.WindMax))), 12, false},
+		}
+		step := (h - 16) / float64(len(lines))
+		for j, l := range lines {
+			c.Text(x, top+8+step*(float64(j)+0.5), l.s, l.size, textMiddle, true, l.bold, c.fg, 1)
+		}
+	}
+}
+
+// ditherGray reduces img to bpp bits of grey. 1, 2 and 4 bits are dithered
+// with Floyd–Steinberg onto evenly spaced greys, which the PNG encoder then
+// packs at that bit depth; anything else is plain 8-bit greyscale.
+func ditherGray(img *image.RGBA, bpp int) image.Image {
+	b := img.Bounds()
+	switch bpp {
+	case 1, 2, 4:
+	default:
+		gray := image.NewGray(b)
+		draw.Draw(gray, b, img, b.Min, draw.Src)
+		return gray
+	}
+	// Go to grey first so the error diffuses along a single axis; dithering
+	// the colour image straight onto a grey palette picks by RGB distance.
+	gray := image.NewGray(b)
+	draw.Draw(gray, b, img, b.Min, draw.Src)
+	levels := 1 << bpp
+	pal := make(color.Palette, levels)
+	for i := range pal {
+		v := uint8(i * 255 / (levels - 1))
+		pal[i] = color.Gray{Y: v}
+	}
+	out := image.NewPaletted(b, pal)
+	draw.FloydSteinberg.Draw(out, b, gray, b.Min)
+	return out
+}
+
+// parseBPPParam reads ?bpp, the panel's bits per pixel: 1, 2, 4 or 8.
+// Anything else is 1, the common denominator of e-ink panels.
+func parseBPPParam(r *http.Request) int {
+	switch v, _ := strconv.Atoi(r.URL.Query().Get("bpp")); v {
+	case 2, 4, 8:
+		return v
+	default:
+		return 1
+	}
+}
+
+// handleDashboardPNG serves the dashboard with a Refresh header telling the
+// panel when to fetch it again.
+func handleDashboardPNG(w http.ResponseWriter, r *http.Request) {
+	lat, lon, name := locationQuery(r)
+	loc, err := ResolveLocationFor(lat, lon, name)
+	if err != nil {
+		http.Error(w, "could not resolve location: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	width, height, ro := parseRasterParams(r)
+	if width == 0 {
+		width = dashboardDefaultW
+	}
+	if height == 0 {
+		height = dashboardDefaultH
+	}
+	glance, err := buildGlanceResponse(r.Context(), loc, true, NoProgress)
+	if glance == nil {
+		writePNG(w, r, nil, err)
+		return
+	}
+	daily, err := GetOpenMeteoDailyRange(loc.Latitude, loc.Longitude, dashboardDays)
+	if err != nil {
+		// The days are the least time-critical part; draw the rest.
+		slog.Debug("dashboard daily forecast", "err", err)
+	}
+	d := dashboardData{
+		Glance: glance,
+		Daily:  daily,
+		Now:    time.Now().In(locationZone(loc.Latitude, loc.Longitude)),
+	}
+	w.Header().Set("Refresh", strconv.Itoa(dashboardRefresh(glance)))
+	img, err := RenderDashboardPNG(d, width, height, ro, parseBPPParam(r))
+	writePNG(w, r, img, err)
+}
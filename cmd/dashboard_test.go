@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestDitherGray(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	for i := 0; i < len(src.Pix); i += 4 {
+		src.Pix[i], src.Pix[i+1], src.Pix[i+2], src.Pix[i+3] = 0x80, 0x80, 0x80, 0xff
+	}
+	tests := []struct {
+		name      string
+		bpp       int
+		colors    int  // palette size; 0 for plain greyscale
+		halfBlack bool // mid grey should come out as roughly half black, half white
+	}{
+		{name: "1 bit", bpp: 1, colors: 2, halfBlack: true},
+		{name: "2 bit", bpp: 2, colors: 4},
+		{name: "4 bit", bpp: 4, colors: 16},
+		{name: "8 bit", bpp: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := ditherGray(src, tt.bpp)
+			if tt.colors == 0 {
+				if _, ok := out.(*image.Gray); !ok {
+					t.Fatalf("got %T, want plain greyscale", out)
+				}
+				return
+			}
+			p, ok := out.(*image.Paletted)
+			if !ok || len(p.Palette) != tt.colors {
+				t.Fatalf("want a %d-colour paletted image", tt.colors)
+			}
+			if !tt.halfBlack {
+				return
+			}
+			black := 0
+			for _, v := range p.Pix {
+				if v == 0 {
+					black++
+				}
+			}
+			if frac := float64(black) / float64(len(p.Pix)); frac < 0.4 || frac > 0.6 {
+				t.Fatalf("mid grey is %.2f black, want ≈0.5", frac)
+			}
+		})
+	}
+}
+
+func TestRenderDashboardPNG(t *testing.T) {
+	d := dashboardData{
+		Glance: &glanceAPIResponse{Location: Location{Description: "Utrecht"}},
+		Daily:  []DailyAggregate{{Date: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)}},
+		Now:    time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		name string
+		w, h int
+		bpp  int
+	}{
+		{name: "7.5 inch panel, 1 bit", w: 800, h: 480, bpp: 1},
+		{name: "small panel, greyscale", w: 400, h: 300, bpp: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := RenderDashboardPNG(d, tt.w, tt.h, RasterOpts{}, tt.bpp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, err := png.Decode(bytes.NewReader(b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := img.Bounds().Size(); got.X != tt.w || got.Y != tt.h {
+				t.Fatalf("dashboard size = %v, want %dx%d", got, tt.w, tt.h)
+			}
+		})
+	}
+}
@@ -0,0 +1,436 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ensembleModel   = "ecmwf_ifs025" // 51 members, 15 days
+	ensembleGridDeg = 0.25           // native grid of ensembleModel; requests snap to it
+	wetDayMm        = 1.0            // a member's daily total at or above this counts as a wet day
+)
+
+// EnsembleData holds every member's hourly run for one point. The member
+// slices are indexed [member][hour] and aligned with Times; NaN marks a
+// value the API left null.
+type EnsembleData struct {
+	Times         []time.Time
+	Temperature   [][]float64
+	Precipitation [][]float64
+	WindSpeed     [][]float64
+	WindGusts     [][]float64
+}
+
+// Percentiles summarises one quantity across ensemble members.
+type Percentiles struct {
+	P10  float64 `json:"p10"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	Mean float64 `json:"mean"`
+}
+
+// MarshalJSON writes a summary with no members behind it (all NaN) as nulls,
+// which encoding/json would otherwise refuse.
+func (p Percentiles) MarshalJSON() ([]byte, error) {
+	num := func(v float64) *float64 {
+		if math.IsNaN(v) {
+			return nil
+		}
+		return &v
+	}
+	return json.Marshal(struct {
+		P10  *float64 `json:"p10"`
+		P50  *float64 `json:"p50"`
+		P90  *float64 `json:"p90"`
+		Mean *float64 `json:"mean"`
+	}{num(p.P10), num(p.P50), num(p.P90), num(p.Mean)})
+}
+
+// EnsembleHour is the member spread for one hour.
+type EnsembleHour struct {
+	Time          time.Time   `json:"time"`
+	Temperature   Percentiles `json:"temperature"`
+	Precipitation Percentiles `json:"precipitation"`
+	WindSpeed     Percentiles `json:"windSpeed"`
+	WindGusts     Percentiles `json:"windGusts"`
+	RainChance    float64     `json:"rainChance"` // fraction of members with precipitation > rainThresholdMm
+	GustChance    float64     `json:"gustChance"` // fraction of members with gusts ≥ gustDisqualify
+}
+
+// EnsembleDay is the member spread of one day's aggregates: each member's
+// own high, low and total are computed first, then summarised.
+type EnsembleDay struct {
+	Date       time.Time   `json:"date"`
+	TempMax    Percentiles `json:"tempMax"`
+	TempMin    Percentiles `json:"tempMin"`
+	PrecipSum  Percentiles `json:"precipSum"`
+	WetChance  float64     `json:"wetChance"`  // fraction of members with ≥ wetDayMm for the day
+	GustChance float64     `json:"gustChance"` // fraction of members with any gust ≥ gustDisqualify
+}
+
+// GetOpenMeteoEnsemble fetches every ensemble member for the inclusive
+// [startDate, endDate] range. The point is snapped to the model's 0.25° grid
+// first, so nearby callers (beam-search leg samples, heatmap cells) share one
+// request. Cached process-wide (ensembleCache); treat the result as read-only.
+func GetOpenMeteoEnsemble(lat, lon float64, startDate, endDate time.Time) (*EnsembleData, error) {
+	lat, lon = snapToGrid(lat, ensembleGridDeg), snapToGrid(lon, ensembleGridDeg)
+	query := fmt.Sprintf("start_date=%s&end_date=%s",
+		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	return memo(ensembleCache, fmt.Sprintf("%.2f|%.2f|%s", lat, lon, query), func() (*EnsembleData, error) {
+		return getOpenMeteoEnsembleUncached(lat, lon, query)
+	})
+}
+
+// GetOpenMeteoEnsembleDays fetches every ensemble member for `days` days
+// starting today, for the forecast views' spread columns.
+func GetOpenMeteoEnsembleDays(lat, lon float64, days int) (*EnsembleData, error) {
+	lat, lon = snapToGrid(lat, ensembleGridDeg), snapToGrid(lon, ensembleGridDeg)
+	query := fmt.Sprintf("forecast_days=%d", days)
+	return memo(ensembleCache, fmt.Sprintf("%.2f|%.2f|%s", lat, lon, query), func() (*EnsembleData, error) {
+		return getOpenMeteoEnsembleUncached(lat, lon, query)
+	})
+}
+
+func getOpenMeteoEnsembleUncached(lat, lon float64, query string) (*EnsembleData, error) {
+	url := fmt.Sprintf("https://ensemble-api.open-meteo.com/v1/ensemble?"+
+		"latitude=%.4f&longitude=%.4f&models=%s&%s&timezone=auto"+
+		"&hourly=temperature_2m,precipitation,wind_speed_10m,wind_gusts_10m",
+		lat, lon, ensembleModel, query)
+	body, err := openMeteoGetBody(url)
+	if err != nil {
+		return nil, fmt.Errorf("ensemble request: %w", err)
+	}
+	data, err := parseEnsemble(body)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// parseEnsemble decodes an ensemble API response. Each variable comes back as
+// a control run ("temperature_2m") plus one array per perturbed member
+// ("temperature_2m_member01", …); all of them become members here.
+func parseEnsemble(raw []byte) (*EnsembleData, error) {
+	var parsed struct {
+		Timezone  string                     `json:"timezone"`
+		UTCOffset int                        `json:"utc_offset_seconds"`
+		Hourly    map[string]json.RawMessage `json:"hourly"`
+	}
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("decode ensemble: %w", err)
+	}
+	var times []string
+	if err := json.Unmarshal(parsed.Hourly["time"], &times); err != nil {
+		return nil, fmt.Errorf("decode ensemble times: %w", err)
+	}
+	zone := openMeteoZone(parsed.Timezone, parsed.UTCOffset)
+	out := &EnsembleData{Times: make([]time.Time, len(times))}
+	for i, s := range times {
+		t, err := time.ParseInLocation("2006-01-02T15:04", s, zone)
+		if err != nil {
+			return nil, fmt.Errorf("ensemble time %q: %w", s, err)
+		}
+		out.Times[i] = t
+	}
+
+	for _, v := range []struct {
+		name string
+		dst  *[][]float64
+	}{
+		{"temperature_2m", &out.Temperature},
+		{"precipitation", &out.Precipitation},
+		{"wind_speed_10m", &out.WindSpeed},
+		{"wind_gusts_10m", &out.WindGusts},
+	} {
+		members, err := ensembleMembers(parsed.Hourly, v.name, len(times))
+		if err != nil {
+			return nil, err
+		}
+		*v.dst = members
+	}
+	if len(out.Temperature) == 0 {
+		return nil, fmt.Errorf("ensemble response has no members")
+	}
+	return out, nil
+}
+
+// ensembleMembers collects the control and member arrays for one variable,
+// in key order so member indices are stable across variables.
+func ensembleMembers(hourly map[string]json.RawMessage, name string, n int) ([][]float64, error) {
+	var keys []string
+	for k := range hourly {
+		if k == name || strings.HasPrefix(k, name+"_member") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	out := make([][]float64, 0, len(keys))
+	for _, k := range keys {
+		var vals []*float64
+		if err := json.Unmarshal(hourly[k], &vals); err != nil {
+			return nil, fmt.Errorf("decode ensemble %s: %w", k, err)
+		}
+		if len(vals) != n {
+			return nil, fmt.Errorf("ensemble %s: %d values for %d times", k, len(vals), n)
+		}
+		row := make([]float64, n)
+		for i, v := range vals {
+			row[i] = math.NaN()
+			if v != nil {
+				row[i] = *v
+			}
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+// Hours summarises the member spread hour by hour.
+func (e *EnsembleData) Hours() []EnsembleHour {
+	out := make([]EnsembleHour, len(e.Times))
+	for i, t := range e.Times {
+		precip := column(e.Precipitation, i)
+		gusts := column(e.WindGusts, i)
+		out[i] = EnsembleHour{
+			Time:          t,
+			Temperature:   summarize(column(e.Temperature, i)),
+			Precipitation: summarize(precip),
+			WindSpeed:     summarize(column(e.WindSpeed, i)),
+			WindGusts:     summarize(gusts),
+			RainChance:    fractionAbove(precip, func(v float64) bool { return v > rainThresholdMm }),
+			GustChance:    fractionAbove(gusts, func(v float64) bool { return v >= gustDisqualify }),
+		}
+	}
+	return out
+}
+
+// Days aggregates each member per local calendar day, then summarises the
+// members. Days are returned in time order.
+func (e *EnsembleData) Days() []EnsembleDay {
+	type span struct {
+		date     time.Time
+		from, to int // hour indices, to exclusive
+	}
+	var spans []span
+	for i, t := range e.Times {
+		d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		if len(spans) == 0 || !spans[len(spans)-1].date.Equal(d) {
+			spans = append(spans, span{date: d, from: i})
+		}
+		spans[len(spans)-1].to = i + 1
+	}
+
+	out := make([]EnsembleDay, 0, len(spans))
+	for _, s := range spans {
+		members := len(e.Temperature)
+		hi := make([]float64, members)
+		lo := make([]float64, members)
+		sum := make([]float64, members)
+		gust := make([]float64, members)
+		for m := 0; m < members; m++ {
+			hi[m], lo[m] = rangeOf(e.Temperature[m][s.from:s.to])
+			sum[m] = math.NaN()
+			if m < len(e.Precipitation) {
+				sum[m] = total(e.Precipitation[m][s.from:s.to])
+			}
+			gust[m] = math.NaN()
+			if m < len(e.WindGusts) {
+				gust[m], _ = rangeOf(e.WindGusts[m][s.from:s.to])
+			}
+		}
+		out = append(out, EnsembleDay{
+			Date:       s.date,
+			TempMax:    summarize(hi),
+			TempMin:    summarize(lo),
+			PrecipSum:  summarize(sum),
+			WetChance:  fractionAbove(sum, func(v float64) bool { return v >= wetDayMm }),
+			GustChance: fractionAbove(gust, func(v float64) bool { return v >= gustDisqualify }),
+		})
+	}
+	return out
+}
+
+// Scenario returns a copy of base with temperature, precipitation and wind
+// replaced by the member spread at percentile q (0..100): precipitation, wind
+// and gusts at q, temperature at 100−q. q=50 is the expected (median) day;
+// q=90 is a risk-averse one that only stays dry when ≥90% of members do, with
+// the wind and cold of the worse members. Feels-like shifts with temperature.
+// Hours the ensemble doesn't cover keep their deterministic values.
+func (e *EnsembleData) Scenario(q float64, base []HourlyForecast) []HourlyForecast {
+	idx := make(map[int64]int, len(e.Times))
+	for i, t := range e.Times {
+		idx[t.Unix()] = i
+	}
+	frac := q / 100
+	out := make([]HourlyForecast, len(base))
+	copy(out, base)
+	for j := range out {
+		i, ok := idx[out[j].Time.Unix()]
+		if !ok {
+			continue
+		}
+		if t := percentile(column(e.Temperature, i), 1-frac); !math.IsNaN(t) {
+			out[j].ApparentTemperature += t - out[j].Temperature
+			out[j].Temperature = t
+		}
+		if v := percentile(column(e.Precipitation, i), frac); !math.IsNaN(v) {
+			out[j].Precipitation = v
+		}
+		if v := percentile(column(e.WindSpeed, i), frac); !math.IsNaN(v) {
+			out[j].WindSpeed = v
+		}
+		if v := percentile(column(e.WindGusts, i), frac); !math.IsNaN(v) {
+			out[j].WindGusts = v
+		}
+	}
+	return out
+}
+
+// withEnsembleScenario swaps data's hourly series for the ensemble scenario at
+// percentile risk (see Scenario). risk ≤ 0 returns data untouched. A failed
+// ensemble fetch is logged and also falls back to the deterministic run, so
+// a flaky ensemble endpoint degrades the plan instead of emptying it.
+func withEnsembleScenario(data *OpenMeteoData, lat, lon float64, startDate, endDate time.Time, risk float64) *OpenMeteoData {
+	if risk <= 0 || data == nil {
+		return data
+	}
+	ens, err := GetOpenMeteoEnsemble(lat, lon, startDate, endDate)
+	if err != nil {
+		slog.Debug("ensemble: falling back to deterministic run", "lat", lat, "lon", lon, "err", err)
+		return data
+	}
+	return &OpenMeteoData{
+		Hourly:    ens.Scenario(risk, data.Hourly),
+		Daily:     data.Daily,
+		Elevation: data.Elevation,
+	}
+}
+
+// ensembleDaysByDate indexes EnsembleDays by "2006-01-02" so forecast rows can
+// look up their spread; a nil map when the fetch failed.
+func ensembleDaysByDate(lat, lon float64, days int) map[string]EnsembleDay {
+	ens, err := GetOpenMeteoEnsembleDays(lat, lon, days)
+	if err != nil {
+		slog.Debug("ensemble: spread unavailable", "err", err)
+		return nil
+	}
+	out := map[string]EnsembleDay{}
+	for _, d := range ens.Days() {
+		out[d.Date.Format("2006-01-02")] = d
+	}
+	return out
+}
+
+// ---------- member statistics ----------
+
+// column returns hour i of every member.
+func column(members [][]float64, i int) []float64 {
+	out := make([]float64, 0, len(members))
+	for _, m := range members {
+		if i < len(m) {
+			out = append(out, m[i])
+		}
+	}
+	return out
+}
+
+// percentile returns the q-quantile (0..1) of vals by linear interpolation
+// between order statistics, ignoring NaNs. NaN if nothing is left.
+func percentile(vals []float64, q float64) float64 {
+	s := make([]float64, 0, len(vals))
+	for _, v := range vals {
+		if !math.IsNaN(v) {
+			s = append(s, v)
+		}
+	}
+	if len(s) == 0 {
+		return math.NaN()
+	}
+	sort.Float64s(s)
+	q = math.Max(0, math.Min(1, q))
+	pos := q * float64(len(s)-1)
+	lo := int(math.Floor(pos))
+	if lo == len(s)-1 {
+		return s[lo]
+	}
+	return s[lo] + (pos-float64(lo))*(s[lo+1]-s[lo])
+}
+
+func summarize(vals []float64) Percentiles {
+	mean, n := 0.0, 0
+	for _, v := range vals {
+		if !math.IsNaN(v) {
+			mean += v
+			n++
+		}
+	}
+	if n == 0 {
+		nan := math.NaN()
+		return Percentiles{P10: nan, P50: nan, P90: nan, Mean: nan}
+	}
+	return Percentiles{
+		P10:  percentile(vals, 0.1),
+		P50:  percentile(vals, 0.5),
+		P90:  percentile(vals, 0.9),
+		Mean: mean / float64(n),
+	}
+}
+
+// fractionAbove is the share of non-NaN vals for which hit is true.
+func fractionAbove(vals []float64, hit func(float64) bool) float64 {
+	hits, n := 0, 0
+	for _, v := range vals {
+		if math.IsNaN(v) {
+			continue
+		}
+		n++
+		if hit(v) {
+			hits++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return float64(hits) / float64(n)
+}
+
+// rangeOf returns the max and min of vals, ignoring NaNs (NaN, NaN if none).
+func rangeOf(vals []float64) (hi, lo float64) {
+	hi, lo = math.Inf(-1), math.Inf(1)
+	for _, v := range vals {
+		if math.IsNaN(v) {
+			continue
+		}
+		hi, lo = math.Max(hi, v), math.Min(lo, v)
+	}
+	if math.IsInf(hi, -1) {
+		return math.NaN(), math.NaN()
+	}
+	return hi, lo
+}
+
+// total sums vals, ignoring NaNs; NaN if every value is missing.
+func total(vals []float64) float64 {
+	sum, n := 0.0, 0
+	for _, v := range vals {
+		if !math.IsNaN(v) {
+			sum += v
+			n++
+		}
+	}
+	if n == 0 {
+		return math.NaN()
+	}
+	return sum
+}
+
+// snapToGrid rounds v to the nearest multiple of step.
+func snapToGrid(v, step float64) float64 {
+	return math.Round(v/step) * step
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestPercentile(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name string
+		vals []float64
+		q    float64
+		want float64
+	}{
+		{"median odd", []float64{3, 1, 2}, 0.5, 2},
+		{"interpolated", []float64{0, 10}, 0.9, 9},
+		{"min", []float64{5, 1, 9}, 0, 1},
+		{"max", []float64{5, 1, 9}, 1, 9},
+		{"ignores NaN", []float64{nan, 4, nan, 8}, 0.5, 6},
+		{"single", []float64{7}, 0.1, 7},
+		{"only NaN", []float64{nan}, 0.5, nan},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := percentile(tc.vals, tc.q)
+			if math.IsNaN(tc.want) != math.IsNaN(got) || math.Abs(got-tc.want) > 1e-9 {
+				t.Fatalf("percentile(%v, %v) = %v, want %v", tc.vals, tc.q, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEnsembleDays(t *testing.T) {
+	raw := []byte(`{"timezone":"UTC","hourly":{
+		"time":["2026-05-01T10:00","2026-05-01T11:00","2026-05-02T10:00"],
+		"temperature_2m":[10,14,8],
+		"temperature_2m_member01":[12,16,9],
+		"precipitation":[0,0,0],
+		"precipitation_member01":[0.6,0.6,null],
+		"wind_speed_10m":[5,5,5],
+		"wind_speed_10m_member01":[5,5,5],
+		"wind_gusts_10m":[20,20,20],
+		"wind_gusts_10m_member01":[30,65,20]}}`)
+	ens, err := parseEnsemble(raw)
+	if err != nil {
+		t.Fatalf("parseEnsemble() error: %v", err)
+	}
+	days := ens.Days()
+	if len(days) != 2 {
+		t.Fatalf("Days() returned %d days, want 2", len(days))
+	}
+	tests := []struct {
+		name       string
+		day        int
+		maxP50     float64
+		minP10     float64
+		wetChance  float64
+		gustChance float64
+	}{
+		{"member spread", 0, 15, 10.2, 0.5, 0.5},
+		{"null precipitation isn't wet", 1, 8.5, 8.1, 0, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := days[tc.day]
+			if math.Abs(d.TempMax.P50-tc.maxP50) > 1e-9 || math.Abs(d.TempMin.P10-tc.minP10) > 1e-9 {
+				t.Fatalf("temps = max %+v min %+v, want max p50 %v min p10 %v", d.TempMax, d.TempMin, tc.maxP50, tc.minP10)
+			}
+			if d.WetChance != tc.wetChance || d.GustChance != tc.gustChance {
+				t.Fatalf("wet %v gust %v, want %v and %v", d.WetChance, d.GustChance, tc.wetChance, tc.gustChance)
+			}
+		})
+	}
+}
+
+func TestEnsembleHours(t *testing.T) {
+	raw := []byte(`{"timezone":"UTC","hourly":{
+		"time":["2026-05-01T10:00","2026-05-01T11:00"],
+		"temperature_2m":[10,14],
+		"temperature_2m_member01":[12,16],
+		"precipitation":[0,0],
+		"precipitation_member01":[0.6,null],
+		"wind_speed_10m":[5,5],
+		"wind_speed_10m_member01":[7,5],
+		"wind_gusts_10m":[20,null],
+		"wind_gusts_10m_member01":[65,null]}}`)
+	ens, err := parseEnsemble(raw)
+	if err != nil {
+		t.Fatalf("parseEnsemble() error: %v", err)
+	}
+	hours := ens.Hours()
+	if len(hours) != 2 {
+		t.Fatalf("Hours() returned %d hours, want 2", len(hours))
+	}
+	tests := []struct {
+		name       string
+		hour       int
+		tempP50    float64
+		windP90    float64
+		rainChance float64
+		gustChance float64
+		json       string
+	}{
+		{"member spread", 0, 11, 6.8, 0.5, 0.5, `"windGusts":{"p10":24.5,"p50":42.5,"p90":60.5,"mean":42.5}`},
+		{"all gusts null", 1, 15, 5, 0, 0, `"windGusts":{"p10":null,"p50":null,"p90":null,"mean":null}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h := hours[tc.hour]
+			if math.Abs(h.Temperature.P50-tc.tempP50) > 1e-9 || math.Abs(h.WindSpeed.P90-tc.windP90) > 1e-9 {
+				t.Fatalf("temp p50 %v wind p90 %v, want %v and %v", h.Temperature.P50, h.WindSpeed.P90, tc.tempP50, tc.windP90)
+			}
+			if h.RainChance != tc.rainChance || h.GustChance != tc.gustChance {
+				t.Fatalf("rain %v gust %v, want %v and %v", h.RainChance, h.GustChance, tc.rainChance, tc.gustChance)
+			}
+			b, err := json.Marshal(h)
+			if err != nil {
+				t.Fatalf("json.Marshal() error: %v", err)
+			}
+			if !strings.Contains(string(b), tc.json) {
+				t.Fatalf("json = %s, want it to contain %s", b, tc.json)
+			}
+		})
+	}
+}
@@ -33,6 +33,45 @@ func GetBuineradarForecast(lat, long float64) (*Forecast, error) {
 }
 
 func getBuineradarForecastUncached(lat, long float64) (*Forecast, error) {
+	series, err := fetchBuineradarSeries(lat, long)
+	if err != nil {
+		return nil, err
+	}
+	forecast := &Forecast{
+		Desc: "Buienradar",
+		Type: PrecipitationForecast,
+	}
+	now := time.Now()
+	for _, p := range series {
+		if p.Time.After(now) {
+			forecast.Data = append(forecast.Data, p)
+		}
+	}
+	return forecast, nil
+}
+
+// GetBuineradarObserved returns the radar-measured precipitation (mm/h) for
+// the past couple of hours — the "history" half of the same RainHistoryForecast
+// response the nowcast comes from. Not cached: the verification recorder is
+// its only caller and wants it fresh.
+func GetBuineradarObserved(lat, long float64) ([]ForecastDataPoint, error) {
+	series, err := fetchBuineradarSeries(lat, long)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var out []ForecastDataPoint
+	for _, p := range series {
+		if !p.Time.After(now) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// fetchBuineradarSeries returns every point of the RainHistoryForecast
+// response, past radar and future nowcast alike.
+func fetchBuineradarSeries(lat, long float64) ([]ForecastDataPoint, error) {
 	slog.Debug("buineradar: getting forecast", "lat", lat, "lon", long)
 	url := fmt.Sprintf("https://graphdata.buienradar.nl/3.0/forecast/geo/RainHistoryForecast?lat=%.3f&lon=%.3f", lat, long)
 	slog.Debug("buineradar: requesting", "url", url)
@@ -64,21 +103,13 @@ func getBuineradarForecastUncached(lat, long float64) (*Forecast, error) {
 		return nil, err
 	}
 
-	forecast := &Forecast{
-		Desc: "Buienradar",
-		Type: PrecipitationForecast,
-	}
+	series := make([]ForecastDataPoint, 0, len(buineradarResponse.Forecasts))
 	for _, data := range buineradarResponse.Forecasts {
 		t, err := time.Parse("2006-01-02T15:04:05", data.UtcDateTime)
 		if err != nil {
 			return nil, err
 		}
-		if t.After(time.Now()) {
-			forecast.Data = append(forecast.Data, ForecastDataPoint{
-				Time:  t,
-				Value: data.DataValue,
-			})
-		}
+		series = append(series, ForecastDataPoint{Time: t, Value: data.DataValue})
 	}
-	return forecast, nil
+	return series, nil
 }
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ---------- provider fusion ----------
+
+// Buienalarm and Buienradar forecast the same rain from different radar
+// processing, and neither is right every time. The fusion puts both on one
+// 5-minute grid and blends them by how much each is trusted, keeping the
+// range they span as an envelope around the blend and flagging where they
+// tell different stories.
+
+const (
+	fusionStep = 5 * time.Minute
+
+	// fusionDisagree flags a step where the providers differ by at least
+	// half their sum: 1 against 3 mm/h, or any rain against none.
+	fusionDisagree = 0.5
+
+	// fusionMinPairs is how many verified points each provider needs before
+	// its error sets its weight; fewer and the weights stay equal.
+	fusionMinPairs = 200
+	// fusionMinMAE floors the verified error, in mm/h, so a provider that
+	// was right through a dry spell can't take all the weight.
+	fusionMinMAE = 0.05
+)
+
+// RainFusion is the blended best-estimate nowcast of both providers.
+type RainFusion struct {
+	Weights      map[string]float64 `json:"weights"`       // per provider, summing to 1
+	WeightSource string             `json:"weight_source"` // "equal", "config" or "verification"
+	Series       []FusionPoint      `json:"series"`
+	// Disagreement is the mean relative difference of the providers over
+	// the steps where either has rain: 0 when they match, 1 when one's rain
+	// is never the other's.
+	Disagreement float64 `json:"disagreement"`
+	// Disagreements are the stretches of steps at or above fusionDisagree;
+	// each step covers the fusionStep from its time.
+	Disagreements []RainSpan `json:"disagreements"`
+}
+
+// FusionPoint is one step of the fused nowcast: the blend and the envelope
+// the providers span around it, all in mm/h.
+type FusionPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+	Lo    float64   `json:"lo"`
+	Hi    float64   `json:"hi"`
+}
+
+// rainTrust weighs the providers in the blend.
+type rainTrust struct {
+	Buienalarm, Buienradar float64
+	Source                 string
+}
+
+var equalRainTrust = rainTrust{Buienalarm: 1, Buienradar: 1, Source: "equal"}
+
+// FuseRain blends the providers over the union of their ranges, so the
+// fusion runs on with Buienradar alone after Buienalarm's horizon. A step
+// with only one provider takes its value as it is, with no envelope. Nil
+// when neither has points.
+func FuseRain(alarm, radar *Forecast, trust rainTrust) *RainFusion {
+	alarmPts, radarPts := forecastPoints(alarm), forecastPoints(radar)
+	var start, horizon time.Time
+	for _, pts := range [][]ForecastDataPoint{alarmPts, radarPts} {
+		if len(pts) == 0 {
+			continue
+		}
+		if first := pts[0].Time; start.IsZero() || first.Before(start) {
+			start = first
+		}
+		if last := pts[len(pts)-1].Time; last.After(horizon) {
+			horizon = last
+		}
+	}
+	if start.IsZero() {
+		return nil
+	}
+	if t := start.Truncate(fusionStep); t.Before(start) {
+		start = t.Add(fusionStep)
+	}
+
+	total := trust.Buienalarm + trust.Buienradar
+	f := &RainFusion{
+		Weights: map[string]float64{
+			providerBuienalarm: trust.Buienalarm / total,
+			providerBuienradar: trust.Buienradar / total,
+		},
+		WeightSource:  trust.Source,
+		Disagreements: []RainSpan{},
+	}
+	var sumDiff float64
+	var wetSteps int
+	var span *RainSpan
+	for t := start; !t.After(horizon); t = t.Add(fusionStep) {
+		av, aok := seriesAt(alarmPts, t)
+		rv, rok := seriesAt(radarPts, t)
+		p := FusionPoint{Time: t}
+		switch {
+		case aok && rok:
+			p.Value = (av*trust.Buienalarm + rv*trust.Buienradar) / total
+			p.Lo, p.Hi = min(av, rv), max(av, rv)
+		case aok:
+			p.Value, p.Lo, p.Hi = av, av, av
+		case rok:
+			p.Value, p.Lo, p.Hi = rv, rv, rv
+		default:
+			continue
+		}
+		f.Series = append(f.Series, p)
+
+		diff := 0.0
+		if aok && rok && p.Hi >= DryThresholdMmH {
+			diff = (p.Hi - p.Lo) / (p.Hi + p.Lo)
+			sumDiff += diff
+			wetSteps++
+		}
+		switch {
+		case diff >= fusionDisagree && span != nil:
+			*span = newRainSpan(span.From, t.Add(fusionStep))
+		case diff >= fusionDisagree:
+			f.Disagreements = append(f.Disagreements, newRainSpan(t, t.Add(fusionStep)))
+			span = &f.Disagreements[len(f.Disagreements)-1]
+		default:
+			span = nil
+		}
+	}
+	if wetSteps > 0 {
+		f.Disagreement = math.Round(sumDiff/float64(wetSteps)*100) / 100
+	}
+	return f
+}
+
+// currentRainTrust is the trust the config sets, else the one verification
+// has earned, else equal.
+func currentRainTrust(ctx context.Context) rainTrust {
+	cfg, err := cachedConfig()
+	if err != nil {
+		slog.Log(ctx, LevelTrace, "rain trust: config", "err", err)
+	} else if len(cfg.Trust) > 0 {
+		t := rainTrust{Buienalarm: 1, Buienradar: 1, Source: "config"}
+		if w, ok := cfg.Trust[providerBuienalarm]; ok {
+			t.Buienalarm = w
+		}
+		if w, ok := cfg.Trust[providerBuienradar]; ok {
+			t.Buienradar = w
+		}
+		return t
+	}
+	t, err := memo(rainTrustCache, "verification", verifiedRainTrust)
+	if err != nil {
+		slog.Log(ctx, LevelTrace, "rain trust: verification", "err", err)
+		return equalRainTrust
+	}
+	return t
+}
+
+// verifiedRainTrust weighs each provider by the inverse of its nowcast error
+// in the verification store. The store scores against Buienradar's own
+// radar, which flatters Buienradar somewhat; it's still the only history
+// there is. It doesn't create the store: no recording, no weights.
+func verifiedRainTrust() (rainTrust, error) {
+	base, err := dataDir()
+	if err != nil {
+		return rainTrust{}, err
+	}
+	store := &verificationStore{dir: filepath.Join(base, "verification")}
+	if _, err := os.Stat(store.snapshotsPath()); errors.Is(err, fs.ErrNotExist) {
+		return rainTrust{}, errors.New("nothing recorded")
+	}
+	snaps, err := store.Snapshots()
+	if err != nil {
+		return rainTrust{}, err
+	}
+	obs, err := store.Observations()
+	if err != nil {
+		return rainTrust{}, err
+	}
+	sumAbs, n := map[string]float64{}, map[string]int{}
+	for _, m := range computeVerification(snaps, obs, "") {
+		if m.Variable == varPrecip {
+			sumAbs[m.Provider] += m.MAE * float64(m.N)
+			n[m.Provider] += m.N
+		}
+	}
+	weight := func(provider string) (float64, error) {
+		if n[provider] < fusionMinPairs {
+			return 0, fmt.Errorf("%s: %d verified points, want %d", provider, n[provider], fusionMinPairs)
+		}
+		return 1 / max(sumAbs[provider]/float64(n[provider]), fusionMinMAE), nil
+	}
+	t := rainTrust{Source: "verification"}
+	if t.Buienalarm, err = weight(providerBuienalarm); err != nil {
+		return rainTrust{}, err
+	}
+	if t.Buienradar, err = weight(providerBuienradar); err != nil {
+		return rainTrust{}, err
+	}
+	return t, nil
+}
+
+// fusionBand is the fused nowcast up to horizon as a blend line and the
+// envelope around it, for rainChart. A zero horizon keeps every step.
+func fusionBand(f *RainFusion, horizon time.Time) (SVGSeries, SVGBand) {
+	line := SVGSeries{Name: "Blend", Color: "currentColor", Dashed: true, NoFill: true}
+	band := SVGBand{Color: "currentColor"}
+	for _, p := range f.Series {
+		if !horizon.IsZero() && p.Time.After(horizon) {
+			break
+		}
+		line.Data = append(line.Data, ForecastDataPoint{Time: p.Time, Value: p.Value})
+		band.Data = append(band.Data, SVGBandPoint{Time: p.Time, Lo: p.Lo, Hi: p.Hi})
+	}
+	return line, band
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFuseRain(t *testing.T) {
+	now := time.Date(2026, 5, 1, 14, 0, 0, 0, time.UTC)
+	alarm := testRainLine(now, 1, 1, 1, 0, 0)
+	// Off the grid by a minute, so every step is interpolated, and running
+	// on past Buienalarm's last point.
+	radar := testRainLine(now.Add(-time.Minute), 1, 1, 1, 1, 3, 3, 2, 2)
+	f := FuseRain(alarm, radar, rainTrust{Buienalarm: 3, Buienradar: 1, Source: "config"})
+
+	tests := []struct {
+		name          string
+		step          int
+		value, lo, hi float64
+	}{
+		{name: "agree", step: 0, value: 1, lo: 1, hi: 1},
+		{name: "radar still wet", step: 3, value: 0.35, lo: 0, hi: 1.4},
+		{name: "alarm's last point", step: 4, value: 0.75, lo: 0, hi: 3},
+		{name: "radar alone after the alarm", step: 5, value: 2.8, lo: 2.8, hi: 2.8},
+		{name: "radar's last step", step: 6, value: 2, lo: 2, hi: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := f.Series[tt.step]
+			if !p.Time.Equal(now.Add(time.Duration(tt.step) * fusionStep)) {
+				t.Fatalf("step %d at %v", tt.step, p.Time)
+			}
+			if math.Abs(p.Value-tt.value) > 1e-9 || math.Abs(p.Lo-tt.lo) > 1e-9 || math.Abs(p.Hi-tt.hi) > 1e-9 {
+				t.Errorf("step %d = %.2f [%.2f, %.2f], want %.2f [%.2f, %.2f]", tt.step, p.Value, p.Lo, p.Hi, tt.value, tt.lo, tt.hi)
+			}
+		})
+	}
+}
+
+func TestFuseRainSummary(t *testing.T) {
+	now := time.Date(2026, 5, 1, 14, 0, 0, 0, time.UTC)
+	trust := rainTrust{Buienalarm: 3, Buienradar: 1, Source: "config"}
+	tests := []struct {
+		name         string
+		alarm, radar *Forecast
+		none         bool // no fusion at all
+		steps        int
+		disagreement float64
+		spans        []RainSpan
+	}{
+		{name: "both providers", alarm: testRainLine(now, 1, 1, 1, 0, 0),
+			radar: testRainLine(now.Add(-time.Minute), 1, 1, 1, 1, 3, 3, 2, 2),
+			steps: 7, disagreement: 0.4, spans: []RainSpan{newRainSpan(now.Add(15*time.Minute), now.Add(25*time.Minute))}},
+		{name: "radar only", radar: testRainLine(now, 1, 2), steps: 2, spans: []RainSpan{}},
+		{name: "neither", alarm: &Forecast{}, none: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := FuseRain(tt.alarm, tt.radar, trust)
+			if tt.none {
+				if f != nil {
+					t.Fatalf("fusion = %+v, want none", f)
+				}
+				return
+			}
+			if len(f.Series) != tt.steps || f.Weights[providerBuienalarm] != 0.75 || f.Disagreement != tt.disagreement {
+				t.Fatalf("%d steps, weights %v, disagreement %v; want %d steps, disagreement %v",
+					len(f.Series), f.Weights, f.Disagreement, tt.steps, tt.disagreement)
+			}
+			if len(f.Disagreements) != len(tt.spans) {
+				t.Fatalf("disagreements = %+v, want %+v", f.Disagreements, tt.spans)
+			}
+			for i, s := range tt.spans {
+				if got := f.Disagreements[i]; !got.From.Equal(s.From) || got.Minutes != s.Minutes {
+					t.Fatalf("disagreement %d = %+v, want %+v", i, got, s)
+				}
+			}
+		})
+	}
+}
+
+func TestRainChartHorizon(t *testing.T) {
+	now := time.Date(2026, 5, 1, 14, 0, 0, 0, time.UTC)
+	alarm := testRainLine(now, 1, 1, 1, 0, 0)
+	radar := testRainLine(now, 1, 1, 1, 1, 3, 3, 2, 2)
+	glance := &glanceAPIResponse{
+		Buienalarm: alarm,
+		Buineradar: radar,
+		Motion:     testRainLine(now, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1),
+		Fusion:     FuseRain(alarm, radar, equalRainTrust),
+	}
+	chart := rainChart(glance)
+	radarEnd := now.Add(35 * time.Minute)
+	series := map[string]SVGSeries{}
+	for _, s := range chart.Series {
+		series[s.Name] = s
+	}
+	tests := []struct {
+		name string
+		data []ForecastDataPoint
+		last time.Time
+	}{
+		{name: "Buienalarm", data: series["Buienalarm"].Data, last: now.Add(20 * time.Minute)},
+		{name: "Buineradar", data: series["Buineradar"].Data, last: radarEnd},
+		{name: "Blend", data: series["Blend"].Data, last: radarEnd},
+		{name: "Radar motion", data: series["Radar motion"].Data, last: radarEnd},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.data) == 0 {
+				t.Fatalf("no %s series", tt.name)
+			}
+			if got := tt.data[len(tt.data)-1].Time; !got.Equal(tt.last) {
+				t.Fatalf("%s ends at %v, want %v", tt.name, got, tt.last)
+			}
+		})
+	}
+	if band := chart.Opts.Bands[0].Data; !band[len(band)-1].Time.Equal(radarEnd) {
+		t.Fatalf("envelope ends at %v, want %v", band[len(band)-1].Time, radarEnd)
+	}
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// historyArchiveLagDays is how far the ERA5 reanalysis trails real time.
+// Days more recent than this come from the forecast API's own analysis of
+// the recent past instead.
+const historyArchiveLagDays = 5
+
+// Sources GetOpenMeteoHistory reports, for the views to label the data.
+const (
+	historySourceArchive = "ERA5 reanalysis"
+	historySourceRecent  = "Open-Meteo analysis of recent days"
+)
+
+// GetOpenMeteoHistory returns the hourly weather that occurred at the
+// coordinates on the local calendar days from..to (inclusive), and which
+// source it came from. Hours in the future are dropped. Past days older than
+// historyArchiveLagDays come from the archive, which has no rain probability
+// or UV index; those fields stay zero.
+func GetOpenMeteoHistory(lat, lon float64, from, to time.Time) ([]HourlyForecast, string, error) {
+	now := time.Now()
+	if from.After(now) {
+		return nil, "", fmt.Errorf("%s is in the future", from.Format("2006-01-02"))
+	}
+	if to.Before(from) {
+		return nil, "", fmt.Errorf("end date %s is before %s", to.Format("2006-01-02"), from.Format("2006-01-02"))
+	}
+
+	var hours []HourlyForecast
+	source := historySourceArchive
+	if to.After(now.AddDate(0, 0, -historyArchiveLagDays)) {
+		source = historySourceRecent
+		data, err := GetOpenMeteoRange(lat, lon, from, to)
+		if err != nil {
+			return nil, "", err
+		}
+		hours = data.Hourly
+	} else {
+		key := fmt.Sprintf("%.3f|%.3f|%s|%s", lat, lon, from.Format("2006-01-02"), to.Format("2006-01-02"))
+		var err error
+		hours, err = memo(historyCache, key, func() ([]HourlyForecast, error) {
+			return getOpenMeteoArchiveHourly(lat, lon, from, to)
+		})
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	out := make([]HourlyForecast, 0, len(hours))
+	for _, h := range hours {
+		if !h.Time.After(now) {
+			out = append(out, h)
+		}
+	}
+	if len(out) == 0 {
+		return nil, "", fmt.Errorf("no observed hours for %s–%s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+	return out, source, nil
+}
+
+// getOpenMeteoArchiveHourly fetches hourly reanalysis from the Open-Meteo
+// historical API for the local days start..end. Hours the archive has no
+// temperature for yet (the last few days) are left out; other missing values
+// read as zero.
+func getOpenMeteoArchiveHourly(lat, lon float64, start, end time.Time) ([]HourlyForecast, error) {
+	hours, err := getOpenMeteoArchive(lat, lon, start, end)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]HourlyForecast, len(hours))
+	for i, h := range hours {
+		out[i] = h.HourlyForecast
+	}
+	return out, nil
+}
+
+// archiveHour is one hour of reanalysis. HasPrecip is false where the archive
+// left precipitation null, which it does for a while after the temperature
+// is in; Precipitation then reads zero.
+type archiveHour struct {
+	HourlyForecast
+	HasPrecip bool
+}
+
+// getOpenMeteoArchive is getOpenMeteoArchiveHourly keeping track of which
+// hours have a precipitation value, for the verification recorder.
+func getOpenMeteoArchive(lat, lon float64, start, end time.Time) ([]archiveHour, error) {
+	url := fmt.Sprintf("https://archive-api.open-meteo.com/v1/archive?latitude=%.4f&longitude=%.4f"+
+		"&hourly=temperature_2m,apparent_temperature,precipitation,wind_speed_10m,wind_direction_10m,wind_gusts_10m,weather_code"+
+		"&timezone=auto&start_date=%s&end_date=%s",
+		lat, lon, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	body, err := openMeteoGetBody(url)
+	if err != nil {
+		return nil, fmt.Errorf("archive request: %w", err)
+	}
+	hours, zone, err := parseOpenMeteoArchive(body)
+	if err != nil {
+		return nil, err
+	}
+	rememberZone(lat, lon, zone)
+	return hours, nil
+}
+
+func parseOpenMeteoArchive(body []byte) ([]archiveHour, *time.Location, error) {
+	var parsed struct {
+		Timezone  string `json:"timezone"`
+		UTCOffset int    `json:"utc_offset_seconds"`
+		Hourly    struct {
+			Time          []string   `json:"time"`
+			Temperature   []*float64 `json:"temperature_2m"`
+			ApparentTemp  []*float64 `json:"apparent_temperature"`
+			Precipitation []*float64 `json:"precipitation"`
+			WindSpeed     []*float64 `json:"wind_speed_10m"`
+			WindDirection []*float64 `json:"wind_direction_10m"`
+			WindGusts     []*float64 `json:"wind_gusts_10m"`
+			WeatherCode   []*int     `json:"weather_code"`
+		} `json:"hourly"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, nil, fmt.Errorf("decode archive: %w", err)
+	}
+	hr := parsed.Hourly
+	n := len(hr.Time)
+	if len(hr.Temperature) != n || len(hr.ApparentTemp) != n || len(hr.Precipitation) != n ||
+		len(hr.WindSpeed) != n || len(hr.WindDirection) != n || len(hr.WindGusts) != n || len(hr.WeatherCode) != n {
+		return nil, nil, fmt.Errorf("archive returned inconsistent hourly array lengths")
+	}
+	val := func(p *float64) float64 {
+		if p == nil {
+			return 0
+		}
+		return *p
+	}
+
+	zone := openMeteoZone(parsed.Timezone, parsed.UTCOffset)
+	out := make([]archiveHour, 0, n)
+	for i, s := range hr.Time {
+		if hr.Temperature[i] == nil {
+			continue
+		}
+		t, err := time.ParseInLocation("2006-01-02T15:04", s, zone)
+		if err != nil {
+			slog.Debug("archive: skipping unparseable time", "time", s, "err", err)
+			continue
+		}
+		h := HourlyForecast{
+			Time:                t,
+			Temperature:         *hr.Temperature[i],
+			ApparentTemperature: val(hr.ApparentTemp[i]),
+			Precipitation:       val(hr.Precipitation[i]),
+			WindSpeed:           val(hr.WindSpeed[i]),
+			WindDirection:       val(hr.WindDirection[i]),
+			WindGusts:           val(hr.WindGusts[i]),
+		}
+		if hr.WeatherCode[i] != nil {
+			h.WeatherCode = *hr.WeatherCode[i]
+		}
+		out = append(out, archiveHour{HourlyForecast: h, HasPrecip: hr.Precipitation[i] != nil})
+	}
+	return out, zone, nil
+}
+
+// clipHours keeps the hours whose local clock time lies within [from, to],
+// given as minutes after midnight. to < from wraps past midnight.
+func clipHours(hours []HourlyForecast, from, to int) []HourlyForecast {
+	var out []HourlyForecast
+	for _, h := range hours {
+		m := h.Time.Hour()*60 + h.Time.Minute()
+		in := m >= from && m <= to
+		if to < from {
+			in = m >= from || m <= to
+		}
+		if in {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("want HH:MM, got %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
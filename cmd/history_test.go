@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClipHours(t *testing.T) {
+	var hours []HourlyForecast
+	for h := 0; h < 24; h++ {
+		hours = append(hours, HourlyForecast{Time: time.Date(2026, 6, 12, h, 0, 0, 0, time.UTC)})
+	}
+	tests := []struct {
+		name, from, to string
+		wantErr        bool
+		first, n       int
+	}{
+		{"whole day", "", "", false, 0, 24},
+		{"ride", "14:00", "18:00", false, 14, 5},
+		{"half hours round inward", "14:30", "17:59", false, 15, 3},
+		{"overnight wraps", "22:00", "02:00", false, 0, 5},
+		{"not a clock time", "2pm", "", true, 0, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			from, to, err := parseHistoryWindow(tc.from, tc.to)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseHistoryWindow(%q, %q) error = %v, want error %v", tc.from, tc.to, err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			got := clipHours(hours, from, to)
+			if len(got) != tc.n || got[0].Time.Hour() != tc.first {
+				t.Fatalf("clipHours(%s–%s) = %d hours from %v, want %d from %02d:00", tc.from, tc.to, len(got), got[0].Time, tc.n, tc.first)
+			}
+		})
+	}
+}
+
+func TestParseOpenMeteoArchive(t *testing.T) {
+	raw := []byte(`{"timezone":"UTC","hourly":{
+		"time":["2026-06-12T10:00","2026-06-12T11:00","2026-06-12T12:00"],
+		"temperature_2m":[18,19,null],
+		"apparent_temperature":[17,18,null],
+		"precipitation":[0.4,null,null],
+		"wind_speed_10m":[10,10,null],
+		"wind_direction_10m":[270,270,null],
+		"wind_gusts_10m":[20,20,null],
+		"weather_code":[61,3,null]}}`)
+	hours, _, err := parseOpenMeteoArchive(raw)
+	if err != nil {
+		t.Fatalf("parseOpenMeteoArchive() error: %v", err)
+	}
+	if len(hours) != 2 {
+		t.Fatalf("parseOpenMeteoArchive() returned %d hours, want 2 (no temperature: dropped)", len(hours))
+	}
+	tests := []struct {
+		name      string
+		hour      int
+		precip    float64
+		hasPrecip bool
+	}{
+		{"measured", 0, 0.4, true},
+		{"null precipitation", 1, 0, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h := hours[tc.hour]
+			if h.Precipitation != tc.precip || h.HasPrecip != tc.hasPrecip {
+				t.Fatalf("hour %d precip %v (has %v), want %v (has %v)", tc.hour, h.Precipitation, h.HasPrecip, tc.precip, tc.hasPrecip)
+			}
+		})
+	}
+}
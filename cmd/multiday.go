@@ -42,12 +42,35 @@ var (
 	FlagMultidayTopN             int
 	FlagMultidayHeatmap          bool
 	FlagMultidayHeatmapGrid      int
+	FlagMultidayBearings         int
+	FlagMultidayRefine           bool
+	FlagMultidayLegSamples       int
+	FlagMultidayWaterMask        bool
+	FlagMultidayObstacles        string
+	FlagMultidayFerries          bool
+	FlagMultidayClimbPenalty     float64
+	FlagMultidayTo               string
+	FlagMultidayGoalRadius       float64
+	FlagMultidayRestDays         bool
+	FlagMultidayRestPenalty      float64
+	FlagMultidayMinKm            float64
+	FlagMultidayMaxKm            float64
+	FlagMultidayDistanceSteps    int
+	FlagMultidayStops            string
+	FlagMultidayStopTolerance    float64
+	FlagMultidayRisk             float64
 )
 
 const (
-	multidayNumDirections = 8
-	multidayFetchWorkers  = 4
-	tailHeadSwitchKmh     = 5.0
+	multidayDefaultBearings = 8
+	multidayRefineTop       = 3 // distinct best bearings per beam node refined in the second round
+	multidayFetchWorkers    = 4
+	multidayDefaultSamples  = 3    // forecast points per day's leg
+	multidayMaxSamples      = 10   // most --leg-samples accepted
+	modelGridDeg            = 0.02 // ~2 km, the finest model grid Open-Meteo serves over NL
+	tailHeadSwitchKmh       = 5.0
+	multidayGoalPull        = 15.0 // ranking penalty per 100 km still to ride to --to
+	multidayDistanceSteps   = 3
 )
 
 var multidayCmd = &cobra.Command{
@@ -61,14 +84,62 @@ E) to follow dry air or tailwind. Rain during the daytime window (10:00–20:00)
 disqualifies a day.
 
 Use --round-trip to bias the search toward plans that end near the starting
-point (with paired bearings that close the loop).`,
+point (with paired bearings that close the loop).
+
+--bearings sets how many evenly spaced directions each day tries (8, 16 or
+32); --refine adds a second round per day that tries the bearings half a step
+either side of the best ones.
+
+Each day's leg is checked at --leg-samples points along the way, with the
+morning hours taken near the start of the leg and the afternoon near its
+end, so a shower at km 20 or km 90 still counts.
+
+Legs are checked every few km against a bundled coarse water mask of the
+Netherlands (--water-mask=false turns it off) and the polygons of an
+--obstacles GeoJSON file (water, borders, restricted areas). The mask leaves
+the dams and bridges across it open: the Afsluitdijk, the Houtribdijk, the
+Zeelandbrug and the Delta Works dams. With --ferries, a leg may cross water
+along one of the bundled ferry routes or any LineString in the --obstacles
+file.
+
+Terrain is ignored by default. With --climb-penalty N, each leg's climbing
+is estimated from an elevation profile sampled every few km (fetched from
+the Open-Meteo elevation API), and N points per 100 m are taken off the
+day's score — more when the climb comes with a headwind or heat.
+
+--to PLACE plans an A-to-B trip instead: each day also tries the bearing
+straight at PLACE, the search favours plans that close in on it, and only
+plans that finish within --goal-radius km of it are shown. --days is then
+the most days you have; a plan may arrive sooner. --to implies
+--heatmap=false. With --rest-days the search may also sit out a day in place
+(a rainy one, typically), at a cost of --rest-penalty points.
+
+--min-km/--max-km let each day's distance vary: the search tries
+--distance-steps lengths across the band. Tailwind counts for more on a long
+day and headwind hurts more, and each 10 km away from --km-per-day costs half
+a point, so plans ride long with the wind and short against it.
+
+--stops FILE restricts where days may end to a list of overnight stops
+(campsites, hostels, stations) from a CSV (name,lat,lon header) or GeoJSON
+file of Points: each day's endpoint moves to the nearest stop within
+--stop-tolerance km, legs with none in reach are dropped, and the plan shows
+the stops by name.
+
+--risk N scores each day on the Nth percentile of the ECMWF ensemble instead
+of the single deterministic run: rain, wind and gusts at the Nth percentile,
+temperature at the (100−N)th. --risk 50 plans on the expected day; --risk 90
+is risk-averse — a day only counts as dry when 90% of the members are. A
+failed ensemble fetch falls back to the deterministic run.`,
 	RunE: runMultiday,
 }
 
 func init() {
 	rootCmd.AddCommand(multidayCmd)
 	multidayCmd.Flags().IntVar(&FlagMultidayDays, "days", 5, "trip length in days")
-	multidayCmd.Flags().Float64Var(&FlagMultidayKmPerDay, "km-per-day", 100, "daily distance in km")
+	multidayCmd.Flags().Float64Var(&FlagMultidayKmPerDay, "km-per-day", 100, "daily distance in km (the preferred one with --min-km/--max-km)")
+	multidayCmd.Flags().Float64Var(&FlagMultidayMinKm, "min-km", 0, "shortest riding day in km (with --max-km)")
+	multidayCmd.Flags().Float64Var(&FlagMultidayMaxKm, "max-km", 0, "longest riding day in km (with --min-km)")
+	multidayCmd.Flags().IntVar(&FlagMultidayDistanceSteps, "distance-steps", multidayDistanceSteps, "daily distances tried between --min-km and --max-km")
 	multidayCmd.Flags().Float64Var(&FlagMultidayMinTemp, "min-temp", 15, "preferred minimum daytime max temperature (°C)")
 	multidayCmd.Flags().StringVar(&FlagMultidayStartDate, "start-date", "", "trip start date YYYY-MM-DD (default: today)")
 	multidayCmd.Flags().IntVar(&FlagMultidayBeamWidth, "beam-width", 16, "beam search width (higher = slower, more options)")
@@ -78,6 +149,20 @@ func init() {
 	multidayCmd.Flags().IntVar(&FlagMultidayTopN, "top", 3, "how many trip plans to print")
 	multidayCmd.Flags().BoolVar(&FlagMultidayHeatmap, "heatmap", true, "render a spatial weather heatmap you trace your own route across (use --heatmap=false for ranked trip plans)")
 	multidayCmd.Flags().IntVar(&FlagMultidayHeatmapGrid, "heatmap-grid", 21, "heatmap resolution (NxN, odd number so start sits on a cell)")
+	multidayCmd.Flags().IntVar(&FlagMultidayBearings, "bearings", multidayDefaultBearings, "candidate bearings per day: 8, 16 or 32")
+	multidayCmd.Flags().BoolVar(&FlagMultidayRefine, "refine", false, "second search round per day around the best-scoring bearings")
+	multidayCmd.Flags().BoolVar(&FlagMultidayWaterMask, "water-mask", true, "avoid legs across the bundled coarse Dutch water mask")
+	multidayCmd.Flags().StringVar(&FlagMultidayObstacles, "obstacles", "", "GeoJSON file of no-go polygons (LineStrings are ferry routes)")
+	multidayCmd.Flags().BoolVar(&FlagMultidayFerries, "ferries", false, "allow legs to cross water on ferry routes")
+	multidayCmd.Flags().Float64Var(&FlagMultidayClimbPenalty, "climb-penalty", 0, "score penalty per 100 m of climbing; > 0 fetches elevation profiles (0 = ignore terrain)")
+	multidayCmd.Flags().StringVar(&FlagMultidayTo, "to", "", "destination place name; plans an A-to-B trip")
+	multidayCmd.Flags().Float64Var(&FlagMultidayGoalRadius, "goal-radius", 15, "finish within this many km of --to")
+	multidayCmd.Flags().BoolVar(&FlagMultidayRestDays, "rest-days", false, "allow rest days that stay in place")
+	multidayCmd.Flags().Float64Var(&FlagMultidayRestPenalty, "rest-penalty", 5, "score cost of a rest day (with --rest-days)")
+	multidayCmd.Flags().StringVar(&FlagMultidayStops, "stops", "", "CSV or GeoJSON file of overnight stops each day must end at")
+	multidayCmd.Flags().Float64Var(&FlagMultidayStopTolerance, "stop-tolerance", 15, "how far (km) a day's endpoint may move to reach a stop")
+	multidayCmd.Flags().Float64Var(&FlagMultidayRisk, "risk", 0, "score days on this ensemble percentile (50 = expected, 90 = risk-averse; 0 = deterministic run)")
+	multidayCmd.Flags().IntVar(&FlagMultidayLegSamples, "leg-samples", multidayDefaultSamples, "forecast points sampled along each day's leg, 1–10 (1 = midpoint only)")
 }
 
 func runMultiday(cmd *cobra.Command, args []string) error {
@@ -92,6 +177,32 @@ func runMultiday(cmd *cobra.Command, args []string) error {
 	if FlagMultidayBeamWidth <= 0 {
 		return fmt.Errorf("--beam-width must be positive")
 	}
+	if !validBearingCount(FlagMultidayBearings) {
+		return fmt.Errorf("--bearings must be 8, 16 or 32")
+	}
+	if FlagMultidayMinKm != 0 || FlagMultidayMaxKm != 0 {
+		if FlagMultidayMinKm <= 0 || FlagMultidayMaxKm < FlagMultidayMinKm {
+			return fmt.Errorf("--min-km and --max-km must both be set, with 0 < min ≤ max")
+		}
+		if FlagMultidayDistanceSteps < 2 {
+			return fmt.Errorf("--distance-steps must be at least 2")
+		}
+	}
+	if FlagMultidayClimbPenalty < 0 {
+		return fmt.Errorf("--climb-penalty must not be negative")
+	}
+	if FlagMultidayRisk < 0 || FlagMultidayRisk >= 100 {
+		return fmt.Errorf("--risk must be between 0 and 99")
+	}
+	if FlagMultidayLegSamples < 1 || FlagMultidayLegSamples > multidayMaxSamples {
+		return fmt.Errorf("--leg-samples must be between 1 and %d", multidayMaxSamples)
+	}
+	if FlagMultidayTo != "" && FlagMultidayRoundTrip {
+		return fmt.Errorf("--to and --round-trip are mutually exclusive")
+	}
+	if FlagMultidayTo != "" && !cmd.Flags().Changed("heatmap") {
+		FlagMultidayHeatmap = false
+	}
 	if FlagMultidayTopN <= 0 {
 		FlagMultidayTopN = 1
 	}
@@ -111,6 +222,28 @@ func runMultiday(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	obstacles, err := loadObstacles(FlagMultidayWaterMask, FlagMultidayObstacles, FlagMultidayFerries)
+	if err != nil {
+		return err
+	}
+
+	var stops []overnightStop
+	if FlagMultidayStops != "" {
+		stops, err = loadStops(FlagMultidayStops)
+		if err != nil {
+			return err
+		}
+	}
+
+	var goal *Location
+	if FlagMultidayTo != "" {
+		g, err := GetLocationFromString(FlagMultidayTo)
+		if err != nil {
+			return fmt.Errorf("resolve --to %q: %w", FlagMultidayTo, err)
+		}
+		goal = &g
+	}
+
 	cfg := beamConfig{
 		KmPerDay:         FlagMultidayKmPerDay,
 		MinTemp:          FlagMultidayMinTemp,
@@ -118,16 +251,43 @@ func runMultiday(cmd *cobra.Command, args []string) error {
 		PivotPenalty:     FlagMultidayPivotPenalty,
 		RoundTrip:        FlagMultidayRoundTrip,
 		RoundTripPenalty: FlagMultidayRoundTripPenalty,
+		Bearings:         FlagMultidayBearings,
+		Refine:           FlagMultidayRefine,
+		LegSamples:       FlagMultidayLegSamples,
+		Obstacles:        obstacles,
+		ClimbPenalty:     FlagMultidayClimbPenalty,
+		RestDays:         FlagMultidayRestDays,
+		RestPenalty:      FlagMultidayRestPenalty,
+		MinKm:            FlagMultidayMinKm,
+		MaxKm:            FlagMultidayMaxKm,
+		DistanceSteps:    FlagMultidayDistanceSteps,
+		Stops:            stops,
+		StopToleranceKm:  FlagMultidayStopTolerance,
+		Risk:             FlagMultidayRisk,
+	}
+	if goal != nil {
+		cfg.Goal = &latLon{goal.Latitude, goal.Longitude}
+		cfg.GoalRadiusKm = FlagMultidayGoalRadius
+		cfg.GoalPull = multidayGoalPull
 	}
 
 	fmt.Printf(termplt.ColorBold+"Multi-day from %s"+termplt.ColorReset+
-		"  ·  %d days × %.0f km/day  ·  %s → %s",
-		loc.Description, FlagMultidayDays, FlagMultidayKmPerDay,
+		"  ·  %d days × %s km/day  ·  %s → %s",
+		loc.Description, FlagMultidayDays, dayDistanceLabel(cfg),
 		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"),
 	)
 	if FlagMultidayRoundTrip {
 		fmt.Print("  ·  round-trip")
 	}
+	if goal != nil {
+		fmt.Printf("  ·  to %s", goal.Description)
+	}
+	if len(stops) > 0 {
+		fmt.Printf("  ·  %d stops", len(stops))
+	}
+	if cfg.Risk > 0 {
+		fmt.Printf("  ·  ensemble p%.0f", cfg.Risk)
+	}
 	if FlagMultidayHeatmap {
 		fmt.Print("  ·  heatmap")
 	}
@@ -146,6 +306,10 @@ func runMultiday(cmd *cobra.Command, args []string) error {
 	trips := RunBeamSearch(loc.Latitude, loc.Longitude, startDate, FlagMultidayDays, cfg, beamProg)
 	beamProg.Finish()
 	if len(trips) == 0 {
+		if goal != nil {
+			fmt.Printf(termplt.ColorRed+"No viable trip reaches %s within %d days — try more --days, --rest-days or a larger --goal-radius."+termplt.ColorReset+"\n", goal.Description, FlagMultidayDays)
+			return nil
+		}
 		fmt.Println(termplt.ColorRed + "No viable trip found — every bearing hit rain or severe gusts on at least one day." + termplt.ColorReset)
 		return nil
 	}
@@ -175,9 +339,10 @@ func runMultiday(cmd *cobra.Command, args []string) error {
 // ---------- labels (reverse geocode) ----------
 
 // annotateTripLabels reverse-geocodes the endpoint of each day for every trip,
-// deduping by rounded lat/lon so overlapping trips share calls. Returns a
-// slice of label-lists parallel to the trips slice (labels[i][d] = locality
-// at the end of day d+1 of trip i).
+// deduping by rounded lat/lon so overlapping trips share calls. Days that end
+// at an overnight stop use the stop's name instead. Returns a slice of
+// label-lists parallel to the trips slice (labels[i][d] = locality at the end
+// of day d+1 of trip i).
 func annotateTripLabels(trips []beamNode, prog Progress) [][]string {
 	type geoKey struct{ LatR, LonR int }
 	keyFor := func(lat, lon float64) geoKey {
@@ -191,7 +356,10 @@ func annotateTripLabels(trips []beamNode, prog Progress) [][]string {
 
 	pending := map[geoKey]geoTask{}
 	for _, t := range trips {
-		for _, p := range t.Positions[1:] {
+		for d, p := range t.Positions[1:] {
+			if tripStop(t, d) != "" {
+				continue
+			}
 			k := keyFor(p.Lat, p.Lon)
 			if _, seen := pending[k]; !seen {
 				pending[k] = geoTask{Lat: p.Lat, Lon: p.Lon, Key: k}
@@ -228,21 +396,46 @@ func annotateTripLabels(trips []beamNode, prog Progress) [][]string {
 	out := make([][]string, len(trips))
 	for i, t := range trips {
 		out[i] = make([]string, 0, len(t.Bearings))
-		for _, p := range t.Positions[1:] {
+		for d, p := range t.Positions[1:] {
+			if stop := tripStop(t, d); stop != "" {
+				out[i] = append(out[i], stop)
+				continue
+			}
 			out[i] = append(out[i], resolved[keyFor(p.Lat, p.Lon)])
 		}
 	}
 	return out
 }
 
+// tripStop is the overnight stop trip ends day d+1 at, or "".
+func tripStop(t beamNode, d int) string {
+	if d < len(t.Stops) {
+		return t.Stops[d]
+	}
+	return ""
+}
+
+// tripDayBearing is the bearing trip actually rides on day d+1. For a day
+// snapped to an overnight stop that is the bearing from the previous
+// endpoint to the stop, not the heading the search tried.
+func tripDayBearing(t beamNode, d int) float64 {
+	if tripStop(t, d) == "" {
+		return t.Bearings[d]
+	}
+	from, to := t.Positions[d], t.Positions[d+1]
+	return InitialBearing(from.Lat, from.Lon, to.Lat, to.Lon)
+}
+
 // ---------- rendering ----------
 
 const (
-	cellWidth    = 9
-	dayColWidth  = 5 // "Day5 "
-	dirColWidth  = 4 // "NE ↗"
-	tempColWidth = 5 // "21°"
-	windColWidth = 5 // "T20"
+	cellWidth     = 9
+	dayColWidth   = 5 // "Day5 "
+	dirColWidth   = 5 // "NNE ↗"
+	kmColWidth    = 5 // "140km"
+	tempColWidth  = 5 // "21°"
+	windColWidth  = 5 // "T20"
+	climbColWidth = 7 // "↑1250m"
 )
 
 func renderLegend() {
@@ -251,7 +444,7 @@ func renderLegend() {
 	y := termplt.ColorYellow
 	rst := termplt.ColorReset
 	fmt.Println(termplt.ColorBold + "Legend:" + rst)
-	fmt.Printf("  Each day row:  %sS  ↘%s  ~Eindhoven   %s19°%s  %sT8%s    — bearing, endpoint, daytime max temp, tail/head wind\n",
+	fmt.Printf("  Each day row:  %sSSE ↘%s  100km  ~Eindhoven   %s19°%s  %sT8%s    ↑120m  — bearing, distance, endpoint, daytime max temp, tail/head wind, climbing\n",
 		"", "", g, rst, g, rst)
 	fmt.Printf("  %sT<n>%s tailwind km/h (good)    %sH<n>%s headwind km/h (bad)    ·  mostly crosswind (<%.0f km/h along route)\n",
 		g, rst, r, rst, tailHeadSwitchKmh)
@@ -265,30 +458,33 @@ func renderTrip(rank int, trip beamNode, labels []string, startLat, startLon flo
 	endLat, endLon := trip.Positions[len(trip.Positions)-1].Lat, trip.Positions[len(trip.Positions)-1].Lon
 	endDist := HaversineKm(endLat, endLon, startLat, startLon)
 
-	path := bearingPath(trip.Bearings)
+	path := tripPath(trip)
 	endLabel := "?"
 	if len(labels) > 0 {
 		endLabel = labels[len(labels)-1]
 	}
 
-	header := fmt.Sprintf("%sTrip %d%s   score %.0f   %s",
-		termplt.ColorBold, rank, termplt.ColorReset, trip.Score, path,
+	header := fmt.Sprintf("%sTrip %d%s   score %.0f   %s   %.0f km",
+		termplt.ColorBold, rank, termplt.ColorReset, trip.Score, path, tripDistanceKm(trip),
 	)
-	if cfg.RoundTrip {
+	switch {
+	case cfg.RoundTrip:
 		header += fmt.Sprintf("   (ends %.0f km from start)", endDist)
-	} else {
+	case cfg.Goal != nil:
+		header += fmt.Sprintf("   (arrives ~%s after %d day%s)", endLabel, len(trip.Bearings), pluralS(len(trip.Bearings)))
+	default:
 		header += fmt.Sprintf("   (ends ~%s, %.0f km away)", endLabel, endDist)
 	}
 	fmt.Println(header)
 
-	pivots := countPivots(trip.Bearings)
-	for i, b := range trip.Bearings {
+	pivots := countPivots(trip)
+	for i := range trip.Bearings {
 		ds := trip.DailyScores[i]
 		label := ""
 		if i < len(labels) {
 			label = labels[i]
 		}
-		renderDayRow(i+1, b, label, ds)
+		renderDayRow(i+1, tripDayBearing(trip, i), label, ds)
 	}
 	if pivots > 0 {
 		fmt.Printf("  %s%d pivot%s%s\n", termplt.ColorPurple, pivots, pluralS(pivots), termplt.ColorReset)
@@ -297,8 +493,14 @@ func renderTrip(rank int, trip beamNode, labels []string, startLat, startLon flo
 
 func renderDayRow(day int, bearingDeg float64, endLabel string, ds DayScore) {
 	dayCol := padRight(fmt.Sprintf("Day%d", day), dayColWidth)
-	dir := fmt.Sprintf("%-2s %s", CompassName(bearingDeg), CompassArrow(bearingDeg))
+	if ds.Rest {
+		rest := termplt.ColorPurple + padRight("rest", dirColWidth) + termplt.ColorReset
+		fmt.Printf("  %s  %s  %s  %s\n", dayCol, rest, padRight("", kmColWidth), "~"+endLabel)
+		return
+	}
+	dir := fmt.Sprintf("%-3s %s", CompassName(bearingDeg), CompassArrow(bearingDeg))
 	dirCol := padRight(dir, dirColWidth)
+	kmCol := padRight(fmt.Sprintf("%.0fkm", ds.DistanceKm), kmColWidth)
 	endCol := padRight("~"+endLabel, 22)
 
 	tempCol := padRight(fmt.Sprintf("%.0f°", ds.MaxTemp), tempColWidth)
@@ -326,7 +528,13 @@ func renderDayRow(day int, bearingDeg float64, endLabel string, ds DayScore) {
 	windColored := windColor + windLabel + termplt.ColorReset
 	windCol := padRight(windColored, windColWidth)
 
-	fmt.Printf("  %s  %s  %s  %s  %s\n", dayCol, dirCol, endCol, tempColored, windCol)
+	climbCol := ""
+	if ds.ClimbM >= 1 {
+		climbCol = fmt.Sprintf("↑%.0fm", ds.ClimbM)
+	}
+	climbCol = padRight(climbCol, climbColWidth)
+
+	fmt.Printf("  %s  %s  %s  %s  %s  %s  %s\n", dayCol, dirCol, kmCol, endCol, tempColored, windCol, climbCol)
 }
 
 // renderRecommendation prints one or two sentences pointing at the winner and
@@ -345,8 +553,11 @@ func renderRecommendation(trips []beamNode, labelsByTrip [][]string, cfg beamCon
 
 	minT, maxT := math.MaxFloat64, -math.MaxFloat64
 	twSum, twCount := 0.0, 0
-	pivots := countPivots(winner.Bearings)
+	pivots := countPivots(winner)
 	for _, ds := range winner.DailyScores {
+		if ds.Rest {
+			continue
+		}
 		if ds.MaxTemp > maxT {
 			maxT = ds.MaxTemp
 		}
@@ -356,10 +567,11 @@ func renderRecommendation(trips []beamNode, labelsByTrip [][]string, cfg beamCon
 		twSum += ds.TailwindAvg
 		twCount++
 	}
-	twAvg := 0.0
-	if twCount > 0 {
-		twAvg = twSum / float64(twCount)
+	if twCount == 0 {
+		fmt.Printf("%sRecommendation:%s stay at %s; no day is rideable.\n", termplt.ColorBold, termplt.ColorReset, endLabel)
+		return
 	}
+	twAvg := twSum / float64(twCount)
 
 	shape := "a straight bearing"
 	if pivots == 1 {
@@ -380,7 +592,7 @@ func renderRecommendation(trips []beamNode, labelsByTrip [][]string, cfg beamCon
 
 	fmt.Printf("%sRecommendation:%s Trip 1 — %s with %s, %.0f–%.0f°C, %s. Bearings: %s.\n",
 		termplt.ColorBold, termplt.ColorReset,
-		endLabel, shape, minT, maxT, wind, bearingPath(winner.Bearings),
+		endLabel, shape, minT, maxT, wind, tripPath(winner),
 	)
 	if cfg.RoundTrip {
 		endLat, endLon := winner.Positions[len(winner.Positions)-1].Lat, winner.Positions[len(winner.Positions)-1].Lon
@@ -394,21 +606,57 @@ func renderRecommendation(trips []beamNode, labelsByTrip [][]string, cfg beamCon
 
 // ---------- small helpers ----------
 
-// bearingPath renders a sequence of bearings as "S → S → SE → E → E".
-func bearingPath(bearings []float64) string {
-	parts := make([]string, 0, len(bearings))
-	for _, b := range bearings {
-		parts = append(parts, CompassName(b))
+// dayDistanceLabel renders the daily distance for headers: "100" or
+// "60–140" with a distance band.
+func dayDistanceLabel(cfg beamConfig) string {
+	lengths := cfg.dayLengths()
+	if len(lengths) == 1 {
+		return fmt.Sprintf("%.0f", lengths[0])
+	}
+	return fmt.Sprintf("%.0f–%.0f", lengths[0], lengths[len(lengths)-1])
+}
+
+// tripDistanceKm sums the riding distance over all days.
+func tripDistanceKm(trip beamNode) float64 {
+	total := 0.0
+	for _, ds := range trip.DailyScores {
+		total += ds.DistanceKm
+	}
+	return total
+}
+
+// tripPath renders a trip's days as "S → SSE → rest → E → E".
+func tripPath(trip beamNode) string {
+	parts := make([]string, 0, len(trip.Bearings))
+	for i := range trip.Bearings {
+		if trip.DailyScores[i].Rest {
+			parts = append(parts, "rest")
+			continue
+		}
+		parts = append(parts, CompassName(tripDayBearing(trip, i)))
 	}
 	return strings.Join(parts, " → ")
 }
 
-func countPivots(bearings []float64) int {
+// validBearingCount reports whether n is a supported --bearings value.
+func validBearingCount(n int) bool {
+	return n == 8 || n == 16 || n == 32
+}
+
+// countPivots counts bearing changes between consecutive riding days; rest
+// days don't break a straight run.
+func countPivots(trip beamNode) int {
 	pivots := 0
-	for i := 1; i < len(bearings); i++ {
-		if bearings[i] != bearings[i-1] {
+	var prev float64
+	seen := false
+	for i, b := range trip.Bearings {
+		if trip.DailyScores[i].Rest {
+			continue
+		}
+		if seen && b != prev {
 			pivots++
 		}
+		prev, seen = b, true
 	}
 	return pivots
 }
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"log/slog"
+	"math"
 	"sort"
 	"sync"
 	"time"
@@ -12,6 +13,7 @@ import (
 type beamNode struct {
 	Bearings    []float64  `json:"bearings"`    // day-by-day compass bearing (len == depth so far)
 	Positions   []latLon   `json:"positions"`   // cumulative positions — Positions[0] is start, len == depth+1
+	Stops       []string   `json:"stops"`       // overnight stop name per day, "" where not snapped to one
 	DailyScores []DayScore `json:"dailyScores"` // per-day scored details for rendering
 	Score       float64    `json:"score"`       // cumulative score after all penalties
 }
@@ -28,18 +30,84 @@ type beamConfig struct {
 	BeamWidth        int
 	PivotPenalty     float64 // subtracted per bearing change
 	RoundTrip        bool
-	RoundTripPenalty float64 // subtracted per km from start at trip end (only when RoundTrip)
+	RoundTripPenalty float64         // subtracted per km from start at trip end (only when RoundTrip)
+	Bearings         int             // evenly spaced candidate bearings per day (8, 16 or 32)
+	Refine           bool            // second pass around the best bearings at half the step
+	LegSamples       int             // forecast points along each day's leg (1 = midpoint only)
+	Obstacles        *obstacleSet    // no-go areas checked along each leg; nil = none
+	ClimbPenalty     float64         // score cost per 100 m of climbing; 0 skips elevation lookups
+	Goal             *latLon         // fixed destination; nil = free drift / round-trip
+	GoalRadiusKm     float64         // plans must end within this distance of Goal
+	GoalPull         float64         // ranking penalty per 100 km still to go (only with Goal)
+	RestDays         bool            // allow staying in place for a day
+	RestPenalty      float64         // score cost of a rest day
+	MinKm, MaxKm     float64         // daily distance band; both 0 = always KmPerDay
+	DistanceSteps    int             // distances tried across [MinKm, MaxKm]
+	Stops            []overnightStop // acceptable overnight stops; empty = end anywhere
+	StopToleranceKm  float64         // how far a day's endpoint may move to reach a stop
+	Risk             float64         // ensemble percentile to score on (50 = expected, 90 = risk-averse); 0 = deterministic run
 }
 
-// hourlyCache dedupes Open-Meteo fetches. Two paths that arrive at the same
-// midpoint on the same day share one HTTP call.
+// bearingCount returns the configured number of candidate bearings, falling
+// back to the classic 8-point compass when unset.
+func (c beamConfig) bearingCount() int {
+	if c.Bearings <= 0 {
+		return multidayDefaultBearings
+	}
+	return c.Bearings
+}
+
+// dayLengths returns the daily distances the search tries: DistanceSteps
+// evenly spaced values across [MinKm, MaxKm], just MinKm when the band is a
+// single distance, or KmPerDay when no band is set.
+func (c beamConfig) dayLengths() []float64 {
+	if c.MaxKm < c.MinKm || c.MinKm <= 0 || c.DistanceSteps < 2 {
+		return []float64{c.KmPerDay}
+	}
+	if c.MaxKm == c.MinKm {
+		return []float64{c.MinKm}
+	}
+	out := make([]float64, c.DistanceSteps)
+	step := (c.MaxKm - c.MinKm) / float64(c.DistanceSteps-1)
+	for i := range out {
+		out[i] = c.MinKm + float64(i)*step
+	}
+	return out
+}
+
+// maxDayKm is the longest day the search may ride.
+func (c beamConfig) maxDayKm() float64 {
+	lengths := c.dayLengths()
+	return lengths[len(lengths)-1]
+}
+
+// legSampleCount returns the configured number of samples per leg, clamped
+// to 1..multidayMaxSamples.
+func (c beamConfig) legSampleCount() int {
+	return min(max(c.LegSamples, 1), multidayMaxSamples)
+}
+
+// hourlyCache dedupes Open-Meteo fetches. Two paths whose leg samples snap to
+// the same grid point on the same day share one HTTP call.
+// With risk > 0 each point's hours are the ensemble scenario at that
+// percentile instead of the deterministic run (see withEnsembleScenario).
 type hourlyCache struct {
-	mu   sync.Mutex
-	data map[string]*OpenMeteoData
+	mu    sync.Mutex
+	data  map[string]*OpenMeteoData
+	risk  float64
+	fetch func(lat, lon float64, date time.Time) (*OpenMeteoData, error) // one point's day; tests swap it out
 }
 
-func newHourlyCache() *hourlyCache {
-	return &hourlyCache{data: make(map[string]*OpenMeteoData)}
+func newHourlyCache(risk float64) *hourlyCache {
+	c := &hourlyCache{data: make(map[string]*OpenMeteoData), risk: risk}
+	c.fetch = func(lat, lon float64, date time.Time) (*OpenMeteoData, error) {
+		data, err := GetOpenMeteoRange(lat, lon, date, date)
+		if err != nil {
+			return nil, err
+		}
+		return withEnsembleScenario(data, lat, lon, date, date, c.risk), nil
+	}
+	return c
 }
 
 func hourlyCacheKey(lat, lon float64, date time.Time) string {
@@ -71,7 +139,7 @@ func (c *hourlyCache) prefetch(points []fetchPoint, prog Progress) {
 			defer wg.Done()
 			defer func() { <-sem }()
 			defer prog.Inc(1)
-			data, err := GetOpenMeteoRange(p.Lat, p.Lon, p.Date, p.Date)
+			data, err := c.fetch(p.Lat, p.Lon, p.Date)
 			c.mu.Lock()
 			defer c.mu.Unlock()
 			if err != nil {
@@ -102,84 +170,70 @@ type fetchPoint struct {
 // BeamWidth surviving paths at each depth. Returns all surviving final-day
 // paths, sorted by score descending. An empty result means every candidate
 // was disqualified (e.g. rain in every direction on some day).
+//
+// Legs that cross cfg.Obstacles anywhere along their path (other than on a
+// ferry, dam or bridge) are dropped before any forecast is fetched for them.
+//
+// Each leg is scored from cfg.LegSamples points along its path, with the
+// hours of the day matched to where the rider should be by then (see
+// legHourly).
+//
+// With cfg.Refine, each day runs a second round over the bearings half a step
+// either side of each node's own best-scoring first-round bearings. It only
+// rides those bearings — rest days and goal legs came with the first round —
+// and goes through the same hourlyCache, so a refined sample that rounds
+// onto an already-fetched grid point costs no extra Open-Meteo call. Routes
+// that come out of both rounds (say, two legs snapped to one stop) are kept
+// once, at their best score.
+//
+// With cfg.Goal set, every node also tries the bearing straight at the goal
+// (shortened to land on it when it is less than a day away), nodes that can
+// no longer reach the goal in the remaining days are dropped, the beam is
+// ranked by score minus the distance still to go, and only plans that end
+// within cfg.GoalRadiusKm are returned. A node that has arrived early stays
+// in the beam unchanged, so the number of riding days is flexible.
+//
+// With cfg.Risk set, days are scored on an ensemble percentile rather than
+// the single deterministic run, so a plan only counts a day as dry when
+// enough of the members agree.
 func RunBeamSearch(startLat, startLon float64, startDate time.Time, days int, cfg beamConfig, prog Progress) []beamNode {
-	cache := newHourlyCache()
+	return runBeamSearch(startLat, startLon, startDate, days, cfg, newHourlyCache(cfg.Risk), prog)
+}
+
+func runBeamSearch(startLat, startLon float64, startDate time.Time, days int, cfg beamConfig, cache *hourlyCache, prog Progress) []beamNode {
 	start := latLon{startLat, startLon}
 	beam := []beamNode{{
 		Positions: []latLon{start},
 	}}
 
+	step := 360.0 / float64(cfg.bearingCount())
+	bearings := make([]float64, cfg.bearingCount())
+	for i := range bearings {
+		bearings[i] = float64(i) * step
+	}
+
 	for day := 0; day < days; day++ {
 		date := startDate.AddDate(0, 0, day)
+		last := day == days-1
 
-		// Phase 1: collect unique fetch points (midpoints of this day's legs).
-		uniq := map[string]fetchPoint{}
-		for _, node := range beam {
-			cur := node.Positions[len(node.Positions)-1]
-			for i := 0; i < multidayNumDirections; i++ {
-				bearing := float64(i) * (360.0 / multidayNumDirections)
-				midLat, midLon := DestinationPoint(cur.Lat, cur.Lon, bearing, cfg.KmPerDay/2)
-				key := hourlyCacheKey(midLat, midLon, date)
-				uniq[key] = fetchPoint{Lat: midLat, Lon: midLon, Date: date}
-			}
+		perNode := make([][]float64, len(beam))
+		for i := range perNode {
+			perNode[i] = bearings
 		}
-		points := make([]fetchPoint, 0, len(uniq))
-		for _, p := range uniq {
-			points = append(points, p)
-		}
-		slog.Debug("multiday: day pass", "day", day+1, "uniqueFetches", len(points), "beamNodes", len(beam))
-		cache.prefetch(points, prog)
-
-		// Phase 2: expand each beam node with 8 bearings; score the resulting leg.
-		candidates := make([]beamNode, 0, len(beam)*multidayNumDirections)
-		for _, node := range beam {
-			cur := node.Positions[len(node.Positions)-1]
-			for i := 0; i < multidayNumDirections; i++ {
-				bearing := float64(i) * (360.0 / multidayNumDirections)
-				midLat, midLon := DestinationPoint(cur.Lat, cur.Lon, bearing, cfg.KmPerDay/2)
-				data, ok := cache.get(midLat, midLon, date)
-				if !ok {
-					continue
-				}
-				// Skip bearings whose day-midpoint lands on water — you can't
-				// cycle across the North Sea or IJsselmeer.
-				if data.IsSea() {
-					continue
-				}
-				ds := ScoreDay(data.Hourly, bearing, cfg.MinTemp)
-				if ds.Disqualified {
-					continue
-				}
-
-				endLat, endLon := DestinationPoint(cur.Lat, cur.Lon, bearing, cfg.KmPerDay)
-				pivot := 0.0
-				if len(node.Bearings) > 0 && node.Bearings[len(node.Bearings)-1] != bearing {
-					pivot = cfg.PivotPenalty
-				}
-				newScore := node.Score + ds.Score - pivot
-
-				// Round-trip penalty is only applied on the last day — that's
-				// when "how far from home do we finish" actually matters.
-				if cfg.RoundTrip && day == days-1 {
-					distKm := HaversineKm(endLat, endLon, startLat, startLon)
-					newScore -= distKm * cfg.RoundTripPenalty / 100
-				}
-
-				newBearings := append(append([]float64{}, node.Bearings...), bearing)
-				newPositions := append(append([]latLon{}, node.Positions...), latLon{endLat, endLon})
-				newDaily := append(append([]DayScore{}, node.DailyScores...), ds)
-				candidates = append(candidates, beamNode{
-					Bearings:    newBearings,
-					Positions:   newPositions,
-					DailyScores: newDaily,
-					Score:       newScore,
-				})
-			}
+		candidates := expandDay(beam, perNode, date, last, false, start, cfg, cache, prog)
+		if cfg.Refine {
+			refined := refineBearings(beam, candidates, step)
+			slog.Debug("multiday: refine pass", "day", day+1, "beamNodes", len(beam))
+			candidates = append(candidates, expandDay(beam, refined, date, last, true, start, cfg, cache, prog)...)
+			candidates = dedupeRoutes(candidates)
+		}
+		if cfg.Goal != nil {
+			candidates = goalFeasible(candidates, beam, days-day-1, cfg)
 		}
 
-		// Phase 3: prune to top BeamWidth by score.
+		// Prune to top BeamWidth by score (plus goal heuristic).
 		sort.Slice(candidates, func(i, j int) bool {
-			return candidates[i].Score > candidates[j].Score
+			return beamRank(candidates[i], cfg) > beamRank(candidates[j], cfg)
 		})
 		if len(candidates) > cfg.BeamWidth {
 			candidates = candidates[:cfg.BeamWidth]
@@ -190,5 +244,344 @@ func RunBeamSearch(startLat, startLon float64, startDate time.Time, days int, cf
 		}
 	}
 
+	if cfg.Goal != nil {
+		sort.Slice(beam, func(i, j int) bool {
+			return beam[i].Score > beam[j].Score
+		})
+	}
 	return beam
 }
+
+// goalDistKm is how far the node's last position is from the goal.
+func goalDistKm(n beamNode, cfg beamConfig) float64 {
+	end := n.Positions[len(n.Positions)-1]
+	return HaversineKm(end.Lat, end.Lon, cfg.Goal.Lat, cfg.Goal.Lon)
+}
+
+// beamRank orders candidates for pruning: the score so far, less
+// cfg.GoalPull per 100 km still to ride when heading for a goal.
+func beamRank(n beamNode, cfg beamConfig) float64 {
+	if cfg.Goal == nil {
+		return n.Score
+	}
+	return n.Score - goalDistKm(n, cfg)*cfg.GoalPull/100
+}
+
+// goalFeasible drops candidates that can't reach the goal radius in
+// daysLeft more days and, on the last day, those that didn't make it. Nodes
+// of prev that already arrived are carried over as finished plans.
+func goalFeasible(candidates, prev []beamNode, daysLeft int, cfg beamConfig) []beamNode {
+	reach := float64(daysLeft)*cfg.maxDayKm() + cfg.GoalRadiusKm
+	out := candidates[:0]
+	for _, c := range candidates {
+		if goalDistKm(c, cfg) <= reach {
+			out = append(out, c)
+		}
+	}
+	for _, n := range prev {
+		if len(n.Bearings) > 0 && goalDistKm(n, cfg) <= cfg.GoalRadiusKm {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// lastRideBearing returns the bearing of the node's most recent riding day,
+// skipping rest days.
+func lastRideBearing(n beamNode) (float64, bool) {
+	for i := len(n.DailyScores) - 1; i >= 0; i-- {
+		if !n.DailyScores[i].Rest {
+			return n.Bearings[i], true
+		}
+	}
+	return 0, false
+}
+
+// beamLeg is one candidate day's ride from a node. Bearing and Km describe
+// the path actually ridden to End; Heading is the search bearing it came
+// from, which is what the plan records (and pivots compare). They differ only
+// when the endpoint was snapped to an overnight stop.
+type beamLeg struct {
+	Bearing, Km float64
+	Heading     float64
+	End         latLon
+	Stop        string // overnight stop name, "" when not snapped
+}
+
+// nodeLegs lists the legs tried from node: every day length along each
+// bearing and, when heading for a goal, the same along the direct bearing
+// to it — capped to end on the goal when it's closer than that. With
+// cfg.Stops every endpoint is snapped to a stop (see snapLegs). refine
+// leaves out the goal legs, which the first round has tried already.
+func nodeLegs(node beamNode, bearings []float64, refine bool, cfg beamConfig) []beamLeg {
+	cur := node.Positions[len(node.Positions)-1]
+	lengths := cfg.dayLengths()
+	legs := make([]beamLeg, 0, (len(bearings)+1)*len(lengths))
+	add := func(b, km float64) {
+		lat, lon := DestinationPoint(cur.Lat, cur.Lon, b, km)
+		legs = append(legs, beamLeg{Bearing: b, Km: km, Heading: b, End: latLon{lat, lon}})
+	}
+	for _, b := range bearings {
+		for _, km := range lengths {
+			add(b, km)
+		}
+	}
+	if cfg.Goal != nil && !refine {
+		dist := HaversineKm(cur.Lat, cur.Lon, cfg.Goal.Lat, cfg.Goal.Lon)
+		if dist > cfg.GoalRadiusKm {
+			b := InitialBearing(cur.Lat, cur.Lon, cfg.Goal.Lat, cfg.Goal.Lon)
+			for _, km := range lengths {
+				add(b, math.Min(dist, km))
+				if km >= dist {
+					break
+				}
+			}
+		}
+	}
+	if len(cfg.Stops) > 0 {
+		legs = snapLegs(cur, legs, cfg)
+	}
+	return legs
+}
+
+// expandDay grows every node in beam by one leg along each bearing on date:
+// first prefetching the unique leg sample points, then scoring each leg. last
+// marks the final day, where the round-trip penalty applies. With
+// cfg.RestDays each node may also stay put for the day, except in the refine
+// round (refine), which only adds the refined bearings.
+func expandDay(beam []beamNode, bearings [][]float64, date time.Time, last, refine bool, start latLon, cfg beamConfig, cache *hourlyCache, prog Progress) []beamNode {
+	// Phase 1: collect unique fetch points (samples along this day's legs)
+	// and, when climbing counts, each leg's elevation profile.
+	uniq := map[string]fetchPoint{}
+	var profile []latLon
+	for i, node := range beam {
+		cur := node.Positions[len(node.Positions)-1]
+		for _, leg := range nodeLegs(node, bearings[i], refine, cfg) {
+			if cfg.Obstacles.LegBlocked(cur, leg.Bearing, leg.Km) {
+				continue
+			}
+			for _, p := range legSamplePoints(cur, leg, cfg) {
+				key := hourlyCacheKey(p.Lat, p.Lon, date)
+				uniq[key] = fetchPoint{Lat: p.Lat, Lon: p.Lon, Date: date}
+			}
+			if cfg.ClimbPenalty > 0 {
+				profile = append(profile, legProfilePoints(cur, leg.Bearing, leg.Km)...)
+			}
+		}
+	}
+	points := make([]fetchPoint, 0, len(uniq))
+	for _, p := range uniq {
+		points = append(points, p)
+	}
+	slog.Debug("multiday: day pass", "date", date.Format("2006-01-02"), "uniqueFetches", len(points), "beamNodes", len(beam))
+	cache.prefetch(points, prog)
+	if len(profile) > 0 {
+		prefetchElevations(profile, prog)
+	}
+
+	// Phase 2: expand each beam node along every leg; score the result.
+	candidates := make([]beamNode, 0, len(beam)*(len(bearings[0])+1)*len(cfg.dayLengths()))
+	for i, node := range beam {
+		cur := node.Positions[len(node.Positions)-1]
+		prevBearing, hasPrev := lastRideBearing(node)
+		for _, leg := range nodeLegs(node, bearings[i], refine, cfg) {
+			if cfg.Obstacles.LegBlocked(cur, leg.Bearing, leg.Km) {
+				continue
+			}
+			hourly, ok := legHourly(cur, leg, date, cfg, cache)
+			if !ok {
+				continue
+			}
+			ds := ScoreDay(hourly, leg.Bearing, cfg.MinTemp)
+			if ds.Disqualified {
+				continue
+			}
+			applyDistance(&ds, leg.Km, cfg.KmPerDay)
+			if cfg.ClimbPenalty > 0 {
+				if climb, ok := legClimbM(cur, leg.Bearing, leg.Km); ok {
+					applyClimb(&ds, climb, cfg.ClimbPenalty)
+				} else {
+					slog.Debug("multiday: no elevation profile", "lat", cur.Lat, "lon", cur.Lon, "bearing", leg.Bearing)
+				}
+			}
+
+			pivot := 0.0
+			if hasPrev && prevBearing != leg.Heading {
+				pivot = cfg.PivotPenalty
+			}
+			newScore := node.Score + ds.Score - pivot
+
+			// Round-trip penalty is only applied on the last day — that's
+			// when "how far from home do we finish" actually matters.
+			if cfg.RoundTrip && last {
+				distKm := HaversineKm(leg.End.Lat, leg.End.Lon, start.Lat, start.Lon)
+				newScore -= distKm * cfg.RoundTripPenalty / 100
+			}
+
+			candidates = append(candidates, node.extend(leg.Heading, leg.End, leg.Stop, ds, newScore))
+		}
+
+		if cfg.RestDays && !refine {
+			// A rest day keeps the previous heading so it never counts as a
+			// pivot, and costs RestPenalty whatever the weather.
+			ds := DayScore{Rest: true, Score: -cfg.RestPenalty}
+			stop := ""
+			if len(node.Stops) > 0 {
+				stop = node.Stops[len(node.Stops)-1]
+			}
+			candidates = append(candidates, node.extend(prevBearing, cur, stop, ds, node.Score+ds.Score))
+		}
+	}
+	return candidates
+}
+
+// extend returns a copy of n with one more day appended.
+func (n beamNode) extend(bearing float64, end latLon, stop string, ds DayScore, score float64) beamNode {
+	return beamNode{
+		Bearings:    append(append([]float64{}, n.Bearings...), bearing),
+		Positions:   append(append([]latLon{}, n.Positions...), end),
+		Stops:       append(append([]string{}, n.Stops...), stop),
+		DailyScores: append(append([]DayScore{}, n.DailyScores...), ds),
+		Score:       score,
+	}
+}
+
+// legSamplePoints returns cfg.LegSamples points along the great-circle leg
+// from cur, each at the centre of its share of the day's distance (so one
+// sample is the classic midpoint) and snapped to the model grid.
+func legSamplePoints(cur latLon, leg beamLeg, cfg beamConfig) []latLon {
+	n := cfg.legSampleCount()
+	out := make([]latLon, n)
+	for i := range out {
+		frac := (float64(i) + 0.5) / float64(n)
+		lat, lon := DestinationPoint(cur.Lat, cur.Lon, leg.Bearing, leg.Km*frac)
+		out[i] = snapToModelGrid(lat, lon)
+	}
+	return out
+}
+
+// snapToModelGrid rounds a point to the forecast model's grid spacing, so
+// nearby samples from different beam nodes share one cache entry.
+func snapToModelGrid(lat, lon float64) latLon {
+	return latLon{
+		Lat: math.Round(lat/modelGridDeg) * modelGridDeg,
+		Lon: math.Round(lon/modelGridDeg) * modelGridDeg,
+	}
+}
+
+// legHourly assembles the hourly weather the rider actually meets on a leg:
+// the daytime window is split evenly between the samples, so morning hours
+// come from the samples near the start and afternoon hours from those near
+// the end. Returns false when any sample is missing or its grid cell is
+// water (elevation 0) away from a ferry, dam or bridge — you can't cycle
+// across the North Sea or IJsselmeer.
+func legHourly(cur latLon, leg beamLeg, date time.Time, cfg beamConfig, cache *hourlyCache) ([]HourlyForecast, bool) {
+	points := legSamplePoints(cur, leg, cfg)
+	samples := make([]*OpenMeteoData, len(points))
+	for i, p := range points {
+		data, ok := cache.get(p.Lat, p.Lon, date)
+		if !ok || (data.IsSea() && !cfg.Obstacles.OnCrossing(p)) {
+			return nil, false
+		}
+		samples[i] = data
+	}
+
+	var out []HourlyForecast
+	for i, data := range samples {
+		for _, h := range data.Hourly {
+			if legSampleForHour(h.Time.Hour(), len(samples)) == i {
+				out = append(out, h)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Time.Before(out[j].Time)
+	})
+	return out, true
+}
+
+// legSampleForHour maps an hour of day to the index of the leg sample the
+// rider is nearest to at that time, assuming steady progress across the
+// daytime window. Hours before the window belong to the first sample and
+// hours after it to the last.
+func legSampleForHour(hour, samples int) int {
+	switch {
+	case hour < daytimeStartHour:
+		return 0
+	case hour >= daytimeEndHour:
+		return samples - 1
+	}
+	return (hour - daytimeStartHour) * samples / (daytimeEndHour - daytimeStartHour)
+}
+
+// refineBearings picks, for each beam node, the bearings of its own
+// best-scoring candidates — up to multidayRefineTop distinct ones, so one
+// strong direction elsewhere in the beam can't crowd out the rest — and
+// returns their half-step neighbours that the first round didn't already
+// try, indexed like beam.
+func refineBearings(beam []beamNode, candidates []beamNode, step float64) [][]float64 {
+	ranked := append([]beamNode{}, candidates...)
+	sort.Slice(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	node := make(map[string]int, len(beam))
+	for i, n := range beam {
+		node[beamPathKey(n.Bearings, n.Positions)] = i
+	}
+	best := make([]map[float64]bool, len(beam))
+	for i := range best {
+		best[i] = map[float64]bool{}
+	}
+	for _, c := range ranked {
+		if c.DailyScores[len(c.DailyScores)-1].Rest {
+			continue
+		}
+		depth := len(c.Bearings) - 1
+		i, ok := node[beamPathKey(c.Bearings[:depth], c.Positions[:depth+1])]
+		if !ok || len(best[i]) >= multidayRefineTop {
+			continue
+		}
+		best[i][c.Bearings[depth]] = true
+	}
+
+	out := make([][]float64, len(beam))
+	for i, top := range best {
+		seen := map[float64]bool{}
+		for b := range top {
+			for _, nb := range []float64{normalizeDeg(b - step/2), normalizeDeg(b + step/2)} {
+				if top[nb] || seen[nb] {
+					continue
+				}
+				seen[nb] = true
+				out[i] = append(out[i], nb)
+			}
+		}
+		sort.Float64s(out[i])
+	}
+	return out
+}
+
+// beamPathKey identifies a beam node by the path it took, so a candidate can
+// be matched back to the node it extends.
+func beamPathKey(bearings []float64, positions []latLon) string {
+	return fmt.Sprint(bearings, positions)
+}
+
+// dedupeRoutes keeps one candidate per route — the same overnight positions
+// day by day — the best-scoring one, in the order they first appear.
+func dedupeRoutes(candidates []beamNode) []beamNode {
+	idx := make(map[string]int, len(candidates))
+	out := candidates[:0]
+	for _, c := range candidates {
+		key := fmt.Sprint(c.Positions)
+		if i, ok := idx[key]; ok {
+			if c.Score > out[i].Score {
+				out[i] = c
+			}
+			continue
+		}
+		idx[key] = len(out)
+		out = append(out, c)
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+)
+
+const (
+	climbStepKm        = 5.0 // spacing of the elevation profile along a leg
+	elevationBatchSize = 100 // Open-Meteo elevation API limit per request
+)
+
+// elevationKey is the cache key for one profile point; 3 decimals (~100 m)
+// is about the resolution of the 90 m DEM behind the elevation API.
+func elevationKey(p latLon) string {
+	return fmt.Sprintf("%.3f|%.3f", p.Lat, p.Lon)
+}
+
+// prefetchElevations fills elevationCache for every point not already in it,
+// in batches of elevationBatchSize with a bounded worker pool. Failed batches
+// are logged and left as cache misses.
+func prefetchElevations(points []latLon, prog Progress) {
+	seen := map[string]bool{}
+	var missing []latLon
+	for _, p := range points {
+		k := elevationKey(p)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		if _, ok := elevationCache.get(k); !ok {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	batches := (len(missing) + elevationBatchSize - 1) / elevationBatchSize
+	prog.AddTotal(batches)
+	sem := make(chan struct{}, multidayFetchWorkers)
+	var wg sync.WaitGroup
+	for start := 0; start < len(missing); start += elevationBatchSize {
+		end := min(start+elevationBatchSize, len(missing))
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(batch []latLon) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer prog.Inc(1)
+			elev, err := getElevations(batch)
+			if err != nil {
+				slog.Debug("multiday: elevation fetch failed", "points", len(batch), "err", err)
+				return
+			}
+			for i, p := range batch {
+				elevationCache.put(elevationKey(p), elev[i])
+			}
+		}(missing[start:end])
+	}
+	wg.Wait()
+}
+
+// getElevations asks the Open-Meteo elevation API for up to
+// elevationBatchSize points in one call.
+func getElevations(points []latLon) ([]float64, error) {
+	lats := make([]string, len(points))
+	lons := make([]string, len(points))
+	for i, p := range points {
+		lats[i] = fmt.Sprintf("%.3f", p.Lat)
+		lons[i] = fmt.Sprintf("%.3f", p.Lon)
+	}
+	url := fmt.Sprintf("https://api.open-meteo.com/v1/elevation?latitude=%s&longitude=%s",
+		strings.Join(lats, ","), strings.Join(lons, ","))
+	body, err := openMeteoGetBody(url)
+	if err != nil {
+		return nil, fmt.Errorf("elevation request: %w", err)
+	}
+	var parsed struct {
+		Elevation []float64 `json:"elevation"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("decode elevation: %w", err)
+	}
+	if len(parsed.Elevation) != len(points) {
+		return nil, fmt.Errorf("elevation: got %d values for %d points", len(parsed.Elevation), len(points))
+	}
+	return parsed.Elevation, nil
+}
+
+// legProfilePoints returns the points of a leg's elevation profile: the start,
+// one every climbStepKm, and the end.
+func legProfilePoints(cur latLon, bearing, km float64) []latLon {
+	steps := int(math.Ceil(km / climbStepKm))
+	out := make([]latLon, 0, steps+1)
+	out = append(out, cur)
+	for i := 1; i <= steps; i++ {
+		lat, lon := DestinationPoint(cur.Lat, cur.Lon, bearing, math.Min(float64(i)*climbStepKm, km))
+		out = append(out, latLon{lat, lon})
+	}
+	return out
+}
+
+// legClimbM sums the ascents along a leg's elevation profile. Returns false
+// if any profile point is missing from elevationCache.
+func legClimbM(cur latLon, bearing, km float64) (float64, bool) {
+	climb := 0.0
+	prev := math.NaN()
+	for _, p := range legProfilePoints(cur, bearing, km) {
+		e, ok := elevationCache.get(elevationKey(p))
+		if !ok {
+			return 0, false
+		}
+		if e > prev {
+			climb += e - prev
+		}
+		prev = e
+	}
+	return climb, true
+}
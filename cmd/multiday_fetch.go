@@ -88,19 +88,35 @@ func (d *OpenMeteoData) IsSea() bool {
 // Results are cached process-wide for a short TTL (openMeteoRangeCache) and
 // MUST be treated as read-only — the cache shares the returned pointer.
 func GetOpenMeteoRange(lat, lon float64, startDate, endDate time.Time) (*OpenMeteoData, error) {
-	key := fmt.Sprintf("%.3f|%.3f|%s|%s", lat, lon,
-		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	return GetOpenMeteoRangeModel(lat, lon, startDate, endDate, "")
+}
+
+// GetOpenMeteoRangeModel is GetOpenMeteoRange for one named Open-Meteo model
+// ("knmi_seamless", "icon_d2", …). An empty model is Open-Meteo's default
+// best-match blend.
+func GetOpenMeteoRangeModel(lat, lon float64, startDate, endDate time.Time, model string) (*OpenMeteoData, error) {
+	key := fmt.Sprintf("%.3f|%.3f|%s|%s|%s", lat, lon,
+		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), model)
 	return memo(openMeteoRangeCache, key, func() (*OpenMeteoData, error) {
-		return getOpenMeteoRangeUncached(lat, lon, startDate, endDate)
+		return getOpenMeteoRangeUncached(lat, lon, startDate, endDate, model)
 	})
 }
 
-func getOpenMeteoRangeUncached(lat, lon float64, startDate, endDate time.Time) (*OpenMeteoData, error) {
+// openMeteoModelParam is the &models= query suffix for model, empty for the
+// default blend.
+func openMeteoModelParam(model string) string {
+	if model == "" {
+		return ""
+	}
+	return "&models=" + model
+}
+
+func getOpenMeteoRangeUncached(lat, lon float64, startDate, endDate time.Time, model string) (*OpenMeteoData, error) {
 	start := startDate.Format("2006-01-02")
 	end := endDate.Format("2006-01-02")
 	url := fmt.Sprintf(
-		"https://api.open-meteo.com/v1/forecast?latitude=%.4f&longitude=%.4f&hourly=temperature_2m,apparent_temperature,precipitation,precipitation_probability,wind_speed_10m,wind_direction_10m,wind_gusts_10m,uv_index,weather_code&daily=sunrise,sunset&timezone=auto&start_date=%s&end_date=%s",
-		lat, lon, start, end,
+		"https://api.open-meteo.com/v1/forecast?latitude=%.4f&longitude=%.4f&hourly=temperature_2m,apparent_temperature,precipitation,precipitation_probability,wind_speed_10m,wind_direction_10m,wind_gusts_10m,uv_index,weather_code&daily=sunrise,sunset&timezone=auto&start_date=%s&end_date=%s%s",
+		lat, lon, start, end, openMeteoModelParam(model),
 	)
 	slog.Debug("open-meteo: requesting", "url", url)
 
@@ -26,25 +26,43 @@ func DestinationPoint(lat, lon, bearingDeg, distanceKm float64) (float64, float6
 	return phi2 * 180 / math.Pi, lambda2 * 180 / math.Pi
 }
 
-var compassNames = [8]string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+// InitialBearing returns the great-circle bearing (0° = N, clockwise) to set
+// off on from (lat1, lon1) to reach (lat2, lon2).
+func InitialBearing(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	return normalizeDeg(math.Atan2(y, x) * 180 / math.Pi)
+}
+
+var compassNames = [16]string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
 var compassArrows = [8]string{"↑", "↗", "→", "↘", "↓", "↙", "←", "↖"}
 
-func compassIndex(bearingDeg float64) int {
-	idx := int(math.Round(bearingDeg/45)) % 8
+// compassIndex rounds bearingDeg to the nearest of `points` evenly spaced
+// compass directions, 0 = N.
+func compassIndex(bearingDeg float64, points int) int {
+	idx := int(math.Round(bearingDeg/(360/float64(points)))) % points
 	if idx < 0 {
-		idx += 8
+		idx += points
 	}
 	return idx
 }
 
-// CompassName rounds the bearing to the nearest 8-point compass name.
+// CompassName rounds the bearing to the nearest 16-point compass name. The
+// 8 principal and intercardinal bearings keep their short names (N, NE, …),
+// so 8-bearing trips read exactly as before.
 func CompassName(bearingDeg float64) string {
-	return compassNames[compassIndex(bearingDeg)]
+	return compassNames[compassIndex(bearingDeg, len(compassNames))]
 }
 
 // CompassArrow rounds the bearing to the nearest 8-point compass arrow glyph.
 func CompassArrow(bearingDeg float64) string {
-	return compassArrows[compassIndex(bearingDeg)]
+	return compassArrows[compassIndex(bearingDeg, len(compassArrows))]
 }
 
 // HaversineKm returns the great-circle distance in km between two lat/lon points.
@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"math"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -48,7 +50,10 @@ type heatmapResult struct {
 
 // RunHeatmap builds a grid of sample points around (startLat, startLon),
 // fetches a multi-day forecast for each, and scores each (cell, day) with
-// ScoreDayOmni.
+// ScoreDayOmni. A cell is marked Sea when it lies inside cfg.Obstacles —
+// checked before fetching, so open water costs no request — or when its
+// model grid cell turns out to be water. With cfg.Risk set, cells are scored
+// on that ensemble percentile like the beam search.
 func RunHeatmap(startLat, startLon float64, startDate time.Time, days int, cfg beamConfig, gridSize int, prog Progress) heatmapResult {
 	if gridSize < 5 {
 		gridSize = 5
@@ -88,14 +93,26 @@ func RunHeatmap(startLat, startLon float64, startDate time.Time, days int, cfg b
 
 	type cellData struct {
 		Row, Col int
+		Water    bool // inside the obstacle layer, not fetched
 		Data     *OpenMeteoData
 		Err      error
 	}
 	results := make([]cellData, len(cells))
+	fetch := 0
+	for i, c := range cells {
+		if cfg.Obstacles.Water(latLon{c.Lat, c.Lon}) {
+			results[i] = cellData{Row: c.Row, Col: c.Col, Water: true}
+			continue
+		}
+		fetch++
+	}
 	sem := make(chan struct{}, multidayFetchWorkers)
 	var wg sync.WaitGroup
-	prog.AddTotal(len(cells))
+	prog.AddTotal(fetch)
 	for i, c := range cells {
+		if results[i].Water {
+			continue
+		}
 		wg.Add(1)
 		sem <- struct{}{}
 		go func(i int, c cellCoord) {
@@ -103,6 +120,9 @@ func RunHeatmap(startLat, startLon float64, startDate time.Time, days int, cfg b
 			defer func() { <-sem }()
 			defer prog.Inc(1)
 			data, err := GetOpenMeteoRange(c.Lat, c.Lon, startDate, endDate)
+			if err == nil {
+				data = withEnsembleScenario(data, c.Lat, c.Lon, startDate, endDate, cfg.Risk)
+			}
 			results[i] = cellData{Row: c.Row, Col: c.Col, Data: data, Err: err}
 		}(i, c)
 	}
@@ -125,6 +145,12 @@ func RunHeatmap(startLat, startLon float64, startDate time.Time, days int, cfg b
 	}
 
 	for _, rd := range results {
+		if rd.Water {
+			for d := 0; d < days; d++ {
+				out.Cells[d][rd.Row][rd.Col] = cellStatus{Sea: true}
+			}
+			continue
+		}
 		if rd.Err != nil || rd.Data == nil || len(rd.Data.Hourly) == 0 {
 			for d := 0; d < days; d++ {
 				out.Cells[d][rd.Row][rd.Col] = cellStatus{NoData: true}
@@ -169,42 +195,47 @@ func RunHeatmap(startLat, startLon float64, startDate time.Time, days int, cfg b
 // renderHeatmap prints one small map per day, stacked vertically, with a
 // legend at the bottom.
 func renderHeatmap(h heatmapResult) {
+	for d := range h.Days {
+		renderHeatmapDay(os.Stdout, h, d)
+		fmt.Println()
+	}
+	renderHeatmapLegend(os.Stdout)
+}
+
+// renderHeatmapDay prints the map for day d.
+func renderHeatmapDay(w io.Writer, h heatmapResult, d int) {
 	gridSize := h.Grid
 	mid := gridSize / 2
-	for d, day := range h.Days {
-		fmt.Printf("%sDay %d  %s%s\n",
-			termplt.ColorBold, d+1, day.Format("2006-01-02"), termplt.ColorReset,
-		)
-		for row := 0; row < gridSize; row++ {
-			fmt.Print("  ")
-			for col := 0; col < gridSize; col++ {
-				cell := h.Cells[d][row][col]
-				isStart := row == mid && col == mid
-				fmt.Print(heatmapCellGlyph(cell, isStart))
-			}
-			northCells := mid - row
-			fmt.Printf("  %+4d km\n", int(float64(northCells)*h.StepKm))
-		}
-		// Bottom axis label (E/W distances at the edges, "start" in the middle).
-		fmt.Print("  ")
-		eastEdge := int(float64(mid) * h.StepKm)
-		left := fmt.Sprintf("-%d km W", eastEdge)
-		right := fmt.Sprintf("+%d km E", eastEdge)
-		const mark = "start"
-		axisWidth := gridSize * 2
-		midStart := (axisWidth - len(mark)) / 2
-		leftGap := midStart - len(left)
-		if leftGap < 1 {
-			leftGap = 1
-		}
-		rightGap := axisWidth - midStart - len(mark) - len(right)
-		if rightGap < 1 {
-			rightGap = 1
+	fmt.Fprintf(w, "%sDay %d  %s%s\n",
+		termplt.ColorBold, d+1, h.Days[d].Format("2006-01-02"), termplt.ColorReset,
+	)
+	for row := 0; row < gridSize; row++ {
+		fmt.Fprint(w, "  ")
+		for col := 0; col < gridSize; col++ {
+			cell := h.Cells[d][row][col]
+			isStart := row == mid && col == mid
+			fmt.Fprint(w, heatmapCellGlyph(cell, isStart))
 		}
-		fmt.Printf("%s%s%s%s%s\n", left, strings.Repeat(" ", leftGap), mark, strings.Repeat(" ", rightGap), right)
-		fmt.Println()
+		northCells := mid - row
+		fmt.Fprintf(w, "  %+4d km\n", int(float64(northCells)*h.StepKm))
+	}
+	// Bottom axis label (E/W distances at the edges, "start" in the middle).
+	fmt.Fprint(w, "  ")
+	eastEdge := int(float64(mid) * h.StepKm)
+	left := fmt.Sprintf("-%d km W", eastEdge)
+	right := fmt.Sprintf("+%d km E", eastEdge)
+	const mark = "start"
+	axisWidth := gridSize * 2
+	midStart := (axisWidth - len(mark)) / 2
+	leftGap := midStart - len(left)
+	if leftGap < 1 {
+		leftGap = 1
+	}
+	rightGap := axisWidth - midStart - len(mark) - len(right)
+	if rightGap < 1 {
+		rightGap = 1
 	}
-	renderHeatmapLegend()
+	fmt.Fprintf(w, "%s%s%s%s%s\n", left, strings.Repeat(" ", leftGap), mark, strings.Repeat(" ", rightGap), right)
 }
 
 // heatmapCellGlyph returns the 2-char rendered cell.
@@ -275,31 +306,31 @@ func heatmapWindSymbol(c cellStatus) string {
 	}
 }
 
-func renderHeatmapLegend() {
+func renderHeatmapLegend(w io.Writer) {
 	rst := termplt.ColorReset
 	sw := func(bg, body string) string { return bg + body + rst }
 	min := FlagMultidayMinTemp
 	b := termplt.ColorBold
-	fmt.Println(b + "Legend" + rst + " — background = temperature, symbol = wind:")
-	fmt.Println()
-	fmt.Println(b + "  Temperature (cell background)" + rst)
-	fmt.Printf("    %s    ideal, ≥%.0f°C\n", sw(termplt.ColorBackgroundBrightGreen, "   "), min+5)
-	fmt.Printf("    %s    warm, %.0f–%.0f°C\n", sw(termplt.ColorBackgroundGreen, "   "), min, min+5)
-	fmt.Printf("    %s    cool, %.0f–%.0f°C\n", sw(termplt.ColorBackgroundYellow, "   "), min-5, min)
-	fmt.Printf("    %s    cold, below %.0f°C\n", sw(termplt.ColorBackgroundBrightBlack, "   "), min-5)
-	fmt.Println()
-	fmt.Println(b + "  Wind (symbol in cell)" + rst)
-	fmt.Printf("    %s    calm, ≤%.0f km/h\n", sw(termplt.ColorBackgroundGreen, "   "), windCalmKmh)
-	fmt.Printf("    %s    breezy, %.0f–%.0f km/h\n", sw(termplt.ColorBackgroundGreen, " · "), windCalmKmh, windBreezyKmh)
-	fmt.Printf("    %s    windy, %.0f–%.0f km/h\n", sw(termplt.ColorBackgroundGreen, " ~ "), windBreezyKmh, windWindyKmh)
-	fmt.Printf("    %s    strong, %.0f–%.0f km/h\n", sw(termplt.ColorBackgroundGreen, " ≈ "), windWindyKmh, windStrongKmh)
-	fmt.Println()
-	fmt.Println(b + "  Overrides" + rst)
-	fmt.Printf("    %s    any daytime rain — skip this day\n", sw(termplt.ColorBackgroundBlue, " · "))
-	fmt.Printf("    %s    gust ≥%.0f km/h — skip this day\n", sw(termplt.ColorBackgroundRed, " ✗ "), gustDisqualify)
-	fmt.Printf("    %s    over water — not rideable\n", sw(termplt.ColorBackgroundCyan, "~~ "))
-	fmt.Printf("    %s    your starting point (overlaid on whichever colour that cell would be)\n",
+	fmt.Fprintln(w, b+"Legend"+rst+" — background = temperature, symbol = wind:")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, b+"  Temperature (cell background)"+rst)
+	fmt.Fprintf(w, "    %s    ideal, ≥%.0f°C\n", sw(termplt.ColorBackgroundBrightGreen, "   "), min+5)
+	fmt.Fprintf(w, "    %s    warm, %.0f–%.0f°C\n", sw(termplt.ColorBackgroundGreen, "   "), min, min+5)
+	fmt.Fprintf(w, "    %s    cool, %.0f–%.0f°C\n", sw(termplt.ColorBackgroundYellow, "   "), min-5, min)
+	fmt.Fprintf(w, "    %s    cold, below %.0f°C\n", sw(termplt.ColorBackgroundBrightBlack, "   "), min-5)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, b+"  Wind (symbol in cell)"+rst)
+	fmt.Fprintf(w, "    %s    calm, ≤%.0f km/h\n", sw(termplt.ColorBackgroundGreen, "   "), windCalmKmh)
+	fmt.Fprintf(w, "    %s    breezy, %.0f–%.0f km/h\n", sw(termplt.ColorBackgroundGreen, " · "), windCalmKmh, windBreezyKmh)
+	fmt.Fprintf(w, "    %s    windy, %.0f–%.0f km/h\n", sw(termplt.ColorBackgroundGreen, " ~ "), windBreezyKmh, windWindyKmh)
+	fmt.Fprintf(w, "    %s    strong, %.0f–%.0f km/h\n", sw(termplt.ColorBackgroundGreen, " ≈ "), windWindyKmh, windStrongKmh)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, b+"  Overrides"+rst)
+	fmt.Fprintf(w, "    %s    any daytime rain — skip this day\n", sw(termplt.ColorBackgroundBlue, " · "))
+	fmt.Fprintf(w, "    %s    gust ≥%.0f km/h — skip this day\n", sw(termplt.ColorBackgroundRed, " ✗ "), gustDisqualify)
+	fmt.Fprintf(w, "    %s    over water — not rideable\n", sw(termplt.ColorBackgroundCyan, "~~ "))
+	fmt.Fprintf(w, "    %s    your starting point (overlaid on whichever colour that cell would be)\n",
 		termplt.ColorBackgroundBrightGreen+b+termplt.ColorWhite+" ● "+rst)
-	fmt.Printf("    %s    no data from the forecast provider (try refreshing)\n",
+	fmt.Fprintf(w, "    %s    no data from the forecast provider (try refreshing)\n",
 		sw(termplt.ColorBackgroundBrightBlack, "   "))
 }
@@ -0,0 +1,352 @@
+package cmd
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+)
+
+//go:embed geo
+var geoFS embed.FS
+
+const (
+	obstacleStepKm     = 2.0 // spacing of the points checked along a leg
+	crossingCorridorKm = 3.0 // how far off a ferry line or fixed crossing a blocked point is still forgiven
+	kmPerDegLat        = 110.57
+	kmPerDegLonEquat   = 111.32
+)
+
+// obstacleLayer is one source of no-go areas: water, borders, restricted
+// zones. Implementations must be safe for concurrent use.
+type obstacleLayer interface {
+	Blocked(p latLon) bool
+}
+
+// obstacleSet combines the active obstacle layers with the lines that may
+// cross them: ferry routes, and the dams and bridges the coarse water mask
+// draws over. A nil *obstacleSet blocks nothing.
+type obstacleSet struct {
+	layers    []obstacleLayer
+	crossings []geoLine
+}
+
+// Water reports whether p falls inside any obstacle layer, ignoring
+// crossings. The heatmap uses this: a ferry or a dam doesn't make a cell
+// rideable.
+func (s *obstacleSet) Water(p latLon) bool {
+	if s == nil {
+		return false
+	}
+	for _, l := range s.layers {
+		if l.Blocked(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// OnCrossing reports whether p lies within crossingCorridorKm of a ferry
+// line or fixed crossing.
+func (s *obstacleSet) OnCrossing(p latLon) bool {
+	if s == nil {
+		return false
+	}
+	for _, f := range s.crossings {
+		if f.distanceKm(p) <= crossingCorridorKm {
+			return true
+		}
+	}
+	return false
+}
+
+// LegBlocked walks the great-circle leg from cur along bearing for km,
+// checking a point every obstacleStepKm (plus the endpoint). The leg is
+// blocked if any point is inside an obstacle and not on a crossing.
+func (s *obstacleSet) LegBlocked(cur latLon, bearing, km float64) bool {
+	if s == nil || len(s.layers) == 0 {
+		return false
+	}
+	steps := int(math.Ceil(km / obstacleStepKm))
+	for i := 1; i <= steps; i++ {
+		d := math.Min(float64(i)*obstacleStepKm, km)
+		lat, lon := DestinationPoint(cur.Lat, cur.Lon, bearing, d)
+		p := latLon{lat, lon}
+		if s.Water(p) && !s.OnCrossing(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadObstacles builds the obstacle set for a run: the bundled coarse Dutch
+// water mask (when waterMask is set), plus the polygons from a user GeoJSON
+// file. The mask comes with the dams and bridges across it (Afsluitdijk,
+// Houtribdijk, the Delta Works), always open. Ferry lines — the bundled
+// ones and any LineStrings in the user file — are only used when ferries is
+// set.
+func loadObstacles(waterMask bool, path string, ferries bool) (*obstacleSet, error) {
+	s := &obstacleSet{}
+	if waterMask {
+		water, _, err := bundledGeo("geo/nl_water.geojson")
+		if err != nil {
+			return nil, err
+		}
+		_, fixed, err := bundledGeo("geo/nl_crossings.geojson")
+		if err != nil {
+			return nil, err
+		}
+		s.layers = append(s.layers, water)
+		s.crossings = append(s.crossings, fixed...)
+	}
+	if ferries {
+		_, lines, err := bundledGeo("geo/nl_ferries.geojson")
+		if err != nil {
+			return nil, err
+		}
+		s.crossings = append(s.crossings, lines...)
+	}
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read obstacles: %w", err)
+		}
+		layer, lines, err := parseGeoJSON(raw)
+		if err != nil {
+			return nil, fmt.Errorf("parse obstacles %s: %w", path, err)
+		}
+		s.layers = append(s.layers, layer)
+		if ferries {
+			s.crossings = append(s.crossings, lines...)
+		}
+	}
+	return s, nil
+}
+
+type bundledGeoResult struct {
+	layer polygonLayer
+	lines []geoLine
+	err   error
+}
+
+var (
+	bundledGeoMu    sync.Mutex
+	bundledGeoCache = map[string]bundledGeoResult{}
+)
+
+// bundledGeo parses an embedded GeoJSON file once per process.
+func bundledGeo(name string) (polygonLayer, []geoLine, error) {
+	bundledGeoMu.Lock()
+	defer bundledGeoMu.Unlock()
+	if r, ok := bundledGeoCache[name]; ok {
+		return r.layer, r.lines, r.err
+	}
+	var r bundledGeoResult
+	raw, err := geoFS.ReadFile(name)
+	if err != nil {
+		r.err = fmt.Errorf("read bundled %s: %w", name, err)
+	} else if r.layer, r.lines, err = parseGeoJSON(raw); err != nil {
+		r.err = fmt.Errorf("parse bundled %s: %w", name, err)
+	}
+	bundledGeoCache[name] = r
+	return r.layer, r.lines, r.err
+}
+
+// ---------- GeoJSON ----------
+
+// geoJSONObject covers the GeoJSON shapes we read: FeatureCollection,
+// Feature, GeometryCollection and the bare geometries.
+type geoJSONObject struct {
+	Type        string          `json:"type"`
+	Features    []geoJSONObject `json:"features"`
+	Geometry    *geoJSONObject  `json:"geometry"`
+	Geometries  []geoJSONObject `json:"geometries"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Properties  map[string]any  `json:"properties"`
+}
+
+// parseGeoJSON returns the Polygon/MultiPolygon geometries in raw as a
+// polygonLayer and the LineString/MultiLineString geometries as lines.
+// Points and unknown types are ignored.
+func parseGeoJSON(raw []byte) (polygonLayer, []geoLine, error) {
+	var obj geoJSONObject
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return polygonLayer{}, nil, fmt.Errorf("decode geojson: %w", err)
+	}
+	var layer polygonLayer
+	var lines []geoLine
+	if err := collectGeoJSON(obj, &layer, &lines); err != nil {
+		return polygonLayer{}, nil, err
+	}
+	return layer, lines, nil
+}
+
+func collectGeoJSON(obj geoJSONObject, layer *polygonLayer, lines *[]geoLine) error {
+	switch obj.Type {
+	case "FeatureCollection":
+		for _, f := range obj.Features {
+			if err := collectGeoJSON(f, layer, lines); err != nil {
+				return err
+			}
+		}
+	case "Feature":
+		if obj.Geometry != nil {
+			return collectGeoJSON(*obj.Geometry, layer, lines)
+		}
+	case "GeometryCollection":
+		for _, g := range obj.Geometries {
+			if err := collectGeoJSON(g, layer, lines); err != nil {
+				return err
+			}
+		}
+	case "Polygon":
+		var rings [][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &rings); err != nil {
+			return fmt.Errorf("decode polygon: %w", err)
+		}
+		layer.add(rings)
+	case "MultiPolygon":
+		var polys [][][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &polys); err != nil {
+			return fmt.Errorf("decode multipolygon: %w", err)
+		}
+		for _, rings := range polys {
+			layer.add(rings)
+		}
+	case "LineString":
+		var pts [][]float64
+		if err := json.Unmarshal(obj.Coordinates, &pts); err != nil {
+			return fmt.Errorf("decode linestring: %w", err)
+		}
+		*lines = append(*lines, toLatLons(pts))
+	case "MultiLineString":
+		var parts [][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &parts); err != nil {
+			return fmt.Errorf("decode multilinestring: %w", err)
+		}
+		for _, pts := range parts {
+			*lines = append(*lines, toLatLons(pts))
+		}
+	}
+	return nil
+}
+
+// toLatLons converts GeoJSON [lon, lat(, alt)] positions, skipping short ones.
+func toLatLons(pts [][]float64) []latLon {
+	out := make([]latLon, 0, len(pts))
+	for _, p := range pts {
+		if len(p) < 2 {
+			continue
+		}
+		out = append(out, latLon{Lat: p[1], Lon: p[0]})
+	}
+	return out
+}
+
+// ---------- polygons ----------
+
+// geoPolygon is an outer ring with optional holes, plus its bounding box so
+// most points are rejected without walking the ring.
+type geoPolygon struct {
+	Outer                          []latLon
+	Holes                          [][]latLon
+	MinLat, MaxLat, MinLon, MaxLon float64
+}
+
+// polygonLayer is an obstacleLayer backed by a list of polygons.
+type polygonLayer struct {
+	Polygons []geoPolygon
+}
+
+func (l *polygonLayer) add(rings [][][]float64) {
+	if len(rings) == 0 {
+		return
+	}
+	poly := geoPolygon{
+		Outer:  toLatLons(rings[0]),
+		MinLat: math.Inf(1), MaxLat: math.Inf(-1),
+		MinLon: math.Inf(1), MaxLon: math.Inf(-1),
+	}
+	if len(poly.Outer) < 3 {
+		return
+	}
+	for _, p := range poly.Outer {
+		poly.MinLat = math.Min(poly.MinLat, p.Lat)
+		poly.MaxLat = math.Max(poly.MaxLat, p.Lat)
+		poly.MinLon = math.Min(poly.MinLon, p.Lon)
+		poly.MaxLon = math.Max(poly.MaxLon, p.Lon)
+	}
+	for _, h := range rings[1:] {
+		poly.Holes = append(poly.Holes, toLatLons(h))
+	}
+	l.Polygons = append(l.Polygons, poly)
+}
+
+// Blocked reports whether p lies inside any polygon (and outside its holes).
+func (l polygonLayer) Blocked(p latLon) bool {
+	for _, poly := range l.Polygons {
+		if p.Lat < poly.MinLat || p.Lat > poly.MaxLat || p.Lon < poly.MinLon || p.Lon > poly.MaxLon {
+			continue
+		}
+		if !ringContains(poly.Outer, p) {
+			continue
+		}
+		inHole := false
+		for _, h := range poly.Holes {
+			if ringContains(h, p) {
+				inHole = true
+				break
+			}
+		}
+		if !inHole {
+			return true
+		}
+	}
+	return false
+}
+
+// ringContains is the even-odd ray-casting test, treating lat/lon as planar —
+// fine for the small, coarse polygons we deal with.
+func ringContains(ring []latLon, p latLon) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lon < (b.Lon-a.Lon)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// ---------- lines ----------
+
+// geoLine is a polyline, e.g. a ferry route.
+type geoLine []latLon
+
+// distanceKm is the shortest distance from p to the polyline, using a local
+// equirectangular projection around p.
+func (l geoLine) distanceKm(p latLon) float64 {
+	kx := kmPerDegLonEquat * math.Cos(p.Lat*math.Pi/180)
+	project := func(q latLon) (float64, float64) {
+		return (q.Lon - p.Lon) * kx, (q.Lat - p.Lat) * kmPerDegLat
+	}
+	best := math.Inf(1)
+	for i := range l {
+		ax, ay := project(l[i])
+		if i == len(l)-1 {
+			best = math.Min(best, math.Hypot(ax, ay))
+			break
+		}
+		bx, by := project(l[i+1])
+		dx, dy := bx-ax, by-ay
+		t := 0.0
+		if seg := dx*dx + dy*dy; seg > 0 {
+			t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/seg))
+		}
+		best = math.Min(best, math.Hypot(ax+t*dx, ay+t*dy))
+	}
+	return best
+}
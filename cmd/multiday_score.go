@@ -13,6 +13,10 @@ const (
 	windWindyKmh   = 40.0 // 25–40 is a hard ride, especially as headwind
 	windStrongKmh  = 60.0 // 40–60 is a fight
 	gustDisqualify = 60.0 // any gust ≥60 km/h knocks out the day
+
+	climbHeatC = 25.0 // above this daytime max, climbing costs more per °C
+
+	distanceOffPenalty = 0.5 // per 10 km away from the preferred daily distance
 )
 
 // DayScore summarises a single day at a single sample point.
@@ -27,6 +31,9 @@ type DayScore struct {
 	MaxGust          float64 `json:"maxGust"`
 	MaxPrecip        float64 `json:"maxPrecip"`
 	BelowMinTemp     bool    `json:"belowMinTemp"` // true if MaxTemp < user's minTemp
+	ClimbM           float64 `json:"climbM"`       // approximate ascent along the leg, metres
+	DistanceKm       float64 `json:"distanceKm"`   // length of the day's leg
+	Rest             bool    `json:"rest"`         // rest day: no riding, position unchanged
 }
 
 // ScoreDay evaluates a day's daytime-hour weather against the chosen bearing.
@@ -38,7 +45,6 @@ func ScoreDay(hourly []HourlyForecast, bearingDeg float64, minTemp float64) DayS
 		MaxTemp: -math.MaxFloat64,
 	}
 
-	bearingRad := bearingDeg * math.Pi / 180
 	tailwindSum := 0.0
 	dayCount := 0
 
@@ -65,11 +71,7 @@ func ScoreDay(hourly []HourlyForecast, bearingDeg float64, minTemp float64) DayS
 			ds.MaxSustainedWind = h.WindSpeed
 		}
 
-		// Meteorological wind direction is where wind comes FROM. "Blows toward"
-		// is from+180°. Tailwind = projection of that vector onto the heading:
-		// -speed * cos(bearing - from).
-		windFromRad := h.WindDirection * math.Pi / 180
-		tailwindSum += -h.WindSpeed * math.Cos(bearingRad-windFromRad)
+		tailwindSum += tailwindComponent(h.WindSpeed, h.WindDirection, bearingDeg)
 	}
 
 	if dayCount == 0 {
@@ -106,6 +108,47 @@ func ScoreDay(hourly []HourlyForecast, bearingDeg float64, minTemp float64) DayS
 	return ds
 }
 
+// tailwindComponent is the part of the wind along a heading: positive pushes
+// you, negative is headwind. Meteorological wind direction is where wind
+// comes FROM, so "blows toward" is from+180° and the projection onto the
+// heading is -speed * cos(bearing - from).
+func tailwindComponent(speed, fromDeg, bearingDeg float64) float64 {
+	return -speed * math.Cos((bearingDeg-fromDeg)*math.Pi/180)
+}
+
+// applyDistance records the leg length on ds and rescales the wind term by
+// it relative to the preferred daily distance: a tailwind is worth more over
+// a long day, a headwind hurts more. That is what lets the search ride long
+// with the wind and short against it. Each 10 km off preferredKm also costs
+// distanceOffPenalty, so lengths are only stretched when the weather pays.
+func applyDistance(ds *DayScore, km, preferredKm float64) {
+	ds.DistanceKm = km
+	if preferredKm <= 0 {
+		return
+	}
+	ds.Score += ds.TailwindAvg * (km/preferredKm - 1)
+	ds.Score -= math.Abs(km-preferredKm) / 10 * distanceOffPenalty
+}
+
+// applyClimb records the leg's ascent on ds and subtracts the climbing
+// penalty: perHundred points per 100 m, scaled up when the climb comes with
+// a headwind (+50% at 25 km/h) or heat (+5% per °C above climbHeatC).
+func applyClimb(ds *DayScore, climbM, perHundred float64) {
+	ds.ClimbM = climbM
+	ds.Score -= climbPenalty(climbM, ds.TailwindAvg, ds.MaxTemp, perHundred)
+}
+
+func climbPenalty(climbM, tailwindAvg, maxTemp, perHundred float64) float64 {
+	factor := 1.0
+	if tailwindAvg < 0 {
+		factor += -tailwindAvg / windBreezyKmh * 0.5
+	}
+	if maxTemp > climbHeatC {
+		factor += (maxTemp - climbHeatC) * 0.05
+	}
+	return climbM / 100 * perHundred * factor
+}
+
 // cyclingWindPenalty is the score cost of riding through max sustained wind
 // of w km/h. 0 below the calm band, ramps up as wind crosses each threshold.
 func cyclingWindPenalty(w float64) float64 {
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// overnightStop is a place a day may end: a campsite, hostel, station.
+type overnightStop struct {
+	Name string `json:"name"`
+	Pos  latLon `json:"pos"`
+}
+
+// loadStops reads overnight stops from a CSV or GeoJSON file, picked by
+// extension (.csv, or .geojson/.json).
+//
+// CSV files need a header row naming the columns: "name", "lat"/"latitude"
+// and "lon"/"lng"/"longitude", in any order; other columns are ignored.
+// GeoJSON files contribute every Point (and MultiPoint) feature, named by its
+// "name" or "title" property.
+func loadStops(path string) ([]overnightStop, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open stops: %w", err)
+	}
+	defer closeBody(f, "stops file")
+
+	var stops []overnightStop
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		stops, err = parseStopsCSV(f)
+	case ".geojson", ".json":
+		var raw []byte
+		raw, err = io.ReadAll(f)
+		if err == nil {
+			stops, err = parseStopsGeoJSON(raw)
+		}
+	default:
+		return nil, fmt.Errorf("stops file %s: want .csv, .geojson or .json", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse stops %s: %w", path, err)
+	}
+	if len(stops) == 0 {
+		return nil, fmt.Errorf("stops file %s has no stops", path)
+	}
+	return stops, nil
+}
+
+func parseStopsCSV(r io.Reader) ([]overnightStop, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	nameCol, latCol, lonCol := -1, -1, -1
+	for i, h := range header {
+		switch strings.ToLower(strings.TrimSpace(h)) {
+		case "name":
+			nameCol = i
+		case "lat", "latitude":
+			latCol = i
+		case "lon", "lng", "longitude":
+			lonCol = i
+		}
+	}
+	if latCol < 0 || lonCol < 0 {
+		return nil, errors.New("csv header needs lat and lon columns")
+	}
+
+	var stops []overnightStop
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv: %w", err)
+		}
+		if latCol >= len(rec) || lonCol >= len(rec) {
+			return nil, fmt.Errorf("line %d: missing lat/lon", line)
+		}
+		lat, err := strconv.ParseFloat(strings.TrimSpace(rec[latCol]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: lat: %w", line, err)
+		}
+		lon, err := strconv.ParseFloat(strings.TrimSpace(rec[lonCol]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: lon: %w", line, err)
+		}
+		name := fmt.Sprintf("%.3f,%.3f", lat, lon)
+		if nameCol >= 0 && nameCol < len(rec) && strings.TrimSpace(rec[nameCol]) != "" {
+			name = strings.TrimSpace(rec[nameCol])
+		}
+		stops = append(stops, overnightStop{Name: name, Pos: latLon{lat, lon}})
+	}
+	return stops, nil
+}
+
+func parseStopsGeoJSON(raw []byte) ([]overnightStop, error) {
+	var obj geoJSONObject
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, fmt.Errorf("decode geojson: %w", err)
+	}
+	var stops []overnightStop
+	var walk func(o geoJSONObject, props map[string]any) error
+	walk = func(o geoJSONObject, props map[string]any) error {
+		switch o.Type {
+		case "FeatureCollection":
+			for _, f := range o.Features {
+				if err := walk(f, nil); err != nil {
+					return err
+				}
+			}
+		case "Feature":
+			if o.Geometry != nil {
+				return walk(*o.Geometry, o.Properties)
+			}
+		case "Point":
+			var p []float64
+			if err := json.Unmarshal(o.Coordinates, &p); err != nil {
+				return fmt.Errorf("decode point: %w", err)
+			}
+			for _, pos := range toLatLons([][]float64{p}) {
+				stops = append(stops, overnightStop{Name: stopName(props, pos), Pos: pos})
+			}
+		case "MultiPoint":
+			var pts [][]float64
+			if err := json.Unmarshal(o.Coordinates, &pts); err != nil {
+				return fmt.Errorf("decode multipoint: %w", err)
+			}
+			for _, pos := range toLatLons(pts) {
+				stops = append(stops, overnightStop{Name: stopName(props, pos), Pos: pos})
+			}
+		}
+		return nil
+	}
+	if err := walk(obj, nil); err != nil {
+		return nil, err
+	}
+	return stops, nil
+}
+
+// stopName picks a display name from GeoJSON feature properties, falling
+// back to the coordinates.
+func stopName(props map[string]any, pos latLon) string {
+	for _, k := range []string{"name", "title"} {
+		if v, ok := props[k].(string); ok && strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return fmt.Sprintf("%.3f,%.3f", pos.Lat, pos.Lon)
+}
+
+// nearestStop returns the stop closest to p within maxKm.
+func nearestStop(stops []overnightStop, p latLon, maxKm float64) (overnightStop, bool) {
+	best, bestKm := overnightStop{}, math.Inf(1)
+	for _, s := range stops {
+		if d := HaversineKm(p.Lat, p.Lon, s.Pos.Lat, s.Pos.Lon); d < bestKm {
+			best, bestKm = s, d
+		}
+	}
+	return best, bestKm <= maxKm
+}
+
+// snapLegs moves every leg's endpoint to the nearest overnight stop within
+// cfg.StopToleranceKm and re-aims the leg at it, dropping legs with no stop
+// in reach. Legs that already arrive at the goal are left alone. When several
+// legs land on the same stop, the one whose heading points most directly at
+// it is kept.
+func snapLegs(cur latLon, legs []beamLeg, cfg beamConfig) []beamLeg {
+	out := make([]beamLeg, 0, len(legs))
+	byStop := map[string]int{} // stop name+pos → index in out
+	for _, leg := range legs {
+		if cfg.Goal != nil && HaversineKm(leg.End.Lat, leg.End.Lon, cfg.Goal.Lat, cfg.Goal.Lon) <= cfg.GoalRadiusKm {
+			out = append(out, leg)
+			continue
+		}
+		stop, ok := nearestStop(cfg.Stops, leg.End, cfg.StopToleranceKm)
+		if !ok {
+			continue
+		}
+		km := HaversineKm(cur.Lat, cur.Lon, stop.Pos.Lat, stop.Pos.Lon)
+		if km < 1 {
+			continue // the stop we're already at; that's a rest day
+		}
+		snapped := beamLeg{
+			Bearing: InitialBearing(cur.Lat, cur.Lon, stop.Pos.Lat, stop.Pos.Lon),
+			Km:      km,
+			Heading: leg.Heading,
+			End:     stop.Pos,
+			Stop:    stop.Name,
+		}
+		key := fmt.Sprintf("%s|%.5f|%.5f", stop.Name, stop.Pos.Lat, stop.Pos.Lon)
+		if i, seen := byStop[key]; seen {
+			if angleDiff(snapped.Bearing, snapped.Heading) < angleDiff(out[i].Bearing, out[i].Heading) {
+				out[i] = snapped
+			}
+			continue
+		}
+		byStop[key] = len(out)
+		out = append(out, snapped)
+	}
+	return out
+}
+
+// angleDiff is the absolute difference between two bearings, 0..180°.
+func angleDiff(a, b float64) float64 {
+	d := math.Abs(normalizeDeg(a - b))
+	if d > 180 {
+		d = 360 - d
+	}
+	return d
+}
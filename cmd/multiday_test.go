@@ -1,6 +1,12 @@
 package cmd
 
-import "testing"
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestVisibleWidth(t *testing.T) {
 	tests := []struct {
@@ -45,3 +51,380 @@ func TestPadRight(t *testing.T) {
 		})
 	}
 }
+
+func TestCompassName(t *testing.T) {
+	tests := []struct {
+		name    string
+		bearing float64
+		want    string
+	}{
+		{"north", 0, "N"},
+		{"wraps near 360", 355, "N"},
+		{"negative", -22.5, "NNW"},
+		{"north-north-east", 22.5, "NNE"},
+		{"east", 90, "E"},
+		{"south-south-west", 200, "SSW"},
+		{"32-point rounds to neighbour", 11.25, "NNE"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := CompassName(tc.bearing)
+			if got != tc.want {
+				t.Fatalf("CompassName(%v) = %q, want %q", tc.bearing, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLegSampleForHour(t *testing.T) {
+	tests := []struct {
+		name    string
+		hour    int
+		samples int
+		want    int
+	}{
+		{"single sample", 15, 1, 0},
+		{"before window", 7, 3, 0},
+		{"window start", 10, 3, 0},
+		{"early afternoon", 14, 3, 1},
+		{"late afternoon", 19, 3, 2},
+		{"after window", 22, 3, 2},
+		{"one sample per hour", 13, 10, 3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := legSampleForHour(tc.hour, tc.samples)
+			if got != tc.want {
+				t.Fatalf("legSampleForHour(%d, %d) = %d, want %d", tc.hour, tc.samples, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRefineBearings(t *testing.T) {
+	start := latLon{52, 5}
+	north := beamNode{Bearings: []float64{0}, Positions: []latLon{start, {53, 5}}}
+	east := beamNode{Bearings: []float64{90}, Positions: []latLon{start, {52, 6}}}
+	cand := func(parent beamNode, bearing, score float64) beamNode {
+		return parent.extend(bearing, latLon{}, "", DayScore{}, score)
+	}
+	tests := []struct {
+		name       string
+		beam       []beamNode
+		step       float64
+		candidates []beamNode
+		want       [][]float64
+	}{
+		{
+			"per node",
+			[]beamNode{north, east},
+			90,
+			[]beamNode{cand(north, 0, 9), cand(east, 180, 1)},
+			[][]float64{{45, 315}, {135, 225}},
+		},
+		{
+			"strong node doesn't crowd out the rest",
+			[]beamNode{north, east},
+			90,
+			[]beamNode{
+				cand(north, 0, 9), cand(north, 90, 8), cand(north, 180, 7), cand(north, 270, 6),
+				cand(east, 0, 1),
+			},
+			[][]float64{{45, 135, 225, 315}, {45, 315}},
+		},
+		{
+			"neighbours already tried are skipped",
+			[]beamNode{north},
+			45,
+			[]beamNode{cand(north, 0, 3), cand(north, 45, 2)},
+			[][]float64{{22.5, 67.5, 337.5}},
+		},
+		{
+			"rest days are no bearing",
+			[]beamNode{north},
+			90,
+			[]beamNode{north.extend(0, latLon{53, 5}, "", DayScore{Rest: true}, 5)},
+			[][]float64{nil},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := refineBearings(tc.beam, tc.candidates, tc.step)
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Fatalf("refineBearings() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunBeamSearchRefine(t *testing.T) {
+	start := latLon{52.1, 5.1}
+	date := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	// The same dry day everywhere, with a westerly so the beam leans east.
+	fakeDay := func(lat, lon float64, d time.Time) (*OpenMeteoData, error) {
+		data := &OpenMeteoData{Elevation: 5}
+		for h := 0; h < 24; h++ {
+			data.Hourly = append(data.Hourly, HourlyForecast{Time: d.Add(time.Duration(h) * time.Hour),
+				Temperature: 18, WindSpeed: 15, WindDirection: 270, WindGusts: 25})
+		}
+		return data, nil
+	}
+	goal := latLon{52.1, 6.6}
+	base := beamConfig{KmPerDay: 50, MinTemp: 10, BeamWidth: 40, Bearings: 8, Refine: true,
+		LegSamples: 1, RestDays: true, RestPenalty: 1, Goal: &goal, GoalRadiusKm: 15, GoalPull: 1}
+	withStops := base
+	for lon := 5.1; lon < 6.8; lon += 0.35 {
+		for _, lat := range []float64{51.8, 52.1, 52.4} {
+			withStops.Stops = append(withStops.Stops, overnightStop{Name: fmt.Sprintf("%.1f,%.2f", lat, lon), Pos: latLon{lat, lon}})
+		}
+	}
+	withStops.StopToleranceKm = 20
+
+	tests := []struct {
+		name string
+		cfg  beamConfig
+	}{
+		{"rest days and goal", base},
+		{"stops", withStops},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cache := newHourlyCache(0)
+			cache.fetch = fakeDay
+			beam := runBeamSearch(start.Lat, start.Lon, date, 3, tc.cfg, cache, NoProgress)
+			if len(beam) == 0 {
+				t.Fatal("empty beam")
+			}
+			seen := map[string]bool{}
+			for _, n := range beam {
+				key := fmt.Sprint(n.Positions)
+				if seen[key] {
+					t.Fatalf("route %v is in the beam twice", n.Positions)
+				}
+				seen[key] = true
+			}
+		})
+	}
+}
+
+func TestBundledWaterMask(t *testing.T) {
+	withFerries, err := loadObstacles(true, "", true)
+	if err != nil {
+		t.Fatalf("loadObstacles: %v", err)
+	}
+	noFerries, err := loadObstacles(true, "", false)
+	if err != nil {
+		t.Fatalf("loadObstacles: %v", err)
+	}
+	tests := []struct {
+		name      string
+		p         latLon
+		wantWater bool
+	}{
+		{"Utrecht", latLon{52.09, 5.12}, false},
+		{"Amsterdam", latLon{52.37, 4.90}, false},
+		{"Lelystad polder", latLon{52.50, 5.50}, false},
+		{"IJsselmeer", latLon{52.80, 5.35}, true},
+		{"Markermeer", latLon{52.55, 5.20}, true},
+		{"North Sea", latLon{52.50, 3.50}, true},
+		{"Westerschelde", latLon{51.42, 3.80}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := noFerries.Water(tc.p); got != tc.wantWater {
+				t.Fatalf("Water(%v) = %v, want %v", tc.p, got, tc.wantWater)
+			}
+		})
+	}
+
+	legs := []struct {
+		name     string
+		from, to latLon
+		ferries  bool
+		blocked  bool
+	}{
+		// Enkhuizen → Stavoren heads NNE straight across the IJsselmeer.
+		{"ferry leg blocked without ferries", latLon{52.70, 5.295}, latLon{52.885, 5.36}, false, true},
+		{"ferry leg open with ferries", latLon{52.70, 5.295}, latLon{52.885, 5.36}, true, false},
+		{"across the IJsselmeer off the dikes", latLon{52.60, 5.10}, latLon{52.85, 5.55}, false, true},
+		{"Afsluitdijk", latLon{52.935, 5.04}, latLon{53.09, 5.405}, false, false},
+		{"Houtribdijk", latLon{52.70, 5.29}, latLon{52.53, 5.43}, false, false},
+		{"Zeelandbrug", latLon{51.65, 3.92}, latLon{51.59, 3.85}, false, false},
+		{"Brouwersdam", latLon{51.77, 3.86}, latLon{51.73, 3.83}, false, false},
+		{"Haringvlietdam", latLon{51.845, 4.05}, latLon{51.81, 4.03}, false, false},
+	}
+	for _, tc := range legs {
+		t.Run(tc.name, func(t *testing.T) {
+			s := noFerries
+			if tc.ferries {
+				s = withFerries
+			}
+			bearing := InitialBearing(tc.from.Lat, tc.from.Lon, tc.to.Lat, tc.to.Lon)
+			km := HaversineKm(tc.from.Lat, tc.from.Lon, tc.to.Lat, tc.to.Lon)
+			if got := s.LegBlocked(tc.from, bearing, km); got != tc.blocked {
+				t.Fatalf("LegBlocked = %v, want %v", got, tc.blocked)
+			}
+		})
+	}
+}
+
+// allWater is an obstacle layer that blocks everywhere.
+type allWater struct{}
+
+func (allWater) Blocked(latLon) bool { return true }
+
+// TestHeatmapSkipsWater checks that cells inside the obstacle layer are
+// marked Sea without a fetch: one would fail offline and show as NoData.
+func TestHeatmapSkipsWater(t *testing.T) {
+	cfg := beamConfig{KmPerDay: 50, Obstacles: &obstacleSet{layers: []obstacleLayer{allWater{}}}}
+	res := RunHeatmap(52, 5, time.Now(), 2, cfg, 5, NoProgress)
+	for d, day := range res.Cells {
+		for r, row := range day {
+			for c, cell := range row {
+				if cell != (cellStatus{Sea: true}) {
+					t.Fatalf("day %d cell %d,%d = %+v, want sea", d, r, c, cell)
+				}
+			}
+		}
+	}
+}
+
+func TestClimbPenalty(t *testing.T) {
+	tests := []struct {
+		name        string
+		climbM      float64
+		tailwindAvg float64
+		maxTemp     float64
+		want        float64
+	}{
+		{"flat", 0, -20, 30, 0},
+		{"mild tailwind", 500, 10, 20, 5},
+		{"headwind", 500, -25, 20, 7.5},
+		{"heat", 500, 0, 35, 7.5},
+		{"headwind and heat", 1000, -25, 35, 20},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := climbPenalty(tc.climbM, tc.tailwindAvg, tc.maxTemp, 1)
+			if math.Abs(got-tc.want) > 1e-9 {
+				t.Fatalf("climbPenalty(%v, %v, %v) = %v, want %v", tc.climbM, tc.tailwindAvg, tc.maxTemp, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestInitialBearing(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+	}{
+		{"due north", 52, 5, 53, 5, 0},
+		{"due south", 52, 5, 51, 5, 180},
+		{"east along equator", 0, 5, 0, 6, 90},
+		{"west along equator", 0, 5, 0, 4, 270},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := InitialBearing(tc.lat1, tc.lon1, tc.lat2, tc.lon2)
+			if math.Abs(got-tc.want) > 1e-6 {
+				t.Fatalf("InitialBearing = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDayLengths(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  beamConfig
+		want []float64
+	}{
+		{"fixed", beamConfig{KmPerDay: 100}, []float64{100}},
+		{"band", beamConfig{KmPerDay: 100, MinKm: 60, MaxKm: 140, DistanceSteps: 3}, []float64{60, 100, 140}},
+		{"band needs two steps", beamConfig{KmPerDay: 100, MinKm: 60, MaxKm: 140, DistanceSteps: 1}, []float64{100}},
+		{"equal band", beamConfig{KmPerDay: 80, MinKm: 120, MaxKm: 120, DistanceSteps: 3}, []float64{120}},
+		{"inverted band", beamConfig{KmPerDay: 80, MinKm: 140, MaxKm: 120, DistanceSteps: 3}, []float64{80}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.cfg.dayLengths()
+			if len(got) != len(tc.want) {
+				t.Fatalf("dayLengths() = %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if math.Abs(got[i]-tc.want[i]) > 1e-9 {
+					t.Fatalf("dayLengths() = %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTripDayBearing(t *testing.T) {
+	start := latLon{52, 5}
+	tests := []struct {
+		name string
+		trip beamNode
+		want float64
+	}{
+		{"unsnapped keeps the heading", beamNode{Bearings: []float64{45}, Positions: []latLon{start, {52.5, 5}}, Stops: []string{""}}, 45},
+		{"snapped rides to the stop", beamNode{Bearings: []float64{45}, Positions: []latLon{start, {52.5, 5}}, Stops: []string{"Camping"}}, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tripDayBearing(tc.trip, 0); math.Abs(got-tc.want) > 0.5 {
+				t.Fatalf("tripDayBearing() = %.1f, want %.1f", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseStopsCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []overnightStop
+		wantErr bool
+	}{
+		{
+			name: "name lat lon",
+			in:   "name,lat,lon\nCamping De Hoge Veluwe,52.05,5.86\n",
+			want: []overnightStop{{Name: "Camping De Hoge Veluwe", Pos: latLon{52.05, 5.86}}},
+		},
+		{
+			name: "reordered columns, extra column",
+			in:   "longitude,kind,latitude,name\n4.90,hostel,52.37,Stayokay\n",
+			want: []overnightStop{{Name: "Stayokay", Pos: latLon{52.37, 4.90}}},
+		},
+		{
+			name: "no name column",
+			in:   "lat,lng\n51.5,5.25\n",
+			want: []overnightStop{{Name: "51.500,5.250", Pos: latLon{51.5, 5.25}}},
+		},
+		{name: "missing lon column", in: "name,lat\nx,52\n", wantErr: true},
+		{name: "bad number", in: "name,lat,lon\nx,abc,5\n", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseStopsCSV(strings.NewReader(tc.in))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("parseStopsCSV() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseStopsCSV() error: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("parseStopsCSV() = %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("stop %d = %+v, want %+v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
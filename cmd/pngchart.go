@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// cliPNGDPI is the density of --png files: twice the screen baseline, so
+// they stay sharp on HiDPI screens and in chat clients that scale them down.
+const cliPNGDPI = 192
+
+// writePNGFile saves a chart rendered for a --png flag.
+func writePNGFile(path string, img []byte, err error) error {
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, img, 0o644); err != nil {
+		return fmt.Errorf("write png: %w", err)
+	}
+	fmt.Printf("\nChart written to %s\n", path)
+	return nil
+}
+
+// lineChart is one line chart's model, for pages and images that show more
+// than one.
+type lineChart struct {
+	Series []SVGSeries
+	Opts   SVGOpts
+}
+
+// RenderLineChartPNG draws the same chart as RenderLineChartSVG as a PNG.
+// opts.Width/Height are in viewBox units; ro.DPI scales them to pixels.
+func RenderLineChartPNG(series []SVGSeries, opts SVGOpts, ro RasterOpts) ([]byte, error) {
+	return RenderLineChartsPNG([]lineChart{{series, opts}}, ro)
+}
+
+// RenderLineChartsPNG stacks several line charts in one image, as the
+// /hourly page stacks temperature over precipitation. The image is as wide
+// as the widest chart.
+func RenderLineChartsPNG(charts []lineChart, ro RasterOpts) ([]byte, error) {
+	layouts := make([]lineChartLayout, len(charts))
+	w, h := 0, 0
+	for i, ch := range charts {
+		layouts[i] = newLineChartLayout(ch.Series, ch.Opts)
+		w = max(w, layouts[i].Opts.Width)
+		h += layouts[i].Opts.Height
+	}
+	c := newRasterCanvas(w, h, ro)
+	dy := 0.0
+	for i, ch := range charts {
+		drawLineChart(c, dy, ch.Series, layouts[i])
+		dy += float64(layouts[i].Opts.Height)
+	}
+	return c.PNG()
+}
+
+// drawLineChart mirrors RenderLineChartSVG element for element, shifted
+// down by dy; see there for the reasoning behind each layer.
+func drawLineChart(c *rasterCanvas, dy float64, series []SVGSeries, l lineChartLayout) {
+	opts := l.Opts
+	const font = 12
+	if l.Empty {
+		c.Text(float64(opts.Width)/2, dy+float64(opts.Height)/2, "no data", font, textMiddle, false, false, c.fg, 0.6)
+		return
+	}
+	padL, padT := float64(l.PadL), dy+float64(l.PadT)
+	plotW, plotH := float64(l.PlotW), float64(l.PlotH)
+	y := func(v float64) float64 { return dy + l.Y(v) }
+
+	c.Line(padL, padT, padL, padT+plotH, 1, c.fg, 0.3)
+	c.Line(padL, padT+plotH, padL+plotW, padT+plotH, 1, c.fg, 0.3)
+
+	for _, h := range opts.Highlights {
+		from, to, ok := l.Span(h)
+		if !ok {
+			continue
+		}
+		c.FillRect(l.X(from), padT, l.X(to)-l.X(from), plotH, c.parseHexColor("#f59e0b"), 0.15)
+	}
+
+	for _, ev := range opts.SunEvents {
+		if !l.Visible(ev.Time) {
+			continue
+		}
+		x := l.X(ev.Time)
+		c.Polyline([][2]float64{{x, padT}, {x, padT + plotH}}, 1, c.fg, 0.35, []float64{2, 3})
+		c.Text(x, padT-6, sunGlyph(ev.Kind)+" "+ev.Time.Format(opts.XTimeFormat), font, textMiddle, false, false, c.fg, 0.75)
+	}
+
+	for _, m := range opts.Markers {
+		if !l.Visible(m.Time) {
+			continue
+		}
+		x := l.X(m.Time)
+		c.Line(x, padT, x, padT+plotH, 1, c.fg, 0.5)
+		c.Text(x+4, padT+12, m.Label, font, textStart, false, false, c.fg, 0.75)
+	}
+
+	if opts.YUnit != "" {
+		c.Text(padL-6, padT-6, strings.TrimSpace(opts.YUnit), font, textEnd, false, false, c.fg, 0.6)
+	}
+
+	for _, v := range l.YTicks() {
+		c.Line(padL, y(v), padL+plotW, y(v), 1, c.fg, 0.1)
+		c.Text(padL-6, y(v), fmt.Sprintf(l.TickFmt, v), font, textEnd, true, false, c.fg, 0.7)
+	}
+
+	for _, t := range l.XTicks() {
+		x := l.X(t)
+		c.Line(x, padT, x, padT+plotH, 1, c.fg, 0.15)
+		c.Text(x, padT+plotH+16, t.Format(opts.XTimeFormat), font, textMiddle, false, false, c.fg, 0.7)
+	}
+
+	for _, band := range opts.Bands {
+		if len(band.Data) < 2 {
+			continue
+		}
+		poly := make([][2]float64, 0, 2*len(band.Data))
+		for _, p := range band.Data {
+			poly = append(poly, [2]float64{l.X(p.Time), y(p.Hi)})
+		}
+		for i := len(band.Data) - 1; i >= 0; i-- {
+			poly = append(poly, [2]float64{l.X(band.Data[i].Time), y(band.Data[i].Lo)})
+		}
+		c.FillPolygon(poly, c.parseHexColor(band.Color), 0.15)
+	}
+
+	yBase := padT + plotH
+	for _, s := range series {
+		if len(s.Data) == 0 {
+			continue
+		}
+		col := c.parseHexColor(s.Color)
+		pts := make([][2]float64, len(s.Data))
+		for i, p := range s.Data {
+			pts[i] = [2]float64{l.X(p.Time), y(p.Value)}
+		}
+		if opts.FillArea && !s.NoFill && len(pts) >= 2 {
+			area := append([][2]float64{{pts[0][0], yBase}}, pts...)
+			area = append(area, [2]float64{pts[len(pts)-1][0], yBase})
+			c.FillPolygon(area, col, 0.22)
+		}
+		var dash []float64
+		if s.Dashed {
+			dash = []float64{6, 4}
+		}
+		c.Polyline(pts, 2, col, 1, dash)
+	}
+}
+
+// RenderHeatGridPNG draws the same grid as RenderHeatGridSVG as a PNG.
+func RenderHeatGridPNG(cells [][]GridCell, opts GridOpts, ro RasterOpts) ([]byte, error) {
+	if len(cells) == 0 || len(cells[0]) == 0 {
+		return newRasterCanvas(1, 1, ro).PNG()
+	}
+	l := newHeatGridLayout(cells, opts)
+	opts = l.Opts
+	c := newRasterCanvas(l.W, l.H, ro)
+	const font = 11
+	cs := float64(opts.CellSize)
+	padL, padT := float64(l.PadL), float64(l.PadT)
+
+	if opts.Title != "" {
+		c.Text(padL, 14, opts.Title, font, textStart, false, true, c.fg, 1)
+	}
+	if opts.StepKm > 0 {
+		for r, txt := range l.RowLabels() {
+			c.Text(padL-4, padT+float64(r)*cs+cs/2+4, txt, font, textEnd, false, false, c.fg, 0.7)
+		}
+		km := int(float64(l.Mid) * opts.StepKm)
+		yLab := padT + float64(l.Rows)*cs + 12
+		c.Text(padL, yLab, fmt.Sprintf("-%d W", km), font, textStart, false, false, c.fg, 0.7)
+		c.Text(padL+float64(l.Cols)*cs, yLab, fmt.Sprintf("+%d E", km), font, textEnd, false, false, c.fg, 0.7)
+	}
+
+	for r := 0; r < l.Rows; r++ {
+		for col := 0; col < l.Cols; col++ {
+			cell := cells[r][col]
+			xi, yi := l.Cell(r, col)
+			x, y := float64(xi), float64(yi)
+			c.FillRect(x, y, cs, cs, c.parseHexColor(cell.Color), 1)
+			if cell.Border != "" {
+				c.Polyline([][2]float64{{x, y}, {x + cs, y}, {x + cs, y + cs}, {x, y + cs}, {x, y}}, 2, c.parseHexColor(cell.Border), 1, nil)
+			}
+			if cell.Symbol != "" {
+				sc := cell.SymbolColor
+				if sc == "" {
+					sc = "#111"
+				}
+				c.Text(x+cs/2, y+cs/2+1, cell.Symbol, cs-6, textMiddle, true, false, c.parseHexColor(sc), 1)
+			}
+		}
+	}
+
+	water := func(r, col int) bool {
+		if r < 0 || r >= l.Rows || col < 0 || col >= l.Cols {
+			return true
+		}
+		return cells[r][col].Water
+	}
+	coast := c.parseHexColor("#06b6d4")
+	for r := 0; r < l.Rows; r++ {
+		for col := 0; col < l.Cols; col++ {
+			if !cells[r][col].Water {
+				continue
+			}
+			xi, yi := l.Cell(r, col)
+			x, y := float64(xi), float64(yi)
+			if !water(r-1, col) {
+				c.Line(x, y, x+cs, y, 2, coast, 1)
+			}
+			if !water(r+1, col) {
+				c.Line(x, y+cs, x+cs, y+cs, 2, coast, 1)
+			}
+			if !water(r, col-1) {
+				c.Line(x, y, x, y+cs, 2, coast, 1)
+			}
+			if !water(r, col+1) {
+				c.Line(x+cs, y, x+cs, y+cs, 2, coast, 1)
+			}
+		}
+	}
+
+	for i := len(opts.Paths) - 1; i >= 0; i-- {
+		p := opts.Paths[i]
+		if len(p.Points) < 2 {
+			continue
+		}
+		pts := make([][2]float64, len(p.Points))
+		for j, pt := range p.Points {
+			pts[j][0], pts[j][1] = l.Point(pt)
+		}
+		width := 3.0
+		if i > 0 {
+			width = 2
+		}
+		var dash []float64
+		if p.Dashed {
+			dash = []float64{6, 4}
+		}
+		col := c.parseHexColor(p.Color)
+		c.Polyline(pts, width, col, 1, dash)
+		if p.Label != "" {
+			x, y := pts[1][0], pts[1][1]
+			c.Disc(x, y, 8, col, 1)
+			c.Text(x, y+1, p.Label, font, textMiddle, true, true, c.parseHexColor("#fff"), 1)
+		}
+	}
+	return c.PNG()
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ---------- 24-hour precipitation timeline ----------
+
+// The nowcasts end about two hours out and the hourly forecast smears the
+// next few hours into whole-hour blocks. The timeline carries the blended
+// nowcast up to its last point and hands over to Open-Meteo's 15-minute
+// model data from there, so 0–24 h reads as one line with a marked seam.
+
+const (
+	timelineHours   = 24
+	minutely15Step  = 15 * time.Minute
+	timelineNowcast = "nowcast"
+	timelineModel   = "model"
+)
+
+// PrecipTimeline is the next 24 hours of precipitation in mm/h.
+type PrecipTimeline struct {
+	// Seam is where the nowcast hands over to the model: the nowcast's last
+	// point. Nil when there was no nowcast and the model covers it all.
+	Seam   *time.Time            `json:"seam,omitempty"`
+	Points []PrecipTimelinePoint `json:"points"`
+}
+
+// PrecipTimelinePoint is one step of the timeline.
+type PrecipTimelinePoint struct {
+	Time   time.Time `json:"time"`
+	Value  float64   `json:"value"`  // mm/h
+	Source string    `json:"source"` // "nowcast" or "model"
+}
+
+// GetOpenMeteoMinutely15 fetches the next day of Open-Meteo's 15-minute
+// precipitation. Each value is the rate over the quarter hour ending at its
+// time, in mm/h like the nowcasts (the API sums mm per quarter hour). In
+// central Europe this comes from the 15-minute models; elsewhere Open-Meteo
+// interpolates the hourly run. Cached process-wide (minutely15Cache); treat
+// the result as read-only.
+func GetOpenMeteoMinutely15(lat, lon float64) ([]ForecastDataPoint, error) {
+	return memo(minutely15Cache, fmt.Sprintf("%.3f|%.3f", lat, lon), func() ([]ForecastDataPoint, error) {
+		url := fmt.Sprintf("https://api.open-meteo.com/v1/forecast?latitude=%.4f&longitude=%.4f"+
+			"&minutely_15=precipitation&forecast_minutely_15=%d&timezone=auto",
+			lat, lon, (timelineHours+1)*4)
+		body, err := openMeteoGetBody(url)
+		if err != nil {
+			return nil, fmt.Errorf("minutely_15 request: %w", err)
+		}
+		return parseMinutely15(body)
+	})
+}
+
+func parseMinutely15(raw []byte) ([]ForecastDataPoint, error) {
+	var parsed struct {
+		Timezone   string `json:"timezone"`
+		UTCOffset  int    `json:"utc_offset_seconds"`
+		Minutely15 struct {
+			Time          []string   `json:"time"`
+			Precipitation []*float64 `json:"precipitation"` // null past the model's horizon
+		} `json:"minutely_15"`
+	}
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("decode minutely_15: %w", err)
+	}
+	m := parsed.Minutely15
+	if len(m.Precipitation) != len(m.Time) {
+		return nil, fmt.Errorf("minutely_15: %d times but %d values", len(m.Time), len(m.Precipitation))
+	}
+	zone := openMeteoZone(parsed.Timezone, parsed.UTCOffset)
+	out := make([]ForecastDataPoint, 0, len(m.Time))
+	for i, s := range m.Time {
+		t, err := time.ParseInLocation("2006-01-02T15:04", s, zone)
+		if err != nil {
+			return nil, fmt.Errorf("minutely_15 time %q: %w", s, err)
+		}
+		if m.Precipitation[i] == nil {
+			continue
+		}
+		out = append(out, ForecastDataPoint{Time: t, Value: *m.Precipitation[i] * float64(time.Hour/minutely15Step)})
+	}
+	if len(out) == 0 {
+		return nil, errors.New("minutely_15: no values")
+	}
+	return out, nil
+}
+
+// stitchPrecipTimeline joins the blended nowcast to the model points after
+// its last one, up to timelineHours from now. Either side may be missing.
+func stitchPrecipTimeline(nowcast *RainFusion, model []ForecastDataPoint, now time.Time) PrecipTimeline {
+	var tl PrecipTimeline
+	end := now.Add(timelineHours * time.Hour)
+	if nowcast != nil && len(nowcast.Series) > 0 {
+		for _, p := range nowcast.Series {
+			tl.Points = append(tl.Points, PrecipTimelinePoint{Time: p.Time, Value: p.Value, Source: timelineNowcast})
+		}
+		tl.Seam = &nowcast.Series[len(nowcast.Series)-1].Time
+	}
+	// Without a nowcast the model starts with the quarter hour now falls in,
+	// the first to end after it.
+	from := now
+	if tl.Seam != nil {
+		from = *tl.Seam
+	}
+	for _, p := range model {
+		if p.Time.After(from) && !p.Time.After(end) {
+			tl.Points = append(tl.Points, PrecipTimelinePoint{Time: p.Time, Value: p.Value, Source: timelineModel})
+		}
+	}
+	return tl
+}
+
+// fetchPrecipTimeline fetches both nowcasts and the 15-minute model for loc
+// and stitches them. It fails only when all three sources do.
+func fetchPrecipTimeline(ctx context.Context, loc Location) (*PrecipTimeline, error) {
+	var (
+		alarm, radar       *Forecast
+		alarmErr, radarErr error
+		model              []ForecastDataPoint
+		modelErr           error
+		wg                 sync.WaitGroup
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		alarm, radar, alarmErr, radarErr = fetchRain(ctx, loc.Latitude, loc.Longitude, NoProgress)
+	}()
+	go func() {
+		defer wg.Done()
+		model, modelErr = GetOpenMeteoMinutely15(loc.Latitude, loc.Longitude)
+	}()
+	wg.Wait()
+	if alarm == nil && radar == nil && model == nil {
+		return nil, errors.Join(alarmErr, radarErr, modelErr)
+	}
+	if modelErr != nil {
+		slog.Debug("precip timeline: no model data", "err", modelErr)
+	}
+	tl := stitchPrecipTimeline(FuseRain(alarm, radar, currentRainTrust(ctx)), model, time.Now())
+	return &tl, nil
+}
+
+// IsDry reports whether the whole timeline stays under DryThresholdMmH.
+func (tl *PrecipTimeline) IsDry() bool {
+	for _, p := range tl.Points {
+		if p.Value >= DryThresholdMmH {
+			return false
+		}
+	}
+	return true
+}
+
+// timelineChart draws the timeline as two lines, the nowcast and the model,
+// with a marker at the seam.
+func timelineChart(tl *PrecipTimeline) lineChart {
+	nowcast := SVGSeries{Name: "Nowcast", Color: buienalarmColor}
+	model := SVGSeries{Name: "Model", Color: modelColor}
+	for _, p := range tl.Points {
+		pt := ForecastDataPoint{Time: p.Time, Value: p.Value}
+		if p.Source == timelineNowcast {
+			nowcast.Data = append(nowcast.Data, pt)
+		} else {
+			model.Data = append(model.Data, pt)
+		}
+	}
+	opts := SVGOpts{
+		YUnit:       PrecipitationForecast.Unit(),
+		XTimeFormat: "15:04",
+		MinYHi:      1,
+		FillArea:    true,
+	}
+	if tl.Seam != nil {
+		opts.Markers = []SVGMarker{{Time: *tl.Seam, Label: "model →"}}
+	}
+	return lineChart{Series: []SVGSeries{nowcast, model}, Opts: opts}
+}
+
+type precipTimelineAPIResponse struct {
+	Location Location `json:"location"`
+	PrecipTimeline
+}
+
+// handlePrecipTimelineJSON serves /api/v1/precip-timeline, the widget's
+// extended chart.
+func handlePrecipTimelineJSON(w http.ResponseWriter, r *http.Request) {
+	lat, lon, name := locationQuery(r)
+	loc, err := ResolveLocationFor(lat, lon, name)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	tl, err := fetchPrecipTimeline(r.Context(), loc)
+	if err != nil {
+		writeJSONError(w, http.StatusBadGateway, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	resp := precipTimelineAPIResponse{Location: loc, PrecipTimeline: *tl}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Log(r.Context(), LevelTrace, "encode precip timeline response", "err", err)
+	}
+}
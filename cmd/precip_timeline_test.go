@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// testMinutely15 is an Open-Meteo minutely_15 response for 14:00–15:00
+// local time, the last quarter without a value.
+var testMinutely15 = []byte(`{"timezone":"Europe/Amsterdam","utc_offset_seconds":7200,"minutely_15":{
+	"time":["2026-05-01T14:00","2026-05-01T14:15","2026-05-01T14:30","2026-05-01T14:45","2026-05-01T15:00"],
+	"precipitation":[0.1,0.2,0.0,0.5,null]}}`)
+
+func TestParseMinutely15(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     []byte
+		points  int
+		last    float64 // mm/h of the last point
+		wantErr bool
+	}{
+		{name: "null quarter skipped, mm per quarter as mm/h", raw: testMinutely15, points: 4, last: 2},
+		{name: "all null", raw: []byte(`{"timezone":"UTC","utc_offset_seconds":0,"minutely_15":{
+			"time":["2026-05-01T14:00"],"precipitation":[null]}}`), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, err := parseMinutely15(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(model) != tt.points || model[len(model)-1].Value != tt.last {
+				t.Fatalf("model = %+v, want %d points ending at %v", model, tt.points, tt.last)
+			}
+		})
+	}
+}
+
+func TestStitchPrecipTimeline(t *testing.T) {
+	model, err := parseMinutely15(testMinutely15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := model[0].Time.Add(5 * time.Minute) // 14:05
+	nowcast := &RainFusion{Series: []FusionPoint{
+		{Time: now, Value: 1}, {Time: now.Add(5 * time.Minute), Value: 1.5}, {Time: now.Add(25 * time.Minute), Value: 0.5},
+	}}
+
+	tests := []struct {
+		name    string
+		nowcast *RainFusion
+		sources string
+		seam    bool
+	}{
+		{name: "stitched after the seam", nowcast: nowcast, sources: "nowcast nowcast nowcast model", seam: true},
+		{name: "model only, from the current quarter", sources: "model model model"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := stitchPrecipTimeline(tt.nowcast, model, now)
+			var sources []string
+			for _, p := range tl.Points {
+				sources = append(sources, p.Source)
+			}
+			if got := strings.Join(sources, " "); got != tt.sources || (tl.Seam != nil) != tt.seam {
+				t.Fatalf("sources %q, seam %v", got, tl.Seam)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg" // some tile servers hand out JPEG base maps
+	"image/png"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The radar is drawn here rather than relayed: RainViewer's global radar
+// tiles are fetched raw (colour scheme 0, which encodes the reflectivity as
+// a grey level), converted to rain rates, cropped and scaled around the
+// location, and coloured with our own scale over a muted base map.
+const (
+	radarTileSize     = 256
+	radarTileMaxZoom  = 7 // RainViewer's radar tiles stop here; closer views are upscaled
+	radarMinZoom      = 3
+	radarMaxZoom      = 12
+	radarDefaultZoom  = 8
+	radarDefaultSize  = 400
+	radarFetchWorkers = 6
+
+	// rainViewerDBZOffset: in colour scheme 0 a pixel's grey level is
+	// dBZ + 32, and a transparent pixel is no echo.
+	rainViewerDBZOffset = 32
+	// radarMinDBZ is about 0.1 mm/h; weaker echoes are mostly clutter.
+	radarMinDBZ = 7
+)
+
+// RadarFrame is one radar image on a web-mercator pixel grid at Zoom: Rate
+// holds mm/h row by row, 0 where there is no echo.
+type RadarFrame struct {
+	Time    time.Time
+	Nowcast bool // extrapolated by the source rather than measured
+	Zoom    int
+	X0, Y0  int // global pixel coordinates of the top-left corner
+	W, H    int
+	Rate    []float32
+}
+
+// At returns the rain rate at frame pixel (x, y), 0 outside the frame.
+func (f *RadarFrame) At(x, y int) float32 {
+	if x < 0 || y < 0 || x >= f.W || y >= f.H {
+		return 0
+	}
+	return f.Rate[y*f.W+x]
+}
+
+// mercatorPixel returns the global web-mercator pixel position of a point
+// at zoom, as slippy-map tiles lay it out.
+func mercatorPixel(lat, lon float64, zoom int) (x, y float64) {
+	lat = math.Max(-85.05, math.Min(85.05, lat))
+	n := float64(int(radarTileSize) << zoom)
+	s := math.Sin(lat * math.Pi / 180)
+	return (lon + 180) / 360 * n, (0.5 - math.Log((1+s)/(1-s))/(4*math.Pi)) * n
+}
+
+// dbzToRate converts reflectivity to rain rate with the Marshall–Palmer
+// relation Z = 200·R^1.6.
+func dbzToRate(dbz float64) float64 {
+	return math.Pow(math.Pow(10, dbz/10)/200, 1/1.6)
+}
+
+type rainViewerFrame struct {
+	Time int64  `json:"time"`
+	Path string `json:"path"`
+}
+
+type rainViewerIndex struct {
+	Host  string `json:"host"`
+	Radar struct {
+		Past    []rainViewerFrame `json:"past"`
+		Nowcast []rainViewerFrame `json:"nowcast"`
+	} `json:"radar"`
+}
+
+func getRainViewerIndex() (*rainViewerIndex, error) {
+	url := upstreamURL("rainviewer")
+	return memo(radarIndexCache, url, func() (*rainViewerIndex, error) {
+		body, err := getUpstream(url, "rainviewer index")
+		if err != nil {
+			return nil, err
+		}
+		var idx rainViewerIndex
+		if err := json.Unmarshal(body, &idx); err != nil {
+			return nil, fmt.Errorf("decode rainviewer index: %w", err)
+		}
+		if idx.Host == "" || len(idx.Radar.Past) == 0 {
+			return nil, fmt.Errorf("rainviewer index lists no radar frames")
+		}
+		return &idx, nil
+	})
+}
+
+// getRadarTile returns one raw radar tile as grey levels (see
+// rainViewerDBZOffset), 0 for no echo. Frames never change once published,
+// so the tile cache only bounds memory.
+func getRadarTile(host, path string, z, x, y int) ([]uint8, error) {
+	url := fmt.Sprintf("%s%s/%d/%d/%d/%d/0/0_0.png", host, path, radarTileSize, z, x, y)
+	return memo(radarTileCache, url, func() ([]uint8, error) {
+		body, err := getUpstream(url, "radar tile")
+		if err != nil {
+			return nil, err
+		}
+		img, err := png.Decode(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("decode radar tile: %w", err)
+		}
+		out := make([]uint8, radarTileSize*radarTileSize)
+		b := img.Bounds()
+		for py := 0; py < radarTileSize && py < b.Dy(); py++ {
+			for px := 0; px < radarTileSize && px < b.Dx(); px++ {
+				c := color.NRGBAModel.Convert(img.At(b.Min.X+px, b.Min.Y+py)).(color.NRGBA)
+				if c.A != 0 {
+					out[py*radarTileSize+px] = c.R
+				}
+			}
+		}
+		return out, nil
+	})
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// radarWindow is the w×h pixel window at zoom centred on a point.
+func radarWindow(lat, lon float64, zoom, w, h int) (x0, y0 int) {
+	cx, cy := mercatorPixel(lat, lon, zoom)
+	return int(math.Floor(cx)) - w/2, int(math.Floor(cy)) - h/2
+}
+
+// radarZoomFor is the closest zoom at which the span between two points
+// fits in the middle two thirds of a size-pixel radar, so a route shows with
+// some weather around it.
+func radarZoomFor(a, b Location, size int) int {
+	for z := radarMaxZoom; z > radarMinZoom; z-- {
+		ax, ay := mercatorPixel(a.Latitude, a.Longitude, z)
+		bx, by := mercatorPixel(b.Latitude, b.Longitude, z)
+		if math.Max(math.Abs(ax-bx), math.Abs(ay-by)) <= float64(size)*2/3 {
+			return z
+		}
+	}
+	return radarMinZoom
+}
+
+// GetRadarFrames returns every radar frame the source has — the past
+// couple of hours and its short nowcast — cropped to a w×h window at zoom
+// centred on lat/lon. Beyond radarTileMaxZoom the radar pixels are scaled up.
+func GetRadarFrames(lat, lon float64, zoom, w, h int) ([]*RadarFrame, error) {
+	x0, y0 := radarWindow(lat, lon, zoom, w, h)
+	return getRadarFrames(x0, y0, zoom, w, h, 0)
+}
+
+// getRadarFrames crops the frames to the window at global pixel (x0, y0).
+// With measured > 0 only that many of the newest measured frames are
+// fetched, and none of the source's nowcast.
+func getRadarFrames(x0, y0, zoom, w, h, measured int) ([]*RadarFrame, error) {
+	idx, err := getRainViewerIndex()
+	if err != nil {
+		return nil, err
+	}
+	tileZoom := min(zoom, radarTileMaxZoom)
+	scale := 1 << (zoom - tileZoom)
+	world := radarTileSize << tileZoom
+	tiles := world / radarTileSize
+
+	// The tiles under the window, in tile-zoom tile coordinates.
+	tx0 := floorDiv(floorDiv(x0, scale), radarTileSize)
+	tx1 := floorDiv(floorDiv(x0+w-1, scale), radarTileSize)
+	ty0 := max(0, floorDiv(floorDiv(y0, scale), radarTileSize))
+	ty1 := min(tiles-1, floorDiv(floorDiv(y0+h-1, scale), radarTileSize))
+
+	type frameSrc struct {
+		rainViewerFrame
+		nowcast bool
+	}
+	var srcs []frameSrc
+	past := idx.Radar.Past
+	if measured > 0 && len(past) > measured {
+		past = past[len(past)-measured:]
+	}
+	for _, f := range past {
+		srcs = append(srcs, frameSrc{f, false})
+	}
+	if measured == 0 {
+		for _, f := range idx.Radar.Nowcast {
+			srcs = append(srcs, frameSrc{f, true})
+		}
+	}
+
+	type tileRef struct{ frame, tx, ty int }
+	got := map[tileRef][]uint8{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var lastErr error
+	sem := make(chan struct{}, radarFetchWorkers)
+	for fi, src := range srcs {
+		for tx := tx0; tx <= tx1; tx++ {
+			for ty := ty0; ty <= ty1; ty++ {
+				wg.Add(1)
+				go func(fi, tx, ty int, path string) {
+					defer wg.Done()
+					sem <- struct{}{}
+					defer func() { <-sem }()
+					tile, err := getRadarTile(idx.Host, path, tileZoom, ((tx%tiles)+tiles)%tiles, ty)
+					mu.Lock()
+					defer mu.Unlock()
+					if err != nil {
+						lastErr = err
+						slog.Debug("radar: tile failed", "z", tileZoom, "x", tx, "y", ty, "err", err)
+						return
+					}
+					got[tileRef{fi, tx, ty}] = tile
+				}(fi, tx, ty, src.Path)
+			}
+		}
+	}
+	wg.Wait()
+
+	// A frame with a tile missing would show that tile as dry, which reads
+	// as real weather; leave the frame out instead.
+	frames := make([]*RadarFrame, 0, len(srcs))
+	for fi, src := range srcs {
+		complete := true
+		for tx := tx0; tx <= tx1 && complete; tx++ {
+			for ty := ty0; ty <= ty1; ty++ {
+				if got[tileRef{fi, tx, ty}] == nil {
+					complete = false
+					break
+				}
+			}
+		}
+		if !complete {
+			slog.Debug("radar: dropping incomplete frame", "time", time.Unix(src.Time, 0))
+			continue
+		}
+		f := &RadarFrame{
+			Time:    time.Unix(src.Time, 0),
+			Nowcast: src.nowcast,
+			Zoom:    zoom,
+			X0:      x0, Y0: y0, W: w, H: h,
+			Rate: make([]float32, w*h),
+		}
+		for y := 0; y < h; y++ {
+			py := floorDiv(y0+y, scale)
+			if py < 0 || py >= world {
+				continue
+			}
+			for x := 0; x < w; x++ {
+				px := floorDiv(x0+x, scale)
+				tile := got[tileRef{fi, floorDiv(px, radarTileSize), floorDiv(py, radarTileSize)}]
+				if tile == nil {
+					continue
+				}
+				v := tile[(py%radarTileSize)*radarTileSize+((px%radarTileSize)+radarTileSize)%radarTileSize]
+				if dbz := int(v) - rainViewerDBZOffset; v != 0 && dbz >= radarMinDBZ {
+					f.Rate[y*w+x] = float32(dbzToRate(float64(dbz)))
+				}
+			}
+		}
+		frames = append(frames, f)
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("radar tiles: %w", lastErr)
+	}
+	return frames, nil
+}
+
+// getRadarBasemap returns the base map under a radar window as grey levels,
+// lightened so the rain colours stand out. Base tiles are fetched at zoom
+// itself; the window is cached whole, since the same few views are asked
+// for over and over. A window with a tile missing is returned, plain grey
+// where the tile failed, but not cached, so the next view tries again.
+func getRadarBasemap(zoom, x0, y0, w, h int) (*image.Gray, error) {
+	tmpl := upstreamURL("basemap")
+	key := fmt.Sprintf("%s|%d|%d|%d|%d|%d", tmpl, zoom, x0, y0, w, h)
+	if img, ok := radarBaseCache.get(key); ok {
+		return img, nil
+	}
+	img, complete, err := fetchRadarBasemap(tmpl, zoom, x0, y0, w, h)
+	if err != nil {
+		return nil, err
+	}
+	if complete {
+		radarBaseCache.put(key, img)
+	}
+	return img, nil
+}
+
+// fetchRadarBasemap draws the base map window from tmpl's tiles and reports
+// whether every tile came through. It fails only when none did.
+func fetchRadarBasemap(tmpl string, zoom, x0, y0, w, h int) (*image.Gray, bool, error) {
+	out := image.NewGray(image.Rect(0, 0, w, h))
+	for i := range out.Pix {
+		out.Pix[i] = 235
+	}
+	tiles := 1 << zoom
+	ok := 0
+	var lastErr error
+	for ty := max(0, floorDiv(y0, radarTileSize)); ty <= min(tiles-1, floorDiv(y0+h-1, radarTileSize)); ty++ {
+		for tx := floorDiv(x0, radarTileSize); tx <= floorDiv(x0+w-1, radarTileSize); tx++ {
+			url := strings.NewReplacer("{z}", fmt.Sprint(zoom), "{x}", fmt.Sprint(((tx%tiles)+tiles)%tiles), "{y}", fmt.Sprint(ty)).Replace(tmpl)
+			body, err := getUpstream(url, "base map tile")
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			img, _, err := image.Decode(bytes.NewReader(body))
+			if err != nil {
+				lastErr = fmt.Errorf("decode base map tile: %w", err)
+				continue
+			}
+			ok++
+			b := img.Bounds()
+			for py := 0; py < radarTileSize; py++ {
+				y := ty*radarTileSize + py - y0
+				if y < 0 || y >= h {
+					continue
+				}
+				for px := 0; px < radarTileSize; px++ {
+					x := tx*radarTileSize + px - x0
+					if x < 0 || x >= w {
+						continue
+					}
+					g := color.GrayModel.Convert(img.At(b.Min.X+px, b.Min.Y+py)).(color.Gray)
+					// Squash into the light end: roads and labels stay
+					// readable without competing with the rain.
+					out.Pix[y*out.Stride+x] = uint8(110 + int(g.Y)*140/255)
+				}
+			}
+		}
+	}
+	if ok == 0 && lastErr != nil {
+		return nil, false, lastErr
+	}
+	if lastErr != nil {
+		slog.Debug("radar: base map incomplete, not caching", "zoom", zoom, "err", lastErr)
+	}
+	return out, lastErr == nil, nil
+}
@@ -0,0 +1,402 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Rain-cell motion from consecutive radar frames. Buienalarm and Buienradar
+// only answer for the point asked about; tracking how the echoes move lets
+// us extrapolate the latest frame to any point on the map — the today grid,
+// a route, a place outside the providers' coverage.
+//
+// Motion is found by block matching: each block of the newest frame is
+// compared against shifted copies of the frame before, and the shift with
+// the least difference is that block's displacement. Blocks with too little
+// rain to match are filled in from their neighbours. The nowcast then traces
+// each point back along the motion field to the newest frame (semi-Lagrangian
+// advection); intensities are carried over as they are — cells don't grow or
+// decay, which is what limits the skill beyond an hour or so.
+const (
+	radarMotionZoom   = radarTileMaxZoom // the radar's native resolution; upscaling adds nothing to track
+	radarMotionSize   = 512              // px; ≈385 km across at 52°N, room for two hours of upwind cells
+	radarMotionSnap   = 64               // px; nearby requests share one analysis
+	radarMotionFrames = 4                // newest measured frames used: three pairs to average
+	radarMotionBlock  = 32               // px per motion vector
+	radarMotionMaxKmh = 120              // fastest cell motion searched for
+	radarMotionMinWet = 24               // wet (sub-sampled) pixels a block needs to be tracked
+	radarMotionCapMmH = 20               // matching on capped rates keeps one downpour core from dominating
+
+	radarNowcastHorizon = 2 * time.Hour
+	radarNowcastStep    = 5 * time.Minute
+	radarAdvectStepMin  = 10 // minutes per back-trajectory step
+)
+
+// RadarMotion is a field of motion vectors over a frame, one per block of
+// radarMotionBlock pixels, in pixels per minute (x east, y south).
+type RadarMotion struct {
+	Cols, Rows int
+	U, V       []float64
+	Tracked    int // blocks with enough rain to match; the rest are filled in
+}
+
+// VelocityAt interpolates the motion at frame pixel (x, y) between block
+// centres.
+func (m *RadarMotion) VelocityAt(x, y float64) (u, v float64) {
+	gx := math.Max(0, math.Min(float64(m.Cols-1), x/radarMotionBlock-0.5))
+	gy := math.Max(0, math.Min(float64(m.Rows-1), y/radarMotionBlock-0.5))
+	c0, r0 := int(gx), int(gy)
+	c1, r1 := min(c0+1, m.Cols-1), min(r0+1, m.Rows-1)
+	fx, fy := gx-float64(c0), gy-float64(r0)
+	at := func(s []float64) float64 {
+		top := s[r0*m.Cols+c0]*(1-fx) + s[r0*m.Cols+c1]*fx
+		bot := s[r1*m.Cols+c0]*(1-fx) + s[r1*m.Cols+c1]*fx
+		return top*(1-fy) + bot*fy
+	}
+	return at(m.U), at(m.V)
+}
+
+// radarPixelKm is the ground size of one mercator pixel at lat and zoom.
+func radarPixelKm(lat float64, zoom int) float64 {
+	return 40075.016686 * math.Cos(lat*math.Pi/180) / float64(int(radarTileSize)<<zoom)
+}
+
+// mercatorLatLon is the inverse of mercatorPixel.
+func mercatorLatLon(x, y float64, zoom int) (lat, lon float64) {
+	n := float64(int(radarTileSize) << zoom)
+	lon = x/n*360 - 180
+	lat = math.Atan(math.Sinh(math.Pi*(1-2*y/n))) * 180 / math.Pi
+	return lat, lon
+}
+
+// EstimateMotion derives the motion field from frames, oldest first, all on
+// the same window. Each consecutive pair gives one estimate per block; they
+// are averaged, gaps filled from tracked neighbours (or the overall mean),
+// and the field smoothed once so the back-trajectories don't jitter.
+func EstimateMotion(frames []*RadarFrame) *RadarMotion {
+	last := frames[len(frames)-1]
+	m := &RadarMotion{
+		Cols: (last.W + radarMotionBlock - 1) / radarMotionBlock,
+		Rows: (last.H + radarMotionBlock - 1) / radarMotionBlock,
+	}
+	n := m.Cols * m.Rows
+	sumU, sumV, cnt := make([]float64, n), make([]float64, n), make([]int, n)
+	lat, _ := mercatorLatLon(float64(last.X0+last.W/2), float64(last.Y0+last.H/2), last.Zoom)
+	pxKm := radarPixelKm(lat, last.Zoom)
+
+	for i := 1; i < len(frames); i++ {
+		prev, next := frames[i-1], frames[i]
+		dt := next.Time.Sub(prev.Time).Minutes()
+		if dt <= 0 {
+			continue
+		}
+		search := min(24, int(math.Ceil(radarMotionMaxKmh*dt/60/pxKm)))
+		for r := 0; r < m.Rows; r++ {
+			for c := 0; c < m.Cols; c++ {
+				dx, dy, ok := matchBlock(prev, next, c*radarMotionBlock, r*radarMotionBlock, search)
+				if !ok {
+					continue
+				}
+				k := r*m.Cols + c
+				sumU[k] += float64(dx) / dt
+				sumV[k] += float64(dy) / dt
+				cnt[k]++
+			}
+		}
+	}
+
+	m.U, m.V = make([]float64, n), make([]float64, n)
+	var meanU, meanV float64
+	for k := range cnt {
+		if cnt[k] > 0 {
+			m.U[k], m.V[k] = sumU[k]/float64(cnt[k]), sumV[k]/float64(cnt[k])
+			meanU += m.U[k]
+			meanV += m.V[k]
+			m.Tracked++
+		}
+	}
+	if m.Tracked == 0 {
+		return m // no rain to track: a still field, and nothing to move anyway
+	}
+	meanU /= float64(m.Tracked)
+	meanV /= float64(m.Tracked)
+	filled := func(u, v []float64) ([]float64, []float64) {
+		ou, ov := make([]float64, n), make([]float64, n)
+		for r := 0; r < m.Rows; r++ {
+			for c := 0; c < m.Cols; c++ {
+				k := r*m.Cols + c
+				if cnt[k] > 0 {
+					ou[k], ov[k] = u[k], v[k]
+					continue
+				}
+				var su, sv float64
+				var sn int
+				for dr := -2; dr <= 2; dr++ {
+					for dc := -2; dc <= 2; dc++ {
+						rr, cc := r+dr, c+dc
+						if rr < 0 || cc < 0 || rr >= m.Rows || cc >= m.Cols || cnt[rr*m.Cols+cc] == 0 {
+							continue
+						}
+						su += u[rr*m.Cols+cc]
+						sv += v[rr*m.Cols+cc]
+						sn++
+					}
+				}
+				if sn > 0 {
+					ou[k], ov[k] = su/float64(sn), sv/float64(sn)
+				} else {
+					ou[k], ov[k] = meanU, meanV
+				}
+			}
+		}
+		return ou, ov
+	}
+	u, v := filled(m.U, m.V)
+	for r := 0; r < m.Rows; r++ {
+		for c := 0; c < m.Cols; c++ {
+			var su, sv float64
+			var sn int
+			for dr := -1; dr <= 1; dr++ {
+				for dc := -1; dc <= 1; dc++ {
+					rr, cc := r+dr, c+dc
+					if rr < 0 || cc < 0 || rr >= m.Rows || cc >= m.Cols {
+						continue
+					}
+					su += u[rr*m.Cols+cc]
+					sv += v[rr*m.Cols+cc]
+					sn++
+				}
+			}
+			m.U[r*m.Cols+c], m.V[r*m.Cols+c] = su/float64(sn), sv/float64(sn)
+		}
+	}
+	return m
+}
+
+// matchBlock finds the shift (dx, dy) within ±search that best maps the
+// block at (bx, by) in next back onto prev: next(p) ≈ prev(p − d). The search
+// is coarse-to-fine on every other pixel; a tiny penalty on the shift's
+// length settles ties (featureless rain shields) towards no motion.
+func matchBlock(prev, next *RadarFrame, bx, by, search int) (dx, dy int, ok bool) {
+	capped := func(f *RadarFrame, x, y int) float64 {
+		return math.Min(float64(f.At(x, y)), radarMotionCapMmH)
+	}
+	wet := 0
+	for y := by; y < by+radarMotionBlock; y += 2 {
+		for x := bx; x < bx+radarMotionBlock; x += 2 {
+			if float64(next.At(x, y)) >= DryThresholdMmH {
+				wet++
+			}
+		}
+	}
+	if wet < radarMotionMinWet {
+		return 0, 0, false
+	}
+	cost := func(dx, dy int) float64 {
+		var s float64
+		for y := by; y < by+radarMotionBlock; y += 2 {
+			for x := bx; x < bx+radarMotionBlock; x += 2 {
+				s += math.Abs(capped(next, x, y) - capped(prev, x-dx, y-dy))
+			}
+		}
+		return s + 1e-3*float64(dx*dx+dy*dy)
+	}
+	best := math.Inf(1)
+	try := func(x, y int) {
+		if x < -search || x > search || y < -search || y > search {
+			return
+		}
+		if c := cost(x, y); c < best {
+			best, dx, dy = c, x, y
+		}
+	}
+	for y := -search; y <= search; y += 3 {
+		for x := -search; x <= search; x += 3 {
+			try(x, y)
+		}
+	}
+	cx, cy := dx, dy
+	for y := cy - 2; y <= cy+2; y++ {
+		for x := cx - 2; x <= cx+2; x++ {
+			try(x, y)
+		}
+	}
+	return dx, dy, true
+}
+
+// RadarNowcast extrapolates the newest measured frame along the motion field.
+type RadarNowcast struct {
+	Latest *RadarFrame
+	Motion *RadarMotion
+}
+
+// RateAt is the extrapolated rain rate in mm/h at a point and time, false
+// when the point or its back-trajectory leaves the analysed window.
+func (n *RadarNowcast) RateAt(lat, lon float64, t time.Time) (float64, bool) {
+	f := n.Latest
+	gx, gy := mercatorPixel(lat, lon, f.Zoom)
+	x, y := gx-float64(f.X0), gy-float64(f.Y0)
+	inside := func() bool { return x >= 0 && y >= 0 && x < float64(f.W) && y < float64(f.H) }
+	if !inside() {
+		return 0, false
+	}
+	left := max(0, t.Sub(f.Time).Minutes())
+	for left > 0 {
+		step := math.Min(left, radarAdvectStepMin)
+		u, v := n.Motion.VelocityAt(x, y)
+		// Midpoint rule: the velocity half a step back bends the trajectory
+		// with the field instead of overshooting curves.
+		u, v = n.Motion.VelocityAt(x-u*step/2, y-v*step/2)
+		x, y = x-u*step, y-v*step
+		left -= step
+		if !inside() {
+			return 0, false
+		}
+	}
+	// A 3×3 mean (≈2 km at the analysis zoom) softens single-pixel speckle.
+	var s float64
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			s += float64(f.At(int(x)+dx, int(y)+dy))
+		}
+	}
+	return s / 9, true
+}
+
+// MotionAt is the cell motion at a point as km/h and the compass bearing
+// the rain is heading towards.
+func (n *RadarNowcast) MotionAt(lat, lon float64) (kmh, towardDeg float64) {
+	f := n.Latest
+	gx, gy := mercatorPixel(lat, lon, f.Zoom)
+	u, v := n.Motion.VelocityAt(gx-float64(f.X0), gy-float64(f.Y0))
+	kmh = math.Hypot(u, v) * 60 * radarPixelKm(lat, f.Zoom)
+	towardDeg = math.Mod(math.Atan2(u, -v)*180/math.Pi+360, 360)
+	return kmh, towardDeg
+}
+
+// Forecast samples the nowcast at a point every radarNowcastStep from now
+// to the horizon, as a rain-chart source alongside the point providers.
+func (n *RadarNowcast) Forecast(lat, lon float64, now time.Time) *Forecast {
+	fc := &Forecast{Type: PrecipitationForecast}
+	start := now.Truncate(radarNowcastStep)
+	for t := start; !t.After(start.Add(radarNowcastHorizon)); t = t.Add(radarNowcastStep) {
+		rate, ok := n.RateAt(lat, lon, t)
+		if !ok {
+			break
+		}
+		fc.Data = append(fc.Data, ForecastDataPoint{Time: t, Value: math.Round(rate*100) / 100})
+	}
+	fc.Desc = radarNowcastDesc(fc.Data)
+	if n.Motion.Tracked > 0 {
+		if kmh, to := n.MotionAt(lat, lon); kmh >= 5 {
+			fc.Desc += fmt.Sprintf(" · cells heading %s at %.0f km/h", CompassName(to), kmh)
+		}
+	}
+	return fc
+}
+
+// radarNowcastDesc sums a nowcast up the way Buienalarm's headline does:
+// when the rain arrives or stops, and how hard it gets.
+func radarNowcastDesc(data []ForecastDataPoint) string {
+	if len(data) == 0 {
+		return "Outside the radar window"
+	}
+	peak := 0.0
+	for _, p := range data {
+		peak = math.Max(peak, p.Value)
+	}
+	wet := func(p ForecastDataPoint) bool { return p.Value >= DryThresholdMmH }
+	if wet(data[0]) {
+		for _, p := range data[1:] {
+			if !wet(p) {
+				return fmt.Sprintf("Raining, easing around %s", p.Time.Format("15:04"))
+			}
+		}
+		return fmt.Sprintf("Rain throughout, up to %.1f mm/h", peak)
+	}
+	for _, p := range data[1:] {
+		if wet(p) {
+			return fmt.Sprintf("Rain arriving around %s, up to %.1f mm/h", p.Time.Format("15:04"), peak)
+		}
+	}
+	return "No rain heading this way"
+}
+
+// GetRadarNowcast analyses the radar around lat/lon. The window is snapped
+// to radarMotionSnap pixels so requests for nearby points — every cell of
+// the today grid — share one analysis, and the analysis is keyed by the
+// newest radar frame, so it's redone exactly when a new frame is published.
+func GetRadarNowcast(lat, lon float64) (*RadarNowcast, error) {
+	idx, err := getRainViewerIndex()
+	if err != nil {
+		return nil, err
+	}
+	cx, cy := mercatorPixel(lat, lon, radarMotionZoom)
+	x0 := int(math.Round(cx/radarMotionSnap))*radarMotionSnap - radarMotionSize/2
+	y0 := int(math.Round(cy/radarMotionSnap))*radarMotionSnap - radarMotionSize/2
+	key := fmt.Sprintf("%d|%d|%d", x0, y0, idx.Radar.Past[len(idx.Radar.Past)-1].Time)
+	return memo(radarNowcastCache, key, func() (*RadarNowcast, error) {
+		frames, err := getRadarFrames(x0, y0, radarMotionZoom, radarMotionSize, radarMotionSize, radarMotionFrames)
+		if err != nil {
+			return nil, err
+		}
+		if len(frames) < 2 {
+			return nil, fmt.Errorf("radar nowcast: need two frames, have %d", len(frames))
+		}
+		return &RadarNowcast{Latest: frames[len(frames)-1], Motion: EstimateMotion(frames)}, nil
+	})
+}
+
+// GetRadarNowcastForecast is the radar-motion rain forecast for a point.
+func GetRadarNowcastForecast(lat, lon float64) (*Forecast, error) {
+	n, err := GetRadarNowcast(lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	return n.Forecast(lat, lon, time.Now()), nil
+}
+
+// blendRadarNowcast returns hourly with precipitation replaced by the
+// radar nowcast where the two overlap. An hour only partly inside the
+// nowcast keeps the model's share for the rest; points the nowcast can't
+// reach keep the model throughout.
+func blendRadarNowcast(hourly []HourlyForecast, n *RadarNowcast, lat, lon float64) []HourlyForecast {
+	from := n.Latest.Time
+	to := from.Add(radarNowcastHorizon)
+	out := make([]HourlyForecast, len(hourly))
+	copy(out, hourly)
+	for i, h := range out {
+		start, end := h.Time, h.Time.Add(time.Hour)
+		if !end.After(from) || !start.Before(to) {
+			continue
+		}
+		lo, hi := start, end
+		if lo.Before(from) {
+			lo = from
+		}
+		if hi.After(to) {
+			hi = to
+		}
+		var sum float64
+		var cnt int
+		ok := true
+		for t := lo; t.Before(hi); t = t.Add(radarNowcastStep) {
+			r, in := n.RateAt(lat, lon, t)
+			if !in {
+				ok = false
+				break
+			}
+			sum += r
+			cnt++
+		}
+		if !ok || cnt == 0 {
+			continue
+		}
+		covered := hi.Sub(lo).Hours()
+		// Mean rate over the covered part is mm over it; the model's hourly
+		// total stands in for the rest of the hour.
+		out[i].Precipitation = math.Round((sum/float64(cnt)*covered+h.Precipitation*(1-covered))*100) / 100
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testDriftingCell is a textured 40 px cell drifting 6 px east per
+// 10-minute frame on the analysis grid around Amsterdam, and a point 30 px
+// downwind of the cell's leading edge in the newest frame.
+func testDriftingCell(t *testing.T) (n *RadarNowcast, lat, lon float64) {
+	t.Helper()
+	const size, blobX, blobY, blob, step = 160, 20, 60, 40, 6
+	gx, gy := mercatorPixel(52.37, 4.90, radarMotionZoom)
+	x0, y0 := int(gx)-size/2, int(gy)-size/2
+	t0 := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	var frames []*RadarFrame
+	for i := 0; i < radarMotionFrames; i++ {
+		f := &RadarFrame{Time: t0.Add(time.Duration(i) * 10 * time.Minute), Zoom: radarMotionZoom,
+			X0: x0, Y0: y0, W: size, H: size, Rate: make([]float32, size*size)}
+		for y := 0; y < blob; y++ {
+			for x := 0; x < blob; x++ {
+				f.Rate[(blobY+y)*size+blobX+i*step+x] = float32(1 + 0.1*float64(x+2*y))
+			}
+		}
+		frames = append(frames, f)
+	}
+	n = &RadarNowcast{Latest: frames[len(frames)-1], Motion: EstimateMotion(frames)}
+	if n.Motion.Tracked == 0 {
+		t.Fatal("no blocks tracked")
+	}
+	u, v := n.Motion.VelocityAt(blobX+3*step+blob/2, blobY+blob/2)
+	if math.Abs(u-0.6) > 0.1 || math.Abs(v) > 0.1 {
+		t.Fatalf("motion = (%.2f, %.2f) px/min, want (0.6, 0)", u, v)
+	}
+	lat, lon = mercatorLatLon(float64(x0+blobX+3*step+blob+30), float64(y0+blobY+blob/2), radarMotionZoom)
+	return n, lat, lon
+}
+
+func TestRadarNowcastRateAt(t *testing.T) {
+	n, lat, lon := testDriftingCell(t)
+	// The cell's edge reaches the point in about 50 minutes.
+	tests := []struct {
+		name  string
+		after time.Duration
+		wet   bool
+	}{
+		{name: "dry before the cell", after: 30 * time.Minute, wet: false},
+		{name: "rain once it arrives", after: 70 * time.Minute, wet: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := n.RateAt(lat, lon, n.Latest.Time.Add(tt.after))
+			if !ok || (tt.wet && r < 1) || (!tt.wet && r > 0) {
+				t.Fatalf("rate at +%v = %.2f (ok %v), want wet %v", tt.after, r, ok, tt.wet)
+			}
+		})
+	}
+}
+
+func TestRadarNowcastForecast(t *testing.T) {
+	n, lat, lon := testDriftingCell(t)
+	fc := n.Forecast(lat, lon, n.Latest.Time)
+	if !strings.HasPrefix(fc.Desc, "Rain arriving around 13:") || !strings.Contains(fc.Desc, "heading E") {
+		t.Fatalf("desc = %q", fc.Desc)
+	}
+}
+
+func TestBlendRadarNowcast(t *testing.T) {
+	n, lat, lon := testDriftingCell(t)
+	hour := n.Latest.Time.Truncate(time.Hour)
+	hourly := []HourlyForecast{{Time: hour}, {Time: hour.Add(time.Hour)}}
+	blended := blendRadarNowcast(hourly, n, lat, lon)
+	if blended[0].Precipitation != 0 || blended[1].Precipitation <= 0 || hourly[1].Precipitation != 0 {
+		t.Fatalf("blended = %.2f, %.2f; want dry then wet, input untouched", blended[0].Precipitation, blended[1].Precipitation)
+	}
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"math"
+)
+
+// radarStop is one step of the rain colour scale: rates at or above MinMmH
+// (and below the next stop) get Color.
+type radarStop struct {
+	MinMmH float64
+	Color  color.RGBA
+	Label  string
+}
+
+// radarScale runs from drizzle to cloudburst: blues for rain you can ride
+// through, purples for a soaking, red and yellow for the rare downpour.
+var radarScale = []radarStop{
+	{0.1, color.RGBA{0x9b, 0xd9, 0xf2, 0xff}, "0.1"},
+	{0.5, color.RGBA{0x5a, 0xb4, 0xe6, 0xff}, "0.5"},
+	{1, color.RGBA{0x2a, 0x7f, 0xd4, 0xff}, "1"},
+	{2, color.RGBA{0x1f, 0x4f, 0xb0, 0xff}, "2"},
+	{5, color.RGBA{0x7c, 0x3a, 0xed, 0xff}, "5"},
+	{10, color.RGBA{0xc0, 0x26, 0xd3, 0xff}, "10"},
+	{20, color.RGBA{0xef, 0x44, 0x44, 0xff}, "20"},
+	{50, color.RGBA{0xfa, 0xcc, 0x15, 0xff}, "50"},
+}
+
+// radarKeyItem is one swatch of the rain scale for a page's legend.
+type radarKeyItem struct {
+	Color string // "#rrggbb"
+	Label string
+}
+
+// radarKey lists the rain scale for HTML legends, since the GIF has none.
+func radarKey() []radarKeyItem {
+	out := make([]radarKeyItem, len(radarScale))
+	for i, s := range radarScale {
+		out[i] = radarKeyItem{fmt.Sprintf("#%02x%02x%02x", s.Color.R, s.Color.G, s.Color.B), s.Label}
+	}
+	return out
+}
+
+// Palette layout for the radar GIF: a grey ramp for the base map, then the
+// rain scale, then the few overlay colours.
+const radarGreys = 64
+
+var (
+	radarMarkerDark  = color.RGBA{0x11, 0x11, 0x11, 0xff}
+	radarMarkerLight = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	radarRouteColor  = color.RGBA{0xf9, 0x73, 0x16, 0xff} // tempColor's orange, unused by the rain scale
+	radarPastColor   = color.RGBA{0x9c, 0xa3, 0xaf, 0xff}
+	radarFutureColor = color.RGBA{0x06, 0xb6, 0xd4, 0xff} // Buienalarm cyan: a forecast, not a measurement
+)
+
+func radarPalette() color.Palette {
+	p := make(color.Palette, 0, radarGreys+len(radarScale)+5)
+	for i := 0; i < radarGreys; i++ {
+		v := uint8(i * 255 / (radarGreys - 1))
+		p = append(p, color.RGBA{v, v, v, 0xff})
+	}
+	for _, s := range radarScale {
+		p = append(p, s.Color)
+	}
+	return append(p, radarMarkerDark, radarMarkerLight, radarRouteColor, radarPastColor, radarFutureColor)
+}
+
+// Palette indexes of the overlay colours, after the greys and the scale.
+var (
+	radarIdxDark   = uint8(radarGreys + len(radarScale))
+	radarIdxLight  = radarIdxDark + 1
+	radarIdxRoute  = radarIdxDark + 2
+	radarIdxPast   = radarIdxDark + 3
+	radarIdxFuture = radarIdxDark + 4
+)
+
+// radarScaleIndex is the palette index for a rain rate, or -1 below the
+// lowest stop.
+func radarScaleIndex(mmh float32) int {
+	idx := -1
+	for i, s := range radarScale {
+		if float64(mmh) >= s.MinMmH {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return -1
+	}
+	return radarGreys + idx
+}
+
+// radarTimelineH is the strip under the map showing which frame is up:
+// grey for measured frames, cyan for the nowcast, the current one full
+// height.
+const radarTimelineH = 6
+
+// renderRadarGIF animates frames over base (nil for a plain background),
+// with a marker at the window's centre and route, given in global pixels at
+// the frames' zoom, drawn on top.
+func renderRadarGIF(frames []*RadarFrame, base *image.Gray, route [][2]float64) *gif.GIF {
+	out := &gif.GIF{LoopCount: 0}
+	if len(frames) == 0 {
+		return out
+	}
+	pal := radarPalette()
+	w, h := frames[0].W, frames[0].H
+	x0, y0 := frames[0].X0, frames[0].Y0
+	last := len(frames) - 1
+	for i, f := range frames {
+		if !f.Nowcast {
+			last = i // the newest measured frame is "now"
+		}
+	}
+
+	for i, f := range frames {
+		img := image.NewPaletted(image.Rect(0, 0, w, h+radarTimelineH), pal)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				g := uint8(235)
+				if base != nil {
+					g = base.GrayAt(x, y).Y
+				}
+				ci := uint8(int(g) * (radarGreys - 1) / 255)
+				if si := radarScaleIndex(f.At(x, y)); si >= 0 {
+					ci = uint8(si)
+				}
+				img.SetColorIndex(x, y, ci)
+			}
+		}
+		for j := 1; j < len(route); j++ {
+			a, b := route[j-1], route[j]
+			drawRadarLine(img, a[0]-float64(x0), a[1]-float64(y0), b[0]-float64(x0), b[1]-float64(y0), radarIdxRoute)
+		}
+		drawRadarMarker(img, w/2, h/2)
+
+		// Timeline: one cell per frame along the bottom strip.
+		for x := 0; x < w; x++ {
+			fi := x * len(frames) / w
+			ci := radarIdxPast
+			if frames[fi].Nowcast {
+				ci = radarIdxFuture
+			}
+			top := h + radarTimelineH/2
+			if fi == i {
+				top = h
+			}
+			for y := h; y < h+radarTimelineH; y++ {
+				if y >= top {
+					img.SetColorIndex(x, y, ci)
+				} else {
+					img.SetColorIndex(x, y, radarIdxLight)
+				}
+			}
+		}
+
+		delay := 50
+		switch i {
+		case last:
+			delay = 150 // linger on "now"
+		case len(frames) - 1:
+			delay = 200
+		}
+		out.Image = append(out.Image, img)
+		out.Delay = append(out.Delay, delay)
+	}
+	return out
+}
+
+// drawRadarMarker draws the "you are here" dot: light centre, dark ring.
+func drawRadarMarker(img *image.Paletted, cx, cy int) {
+	for dy := -6; dy <= 6; dy++ {
+		for dx := -6; dx <= 6; dx++ {
+			d := math.Hypot(float64(dx), float64(dy))
+			switch {
+			case d <= 3:
+				img.SetColorIndex(cx+dx, cy+dy, radarIdxLight)
+			case d <= 5.5:
+				img.SetColorIndex(cx+dx, cy+dy, radarIdxDark)
+			}
+		}
+	}
+}
+
+// drawRadarLine draws a 2-pixel-wide line between two window positions.
+func drawRadarLine(img *image.Paletted, x1, y1, x2, y2 float64, ci uint8) {
+	steps := int(math.Ceil(math.Max(math.Abs(x2-x1), math.Abs(y2-y1))))
+	if steps == 0 {
+		steps = 1
+	}
+	r := img.Bounds()
+	for s := 0; s <= steps; s++ {
+		t := float64(s) / float64(steps)
+		x := int(math.Round(x1 + (x2-x1)*t))
+		y := int(math.Round(y1 + (y2-y1)*t))
+		for _, d := range [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+			p := image.Pt(x+d[0], y+d[1])
+			if p.In(r) && p.Y < r.Max.Y-radarTimelineH {
+				img.SetColorIndex(p.X, p.Y, ci)
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import "testing"
+
+func TestMercatorPixel(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		zoom     int
+		tx, ty   int
+	}{
+		// Amsterdam sits in slippy tile 8/131/84.
+		{name: "amsterdam", lat: 52.37, lon: 4.90, zoom: 8, tx: 131, ty: 84},
+		{name: "null island", lat: 0, lon: 0, zoom: 1, tx: 1, ty: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := mercatorPixel(tt.lat, tt.lon, tt.zoom)
+			if tx, ty := int(x)/radarTileSize, int(y)/radarTileSize; tx != tt.tx || ty != tt.ty {
+				t.Fatalf("tile = %d/%d, want %d/%d", tx, ty, tt.tx, tt.ty)
+			}
+		})
+	}
+}
+
+func TestDBZToRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		dbz    float64
+		lo, hi float64
+	}{
+		{name: "light rain", dbz: 23, lo: 0.9, hi: 1.1},
+		{name: "heavy rain", dbz: 40, lo: 11, hi: 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if r := dbzToRate(tt.dbz); r < tt.lo || r > tt.hi {
+				t.Fatalf("dbzToRate(%v) = %.2f, want %.1f–%.1f mm/h", tt.dbz, r, tt.lo, tt.hi)
+			}
+		})
+	}
+}
+
+func TestFloorDiv(t *testing.T) {
+	tests := []struct {
+		name string
+		a    int
+		want int
+	}{
+		{name: "just below zero", a: -1, want: -1},
+		{name: "last of the first tile", a: radarTileSize - 1, want: 0},
+		{name: "one tile and a bit below zero", a: -radarTileSize - 1, want: -2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := floorDiv(tt.a, radarTileSize); got != tt.want {
+				t.Fatalf("floorDiv(%d) = %d, want %d", tt.a, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRadarPath(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{name: "skips a bad point", in: "52.1,4.3; bad ;52.2,4.5", want: 2},
+		{name: "out of range", in: "95,4.3;52.2,190", want: 0},
+		{name: "empty", in: "", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRadarPath(tt.in); len(got) != tt.want {
+				t.Fatalf("parseRadarPath kept %d points, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderRadarGIF(t *testing.T) {
+	frame := func(nowcast bool, rate float32) *RadarFrame {
+		f := &RadarFrame{Nowcast: nowcast, W: 40, H: 30, Rate: make([]float32, 40*30)}
+		f.Rate[0] = rate
+		return f
+	}
+	frames := []*RadarFrame{frame(false, 0), frame(false, 3), frame(true, 60)}
+	g := renderRadarGIF(frames, nil, [][2]float64{{0, 29}, {39, 29}})
+	if len(g.Image) != 3 || g.Delay[1] <= g.Delay[0] {
+		t.Fatalf("frames = %d, delays %v; want 3 with the newest measured held", len(g.Image), g.Delay)
+	}
+
+	tests := []struct {
+		name  string
+		frame int
+		x, y  int
+		want  uint8
+	}{
+		{name: "rain pixel", frame: 1, x: 0, y: 0, want: uint8(radarScaleIndex(3))},
+		{name: "top of the scale", frame: 2, x: 0, y: 0, want: uint8(radarGreys + len(radarScale) - 1)},
+		{name: "centre marker", frame: 0, x: 20, y: 15, want: radarIdxLight},
+		{name: "route", frame: 0, x: 10, y: 29, want: radarIdxRoute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.Image[tt.frame].ColorIndexAt(tt.x, tt.y); got != tt.want {
+				t.Fatalf("pixel (%d,%d) of frame %d = %d, want %d", tt.x, tt.y, tt.frame, got, tt.want)
+			}
+		})
+	}
+}
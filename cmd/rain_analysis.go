@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// heavyRainMm is the rate from which the analysis calls rain heavy; below it
+// and above lightRainMm it's moderate.
+const heavyRainMm = 10.0
+
+// rainDisagreement is the agreement share below which the summary says the
+// providers disagree.
+const rainDisagreement = 0.75
+
+// RainAnalysis is the 2-hour nowcast read off both providers at once, so the
+// CLI, the web page and the widget tell the same story without relying on
+// Buienalarm's text or re-deriving it from the raw points. Times are where
+// the merged line crosses DryThresholdMmH.
+type RainAnalysis struct {
+	From    time.Time `json:"from"`
+	Horizon time.Time `json:"horizon"`
+	Dry     bool      `json:"dry"`     // dry across the whole window
+	Raining bool      `json:"raining"` // wet at From
+
+	StartsAt    *time.Time `json:"starts_at,omitempty"`
+	StopsAt     *time.Time `json:"stops_at,omitempty"` // nil when it rains to the horizon
+	DurationMin int        `json:"duration_min"`       // start to stop, or to the horizon
+	PeakMmH     float64    `json:"peak_mm_h"`
+	PeakAt      *time.Time `json:"peak_at,omitempty"`
+	TotalMm     float64    `json:"total_mm"`
+	Intensity   string     `json:"intensity"` // "light", "moderate", "heavy"; "" when dry
+
+	DryWindows []RainSpan `json:"dry_windows"`
+
+	Providers []string `json:"providers"`
+	// Agreement is the share of the window in which both providers say the
+	// same thing, wet or dry; nil with fewer than two providers.
+	Agreement *float64 `json:"agreement,omitempty"`
+	// StartDiffMin is how many minutes later Buienradar has the rain start
+	// than Buienalarm (negative: earlier); nil unless both have it start.
+	StartDiffMin *int `json:"start_diff_min,omitempty"`
+
+	Summary string `json:"summary"`
+}
+
+// RainSpan is a stretch of the window, e.g. a dry spell between showers.
+type RainSpan struct {
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Minutes int       `json:"minutes"`
+}
+
+// AnalyzeRain merges Buienalarm and Buienradar into one line — the wetter of
+// the two at every point of either, the same rule as IsDry — and reads the
+// events off it. Buienradar is capped to Buienalarm's horizon, or to two
+// hours when Buienalarm is missing. now only affects the relative times in
+// the summary. Nil when neither provider has points.
+func AnalyzeRain(alarm, radar *Forecast, now time.Time) *RainAnalysis {
+	alarmPts, radarPts, horizon, ok := nowcastWindow(alarm, radar)
+	if !ok {
+		return nil
+	}
+
+	var grid []time.Time
+	for _, p := range alarmPts {
+		grid = append(grid, p.Time)
+	}
+	for _, p := range radarPts {
+		grid = append(grid, p.Time)
+	}
+	slices.SortFunc(grid, func(a, b time.Time) int { return a.Compare(b) })
+	grid = slices.CompactFunc(grid, func(a, b time.Time) bool { return a.Equal(b) })
+
+	a := &RainAnalysis{From: grid[0], Horizon: horizon, DryWindows: []RainSpan{}}
+	if len(alarmPts) > 0 {
+		a.Providers = append(a.Providers, "buienalarm")
+	}
+	if len(radarPts) > 0 {
+		a.Providers = append(a.Providers, "buienradar")
+	}
+
+	merged := make([]float64, len(grid))
+	var agree, both int
+	var alarmStart, radarStart time.Time
+	for i, t := range grid {
+		av, aok := seriesAt(alarmPts, t)
+		rv, rok := seriesAt(radarPts, t)
+		merged[i] = max(av, rv)
+		if aok && alarmStart.IsZero() && av >= DryThresholdMmH {
+			alarmStart = t
+		}
+		if rok && radarStart.IsZero() && rv >= DryThresholdMmH {
+			radarStart = t
+		}
+		if aok && rok {
+			both++
+			if (av >= DryThresholdMmH) == (rv >= DryThresholdMmH) {
+				agree++
+			}
+		}
+	}
+	if both > 0 {
+		share := float64(agree) / float64(both)
+		a.Agreement = &share
+	}
+	if !alarmStart.IsZero() && !radarStart.IsZero() {
+		diff := int(math.Round(radarStart.Sub(alarmStart).Minutes()))
+		a.StartDiffMin = &diff
+	}
+
+	var dryFrom time.Time
+	for i, t := range grid {
+		v := merged[i]
+		if i > 0 {
+			a.TotalMm += (merged[i-1] + v) / 2 * t.Sub(grid[i-1]).Hours()
+		}
+		if v > a.PeakMmH {
+			a.PeakMmH = v
+			a.PeakAt = &grid[i]
+		}
+		wet := v >= DryThresholdMmH
+		switch {
+		case wet && a.StartsAt == nil:
+			a.StartsAt = &grid[i]
+		case !wet && a.StartsAt != nil && a.StopsAt == nil:
+			a.StopsAt = &grid[i]
+		}
+		switch {
+		case !wet && dryFrom.IsZero():
+			dryFrom = t
+		case wet && !dryFrom.IsZero():
+			a.DryWindows = append(a.DryWindows, newRainSpan(dryFrom, t))
+			dryFrom = time.Time{}
+		}
+	}
+	if !dryFrom.IsZero() && dryFrom.Before(horizon) {
+		a.DryWindows = append(a.DryWindows, newRainSpan(dryFrom, horizon))
+	}
+	a.TotalMm = math.Round(a.TotalMm*100) / 100
+
+	a.Dry = a.StartsAt == nil
+	if a.Dry {
+		a.PeakAt = nil
+	} else {
+		a.Raining = a.StartsAt.Equal(a.From)
+		end := horizon
+		if a.StopsAt != nil {
+			end = *a.StopsAt
+		}
+		a.DurationMin = int(math.Round(end.Sub(*a.StartsAt).Minutes()))
+		switch {
+		case a.PeakMmH < lightRainMm:
+			a.Intensity = "light"
+		case a.PeakMmH < heavyRainMm:
+			a.Intensity = "moderate"
+		default:
+			a.Intensity = "heavy"
+		}
+	}
+	a.Summary = a.summary(now)
+	return a
+}
+
+// nowcastWindow is both providers' points within the window the rain chart
+// shows: up to Buienalarm's last point, or two hours when Buienalarm is
+// missing. ok is false when neither has points.
+func nowcastWindow(alarm, radar *Forecast) (alarmPts, radarPts []ForecastDataPoint, horizon time.Time, ok bool) {
+	alarmPts, radarPts = forecastPoints(alarm), forecastPoints(radar)
+	switch {
+	case len(alarmPts) > 0:
+		horizon = alarmPts[len(alarmPts)-1].Time
+	case len(radarPts) > 0:
+		horizon = radarPts[0].Time.Add(2 * time.Hour)
+	default:
+		return nil, nil, time.Time{}, false
+	}
+	return alarmPts, capToHorizon(radarPts, horizon), horizon, true
+}
+
+func newRainSpan(from, to time.Time) RainSpan {
+	return RainSpan{From: from, To: to, Minutes: int(math.Round(to.Sub(from).Minutes()))}
+}
+
+// seriesAt interpolates pts linearly at t; false outside their range.
+func seriesAt(pts []ForecastDataPoint, t time.Time) (float64, bool) {
+	for i, p := range pts {
+		if p.Time.Equal(t) {
+			return p.Value, true
+		}
+		if p.Time.After(t) {
+			if i == 0 {
+				return 0, false
+			}
+			prev := pts[i-1]
+			f := t.Sub(prev.Time).Seconds() / p.Time.Sub(prev.Time).Seconds()
+			return prev.Value + f*(p.Value-prev.Value), true
+		}
+	}
+	return 0, false
+}
+
+// summary is the one-line headline, e.g. "Light rain from 14:10 (in 10 min)
+// for 25 min, up to 2.1 mm/h".
+func (a *RainAnalysis) summary(now time.Time) string {
+	if a.Dry {
+		return "Dry for the next " + formatRainMinutes(minutesFrom(now, a.Horizon))
+	}
+	kind := strings.ToUpper(a.Intensity[:1]) + a.Intensity[1:] + " rain"
+	var s string
+	switch {
+	case a.Raining && a.StopsAt != nil:
+		s = fmt.Sprintf("%s stopping in %s (%s)", kind, formatRainMinutes(minutesFrom(now, *a.StopsAt)), a.StopsAt.Format("15:04"))
+	case a.Raining:
+		s = fmt.Sprintf("%s for the next %s", kind, formatRainMinutes(minutesFrom(now, a.Horizon)))
+	default:
+		s = fmt.Sprintf("%s from %s (in %s)", kind, a.StartsAt.Format("15:04"), formatRainMinutes(minutesFrom(now, *a.StartsAt)))
+		if a.StopsAt != nil {
+			s += " for " + formatRainMinutes(a.DurationMin)
+		}
+	}
+	s += fmt.Sprintf(", up to %.1f mm/h", a.PeakMmH)
+	if a.Agreement != nil && *a.Agreement < rainDisagreement {
+		s += " · the providers disagree"
+	}
+	return s
+}
+
+func minutesFrom(now, t time.Time) int {
+	return max(0, int(math.Round(t.Sub(now).Minutes())))
+}
+
+// formatRainMinutes is "25 min", "1 h 40 min" or "2 h".
+func formatRainMinutes(m int) string {
+	switch {
+	case m < 60:
+		return fmt.Sprintf("%d min", m)
+	case m%60 == 0:
+		return fmt.Sprintf("%d h", m/60)
+	default:
+		return fmt.Sprintf("%d h %d min", m/60, m%60)
+	}
+}
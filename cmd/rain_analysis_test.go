@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnalyzeRain(t *testing.T) {
+	now := time.Date(2026, 5, 1, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		alarm, radar *Forecast
+		summary      string
+		duration     int
+		dryWindows   int
+	}{
+		{name: "dry", alarm: testRainLine(now, 0, 0, 0), radar: testRainLine(now, 0, 0, 0),
+			summary: "Dry for the next 10 min", dryWindows: 1},
+		{name: "shower", alarm: testRainLine(now, 0, 0, 1.2, 0.6, 0),
+			summary: "Light rain from 14:10 (in 10 min) for 10 min, up to 1.2 mm/h", duration: 10, dryWindows: 1},
+		{name: "radar is wetter", alarm: testRainLine(now, 0.5, 0.1, 0), radar: testRainLine(now, 0.5, 4, 0),
+			summary: "Moderate rain stopping in 10 min (14:10), up to 4.0 mm/h", duration: 10},
+		{name: "disagree", alarm: testRainLine(now, 0, 0, 0, 0, 0), radar: testRainLine(now, 1, 1, 1, 1, 1),
+			summary: "Light rain for the next 20 min, up to 1.0 mm/h · the providers disagree", duration: 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := AnalyzeRain(tt.alarm, tt.radar, now)
+			if a.Summary != tt.summary {
+				t.Errorf("summary = %q, want %q", a.Summary, tt.summary)
+			}
+			if a.DurationMin != tt.duration || len(a.DryWindows) != tt.dryWindows {
+				t.Errorf("duration %d, %d dry windows; want %d, %d", a.DurationMin, len(a.DryWindows), tt.duration, tt.dryWindows)
+			}
+		})
+	}
+}
+
+func TestAnalyzeRainTotals(t *testing.T) {
+	now := time.Date(2026, 5, 1, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		alarm, radar *Forecast
+		none         bool // no analysis at all
+		totalMm      float64
+		peakAt       time.Time
+	}{
+		{name: "ten minutes at 1.2 mm/h", alarm: testRainLine(now, 0, 1.2, 1.2, 0), totalMm: 0.2, peakAt: now.Add(5 * time.Minute)},
+		{name: "no points", radar: &Forecast{}, none: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := AnalyzeRain(tt.alarm, tt.radar, now)
+			if tt.none {
+				if a != nil {
+					t.Fatalf("analysis = %+v, want none", a)
+				}
+				return
+			}
+			if a.TotalMm != tt.totalMm || !a.PeakAt.Equal(tt.peakAt) {
+				t.Fatalf("total %.2f mm, peak at %v; want %.2f at %v", a.TotalMm, a.PeakAt, tt.totalMm, tt.peakAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jsnjack/termplt"
+)
+
+// ansiGrey is the dim colour of the previous rain line under --watch;
+// termplt has no grey of its own.
+const ansiGrey = "\x1b[90m"
+
+// watchRain is `weather --watch`: it redraws the rain outlook in place every
+// interval until Ctrl-C, with the previous Buienalarm line dimmed under the
+// current one and a line saying what changed. Intervals shorter than the
+// nowcast cache's TTL are stretched to it, since a refetch before then
+// returns the same forecast.
+func watchRain(ctx context.Context, loc Location, interval time.Duration) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	interval = max(interval, buienalarmCache.ttl)
+
+	var prev *Forecast
+	var prevAt time.Time
+	for {
+		prog := NewCLIProgress("rain forecast")
+		glance, err := buildGlanceResponse(ctx, loc, true, prog)
+		prog.Finish()
+		if ctx.Err() != nil {
+			return nil
+		}
+		now := time.Now()
+
+		// Clear only once the new data is in, so the last picture stays up
+		// while it loads.
+		fmt.Print("\x1b[H\x1b[2J")
+		if glance == nil {
+			fmt.Printf("%sforecast: %v%s\n", termplt.ColorRed, err, termplt.ColorReset)
+		} else {
+			printGlance(os.Stdout, glance, loc, prev)
+			if change := rainChange(prev, glance.Buienalarm); change != "" {
+				fmt.Printf("\n%sSince %s:%s %s\n", termplt.ColorBold, prevAt.Format("15:04"), termplt.ColorReset, change)
+			}
+			if glance.Buienalarm != nil {
+				prev, prevAt = glance.Buienalarm, now
+			}
+		}
+		fmt.Printf("\n%sUpdated %s · next at %s · Ctrl-C to stop%s\n",
+			ansiGrey, now.Format("15:04:05"), now.Add(interval).Format("15:04:05"), termplt.ColorReset)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// rainChange describes how the Buienalarm line moved between two refreshes:
+// the peak rate and when the rain starts. Empty when there's nothing to
+// compare or both are dry.
+func rainChange(prev, cur *Forecast) string {
+	if prev == nil || cur == nil {
+		return ""
+	}
+	prevPeak, prevStart := rainPeakStart(prev)
+	curPeak, curStart := rainPeakStart(cur)
+	if prevStart.IsZero() && curStart.IsZero() {
+		return ""
+	}
+	// Rain that had already started by the new window's first point is
+	// "now" in both, not a start that moved.
+	if len(cur.Data) > 0 && !prevStart.IsZero() && prevStart.Before(cur.Data[0].Time) {
+		prevStart = cur.Data[0].Time
+	}
+	var parts []string
+	switch {
+	case math.Abs(curPeak-prevPeak) < 0.05:
+		parts = append(parts, fmt.Sprintf("peak unchanged at %.1f mm/h", curPeak))
+	case curPeak > prevPeak:
+		parts = append(parts, fmt.Sprintf("%speak up%s %.1f → %.1f mm/h", termplt.ColorYellow, termplt.ColorReset, prevPeak, curPeak))
+	default:
+		parts = append(parts, fmt.Sprintf("%speak down%s %.1f → %.1f mm/h", termplt.ColorGreen, termplt.ColorReset, prevPeak, curPeak))
+	}
+	switch {
+	case curStart.IsZero():
+		parts = append(parts, termplt.ColorGreen+"rain no longer expected"+termplt.ColorReset)
+	case prevStart.IsZero():
+		parts = append(parts, fmt.Sprintf("%srain now expected from %s%s", termplt.ColorYellow, curStart.Format("15:04"), termplt.ColorReset))
+	case curStart.Sub(prevStart).Abs() >= 5*time.Minute:
+		parts = append(parts, fmt.Sprintf("rain from %s (was %s)", curStart.Format("15:04"), prevStart.Format("15:04")))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// rainPeakStart is a forecast's peak rate and the first time it reaches
+// DryThresholdMmH; zero when it stays dry.
+func rainPeakStart(f *Forecast) (peak float64, start time.Time) {
+	for _, p := range f.Data {
+		peak = max(peak, p.Value)
+		if start.IsZero() && p.Value >= DryThresholdMmH {
+			start = p.Time
+		}
+	}
+	return peak, start
+}
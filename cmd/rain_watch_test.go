@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRainLine builds a 5-minute rain forecast from `from` with the given
+// rates.
+func testRainLine(from time.Time, vals ...float64) *Forecast {
+	f := &Forecast{}
+	for i, v := range vals {
+		f.Data = append(f.Data, ForecastDataPoint{Time: from.Add(time.Duration(i*5) * time.Minute), Value: v})
+	}
+	return f
+}
+
+func TestRainChange(t *testing.T) {
+	t0 := time.Date(2026, 5, 1, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		prev, cur *Forecast
+		want      string // substring; "" means no change line at all
+		notWant   string // substring that must not appear; "" for no check
+	}{
+		{name: "first refresh", cur: testRainLine(t0, 0, 1)},
+		{name: "dry both times", prev: testRainLine(t0, 0, 0), cur: testRainLine(t0, 0, 0)},
+		{name: "heavier", prev: testRainLine(t0, 0, 1), cur: testRainLine(t0, 0, 2), want: "1.0 → 2.0 mm/h"},
+		{name: "arrives", prev: testRainLine(t0, 0, 0, 0), cur: testRainLine(t0, 0, 0, 1), want: "rain now expected from 14:10"},
+		{name: "clears", prev: testRainLine(t0, 1, 0), cur: testRainLine(t0, 0, 0), want: "rain no longer expected"},
+		{name: "earlier", prev: testRainLine(t0, 0, 0, 0, 1), cur: testRainLine(t0, 0, 1, 1, 1), want: "rain from 14:05 (was 14:15)"},
+		// Ongoing rain isn't a moved start just because the window slid on.
+		{name: "still raining", prev: testRainLine(t0, 1, 1, 1), cur: testRainLine(t0.Add(10*time.Minute), 1, 1),
+			want: "peak unchanged at 1.0 mm/h", notWant: "rain from"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rainChange(tt.prev, tt.cur)
+			if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+				t.Fatalf("rainChange = %q, want %q", got, tt.want)
+			}
+			if tt.notWant != "" && strings.Contains(got, tt.notWant) {
+				t.Fatalf("rainChange = %q, shouldn't mention %q", got, tt.notWant)
+			}
+		})
+	}
+}
@@ -0,0 +1,392 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A small pure-Go raster backend for the chart models in svgchart.go, for
+// consumers that can't show SVG (chat bots, e-ink panels, email). Geometry
+// is given in the same units as the SVG viewBox and scaled by DPI/96, so a
+// chart looks the same on a 96 dpi screen and sharper on a 300 dpi panel.
+// Lines and fills are anti-aliased by coverage; text uses a built-in 5×7
+// bitmap font, scaled in whole pixels so it stays crisp on e-ink.
+
+// RasterOpts sets the output size and look of a PNG chart.
+type RasterOpts struct {
+	DPI  float64 // default 96: one viewBox unit per pixel
+	Dark bool    // light text on a dark background instead of the reverse
+}
+
+const (
+	rasterBaseDPI = 96
+	rasterMinDPI  = 48
+	rasterMaxDPI  = 600
+
+	// rasterMaxPixels caps the output: the PNG endpoints take size and DPI
+	// from the query, and 2000×2000 at 600 dpi would be 156 megapixels.
+	rasterMaxPixels = 4 << 20
+)
+
+type rasterCanvas struct {
+	img    *image.RGBA
+	scale  float64 // device pixels per viewBox unit
+	fg, bg color.RGBA
+}
+
+// newRasterCanvas makes a w×h viewBox canvas at ro.DPI, lowered as far as
+// needed to keep it within rasterMaxPixels.
+func newRasterCanvas(w, h int, ro RasterOpts) *rasterCanvas {
+	if ro.DPI <= 0 {
+		ro.DPI = rasterBaseDPI
+	}
+	scale := ro.DPI / rasterBaseDPI
+	if px := float64(w) * float64(h) * scale * scale; px > rasterMaxPixels {
+		scale *= math.Sqrt(rasterMaxPixels / px)
+	}
+	c := &rasterCanvas{
+		scale: scale,
+		fg:    color.RGBA{0x11, 0x11, 0x11, 0xff},
+		bg:    color.RGBA{0xff, 0xff, 0xff, 0xff},
+	}
+	if ro.Dark {
+		c.fg, c.bg = color.RGBA{0xe5, 0xe7, 0xeb, 0xff}, color.RGBA{0x11, 0x18, 0x27, 0xff}
+	}
+	c.img = image.NewRGBA(image.Rect(0, 0, int(math.Ceil(float64(w)*c.scale)), int(math.Ceil(float64(h)*c.scale))))
+	for i := 0; i < len(c.img.Pix); i += 4 {
+		c.img.Pix[i], c.img.Pix[i+1], c.img.Pix[i+2], c.img.Pix[i+3] = c.bg.R, c.bg.G, c.bg.B, 0xff
+	}
+	return c
+}
+
+// PNG encodes the canvas.
+func (c *rasterCanvas) PNG() ([]byte, error) {
+	return encodePNG(c.img)
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// parseHexColor reads "#rgb" or "#rrggbb"; anything else is the foreground,
+// like currentColor in the SVG.
+func (c *rasterCanvas) parseHexColor(s string) color.RGBA {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if len(s) != 6 || err != nil {
+		return c.fg
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}
+}
+
+// blend paints device pixel (x, y) with col at coverage a (0..1).
+func (c *rasterCanvas) blend(x, y int, col color.RGBA, a float64) {
+	if a <= 0 || !(image.Point{x, y}).In(c.img.Rect) {
+		return
+	}
+	a = math.Min(a, 1)
+	i := c.img.PixOffset(x, y)
+	p := c.img.Pix[i : i+3 : i+3]
+	p[0] = uint8(float64(p[0])*(1-a) + float64(col.R)*a + 0.5)
+	p[1] = uint8(float64(p[1])*(1-a) + float64(col.G)*a + 0.5)
+	p[2] = uint8(float64(p[2])*(1-a) + float64(col.B)*a + 0.5)
+}
+
+// FillRect fills a rectangle in viewBox units.
+func (c *rasterCanvas) FillRect(x, y, w, h float64, col color.RGBA, alpha float64) {
+	x0, y0 := x*c.scale, y*c.scale
+	x1, y1 := (x+w)*c.scale, (y+h)*c.scale
+	for py := int(math.Floor(y0)); py < int(math.Ceil(y1)); py++ {
+		cy := math.Min(float64(py+1), y1) - math.Max(float64(py), y0)
+		for px := int(math.Floor(x0)); px < int(math.Ceil(x1)); px++ {
+			cx := math.Min(float64(px+1), x1) - math.Max(float64(px), x0)
+			c.blend(px, py, col, alpha*cx*cy)
+		}
+	}
+}
+
+// Line strokes a segment of width w (viewBox units) with round caps.
+func (c *rasterCanvas) Line(x1, y1, x2, y2, w float64, col color.RGBA, alpha float64) {
+	x1, y1, x2, y2 = x1*c.scale, y1*c.scale, x2*c.scale, y2*c.scale
+	r := math.Max(w*c.scale, 1) / 2
+	dx, dy := x2-x1, y2-y1
+	l2 := dx*dx + dy*dy
+	for py := int(math.Floor(math.Min(y1, y2) - r - 1)); py <= int(math.Ceil(math.Max(y1, y2)+r+1)); py++ {
+		for px := int(math.Floor(math.Min(x1, x2) - r - 1)); px <= int(math.Ceil(math.Max(x1, x2)+r+1)); px++ {
+			// Distance from the pixel centre to the segment; coverage falls
+			// off over the last pixel of the stroke's half-width.
+			cx, cy := float64(px)+0.5, float64(py)+0.5
+			t := 0.0
+			if l2 > 0 {
+				t = math.Max(0, math.Min(1, ((cx-x1)*dx+(cy-y1)*dy)/l2))
+			}
+			d := math.Hypot(cx-(x1+t*dx), cy-(y1+t*dy))
+			if cov := r + 0.5 - d; cov > 0 {
+				c.blend(px, py, col, alpha*cov)
+			}
+		}
+	}
+}
+
+// Polyline strokes pts in order; dash, when set, is an on/off pattern in
+// viewBox units carried across the corners like SVG's stroke-dasharray.
+func (c *rasterCanvas) Polyline(pts [][2]float64, w float64, col color.RGBA, alpha float64, dash []float64) {
+	if len(dash) == 0 {
+		for i := 1; i < len(pts); i++ {
+			c.Line(pts[i-1][0], pts[i-1][1], pts[i][0], pts[i][1], w, col, alpha)
+		}
+		return
+	}
+	di, left := 0, dash[0]
+	for i := 1; i < len(pts); i++ {
+		x, y := pts[i-1][0], pts[i-1][1]
+		seg := math.Hypot(pts[i][0]-x, pts[i][1]-y)
+		if seg == 0 {
+			continue
+		}
+		ux, uy := (pts[i][0]-x)/seg, (pts[i][1]-y)/seg
+		for seg > 0 {
+			step := math.Min(seg, left)
+			if di%2 == 0 {
+				c.Line(x, y, x+ux*step, y+uy*step, w, col, alpha)
+			}
+			x, y = x+ux*step, y+uy*step
+			seg -= step
+			left -= step
+			if left <= 0 {
+				di = (di + 1) % len(dash)
+				left = dash[di]
+			}
+		}
+	}
+}
+
+// FillPolygon fills a closed polygon (even-odd), anti-aliased vertically
+// by sampling four scanlines per pixel row.
+func (c *rasterCanvas) FillPolygon(pts [][2]float64, col color.RGBA, alpha float64) {
+	if len(pts) < 3 {
+		return
+	}
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, p := range pts {
+		minY, maxY = math.Min(minY, p[1]*c.scale), math.Max(maxY, p[1]*c.scale)
+	}
+	const sub = 4
+	cover := map[int]float64{}
+	for py := int(math.Floor(minY)); py <= int(math.Ceil(maxY)); py++ {
+		clear(cover)
+		for s := 0; s < sub; s++ {
+			sy := float64(py) + (float64(s)+0.5)/sub
+			var xs []float64
+			for i := range pts {
+				a, b := pts[i], pts[(i+1)%len(pts)]
+				ay, by := a[1]*c.scale, b[1]*c.scale
+				if (ay <= sy) == (by <= sy) {
+					continue
+				}
+				xs = append(xs, (a[0]+(sy-ay)/(by-ay)*(b[0]-a[0]))*c.scale)
+			}
+			slices.Sort(xs)
+			for i := 0; i+1 < len(xs); i += 2 {
+				x0, x1 := xs[i], xs[i+1]
+				for px := int(math.Floor(x0)); px < int(math.Ceil(x1)); px++ {
+					cover[px] += (math.Min(float64(px+1), x1) - math.Max(float64(px), x0)) / sub
+				}
+			}
+		}
+		for px, cv := range cover {
+			c.blend(px, py, col, alpha*cv)
+		}
+	}
+}
+
+// Disc fills a circle centred at (x, y) with radius r (viewBox units).
+func (c *rasterCanvas) Disc(x, y, r float64, col color.RGBA, alpha float64) {
+	c.Line(x, y, x, y, 2*r, col, alpha)
+}
+
+// Text anchors for Text.
+const (
+	textStart = iota
+	textMiddle
+	textEnd
+)
+
+// Text draws s with its baseline at y (or centred on y when middle is set),
+// anchored at x. size is the SVG font size in viewBox units; the bitmap
+// font is scaled to the nearest whole multiple so glyph edges stay sharp.
+func (c *rasterCanvas) Text(x, y float64, s string, size float64, anchor int, middle, bold bool, col color.RGBA, alpha float64) {
+	k := max(1, int(math.Round(size*c.scale/10)))
+	runes := []rune(s)
+	w := float64(len(runes)*(rasterGlyphW+1)*k - k)
+	if bold {
+		w += float64(k)
+	}
+	px := x * c.scale
+	switch anchor {
+	case textMiddle:
+		px -= w / 2
+	case textEnd:
+		px -= w
+	}
+	top := y*c.scale - float64(rasterGlyphH*k)
+	if middle {
+		top = y*c.scale - float64(rasterGlyphH*k)/2
+	}
+	ox, oy := int(math.Round(px)), int(math.Round(top))
+	for i, r := range runes {
+		g, ok := rasterFont[r]
+		if !ok {
+			g = rasterFont['?']
+		}
+		gx := ox + i*(rasterGlyphW+1)*k
+		for row := 0; row < rasterGlyphH; row++ {
+			for colIdx := 0; colIdx < rasterGlyphW; colIdx++ {
+				if g[row]&(1<<(rasterGlyphW-1-colIdx)) == 0 {
+					continue
+				}
+				for dy := 0; dy < k; dy++ {
+					for dx := 0; dx < k; dx++ {
+						c.blend(gx+colIdx*k+dx, oy+row*k+dy, col, alpha)
+						if bold {
+							c.blend(gx+colIdx*k+dx+k, oy+row*k+dy, col, alpha)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+const rasterGlyphW, rasterGlyphH = 5, 7
+
+// rasterFont maps each rune to seven rows of five pixels, most significant
+// bit leftmost. It covers ASCII and the few symbols the charts and grids
+// use (arrows, sun markers, rain cross); anything else draws as '?'.
+var rasterFont = func() map[rune][rasterGlyphH]uint8 {
+	src := map[rune]string{
+		' ':  ".....|.....|.....|.....|.....|.....|.....",
+		'0':  ".###.|#...#|#..##|#.#.#|##..#|#...#|.###.",
+		'1':  "..#..|.##..|..#..|..#..|..#..|..#..|.###.",
+		'2':  ".###.|#...#|....#|...#.|..#..|.#...|#####",
+		'3':  "#####|...#.|..#..|...#.|....#|#...#|.###.",
+		'4':  "...#.|..##.|.#.#.|#..#.|#####|...#.|...#.",
+		'5':  "#####|#....|####.|....#|....#|#...#|.###.",
+		'6':  "..##.|.#...|#....|####.|#...#|#...#|.###.",
+		'7':  "#####|....#|...#.|..#..|.#...|.#...|.#...",
+		'8':  ".###.|#...#|#...#|.###.|#...#|#...#|.###.",
+		'9':  ".###.|#...#|#...#|.####|....#|...#.|.##..",
+		'A':  ".###.|#...#|#...#|#####|#...#|#...#|#...#",
+		'B':  "####.|#...#|#...#|####.|#...#|#...#|####.",
+		'C':  ".###.|#...#|#....|#....|#....|#...#|.###.",
+		'D':  "###..|#..#.|#...#|#...#|#...#|#..#.|###..",
+		'E':  "#####|#....|#....|####.|#....|#....|#####",
+		'F':  "#####|#....|#....|####.|#....|#....|#....",
+		'G':  ".###.|#...#|#....|#.###|#...#|#...#|.####",
+		'H':  "#...#|#...#|#...#|#####|#...#|#...#|#...#",
+		'I':  ".###.|..#..|..#..|..#..|..#..|..#..|.###.",
+		'J':  "..###|...#.|...#.|...#.|...#.|#..#.|.##..",
+		'K':  "#...#|#..#.|#.#..|##...|#.#..|#..#.|#...#",
+		'L':  "#....|#....|#....|#....|#....|#....|#####",
+		'M':  "#...#|##.##|#.#.#|#.#.#|#...#|#...#|#...#",
+		'N':  "#...#|#...#|##..#|#.#.#|#..##|#...#|#...#",
+		'O':  ".###.|#...#|#...#|#...#|#...#|#...#|.###.",
+		'P':  "####.|#...#|#...#|####.|#....|#....|#....",
+		'Q':  ".###.|#...#|#...#|#...#|#.#.#|#..#.|.##.#",
+		'R':  "####.|#...#|#...#|####.|#.#..|#..#.|#...#",
+		'S':  ".####|#....|#....|.###.|....#|....#|####.",
+		'T':  "#####|..#..|..#..|..#..|..#..|..#..|..#..",
+		'U':  "#...#|#...#|#...#|#...#|#...#|#...#|.###.",
+		'V':  "#...#|#...#|#...#|#...#|#...#|.#.#.|..#..",
+		'W':  "#...#|#...#|#...#|#.#.#|#.#.#|#.#.#|.#.#.",
+		'X':  "#...#|#...#|.#.#.|..#..|.#.#.|#...#|#...#",
+		'Y':  "#...#|#...#|.#.#.|..#..|..#..|..#..|..#..",
+		'Z':  "#####|....#|...#.|..#..|.#...|#....|#####",
+		'a':  ".....|.....|.###.|....#|.####|#...#|.####",
+		'b':  "#....|#....|#.##.|##..#|#...#|#...#|####.",
+		'c':  ".....|.....|.###.|#....|#....|#...#|.###.",
+		'd':  "....#|....#|.##.#|#..##|#...#|#...#|.####",
+		'e':  ".....|.....|.###.|#...#|#####|#....|.###.",
+		'f':  "..##.|.#..#|.#...|###..|.#...|.#...|.#...",
+		'g':  ".....|.####|#...#|#...#|.####|....#|.###.",
+		'h':  "#....|#....|#.##.|##..#|#...#|#...#|#...#",
+		'i':  "..#..|.....|.##..|..#..|..#..|..#..|.###.",
+		'j':  "...#.|.....|..##.|...#.|...#.|#..#.|.##..",
+		'k':  "#....|#....|#..#.|#.#..|##...|#.#..|#..#.",
+		'l':  ".##..|..#..|..#..|..#..|..#..|..#..|.###.",
+		'm':  ".....|.....|##.#.|#.#.#|#.#.#|#...#|#...#",
+		'n':  ".....|.....|#.##.|##..#|#...#|#...#|#...#",
+		'o':  ".....|.....|.###.|#...#|#...#|#...#|.###.",
+		'p':  ".....|.....|####.|#...#|####.|#....|#....",
+		'q':  ".....|.....|.##.#|#..##|.####|....#|....#",
+		'r':  ".....|.....|#.##.|##..#|#....|#....|#....",
+		's':  ".....|.....|.###.|#....|.###.|....#|####.",
+		't':  ".#...|.#...|###..|.#...|.#...|.#..#|..##.",
+		'u':  ".....|.....|#...#|#...#|#...#|#..##|.##.#",
+		'v':  ".....|.....|#...#|#...#|#...#|.#.#.|..#..",
+		'w':  ".....|.....|#...#|#...#|#.#.#|#.#.#|.#.#.",
+		'x':  ".....|.....|#...#|.#.#.|..#..|.#.#.|#...#",
+		'y':  ".....|.....|#...#|#...#|.####|....#|.###.",
+		'z':  ".....|.....|#####|...#.|..#..|.#...|#####",
+		'.':  ".....|.....|.....|.....|.....|.##..|.##..",
+		',':  ".....|.....|.....|.....|.##..|..#..|.#...",
+		':':  ".....|.##..|.##..|.....|.##..|.##..|.....",
+		';':  ".....|.##..|.##..|.....|.##..|..#..|.#...",
+		'-':  ".....|.....|.....|#####|.....|.....|.....",
+		'—':  ".....|.....|.....|#####|.....|.....|.....",
+		'+':  ".....|..#..|..#..|#####|..#..|..#..|.....",
+		'=':  ".....|.....|#####|.....|#####|.....|.....",
+		'/':  ".....|....#|...#.|..#..|.#...|#....|.....",
+		'%':  "##...|##..#|...#.|..#..|.#...|#..##|...##",
+		'(':  "...#.|..#..|.#...|.#...|.#...|..#..|...#.",
+		')':  ".#...|..#..|...#.|...#.|...#.|..#..|.#...",
+		'?':  ".###.|#...#|....#|...#.|..#..|.....|..#..",
+		'!':  "..#..|..#..|..#..|..#..|..#..|.....|..#..",
+		'\'': "..#..|..#..|.#...|.....|.....|.....|.....",
+		'_':  ".....|.....|.....|.....|.....|.....|#####",
+		'<':  "...#.|..#..|.#...|#....|.#...|..#..|...#.",
+		'>':  ".#...|..#..|...#.|....#|...#.|..#..|.#...",
+		'°':  ".##..|#..#.|#..#.|.##..|.....|.....|.....",
+		'·':  ".....|.....|..#..|.###.|..#..|.....|.....",
+		'●':  ".....|.###.|#####|#####|#####|.###.|.....",
+		'✗':  "#...#|##.##|.###.|..#..|.###.|##.##|#...#",
+		'~':  ".....|.....|.#...|#.#.#|...#.|.....|.....",
+		'≈':  ".....|.#...|#.#.#|...#.|.#...|#.#.#|...#.",
+		'↑':  "..#..|.###.|#.#.#|..#..|..#..|..#..|..#..",
+		'↓':  "..#..|..#..|..#..|..#..|#.#.#|.###.|..#..",
+		'→':  ".....|..#..|...#.|#####|...#.|..#..|.....",
+		'←':  ".....|..#..|.#...|#####|.#...|..#..|.....",
+		'↗':  ".####|...##|..#.#|.#..#|#....|.....|.....",
+		'↖':  "####.|##...|#.#..|#..#.|....#|.....|.....",
+		'↘':  ".....|.....|#....|.#..#|..#.#|...##|.####",
+		'↙':  ".....|.....|....#|#..#.|#.#..|##...|####.",
+	}
+	out := make(map[rune][rasterGlyphH]uint8, len(src))
+	for r, s := range src {
+		var g [rasterGlyphH]uint8
+		for i, row := range strings.Split(s, "|") {
+			for _, ch := range row {
+				g[i] <<= 1
+				if ch == '#' {
+					g[i] |= 1
+				}
+			}
+		}
+		out[r] = g
+	}
+	return out
+}()
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestRenderLineChartPNG(t *testing.T) {
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	series := []SVGSeries{{Name: "rain", Color: "#06b6d4", Data: []ForecastDataPoint{
+		{Time: t0, Value: 1}, {Time: t0.Add(time.Hour), Value: 2},
+	}}}
+	tests := []struct {
+		name         string
+		w, h         int
+		dpi          float64
+		wantW, wantH int
+	}{
+		{name: "screen", w: 300, h: 100, dpi: 96, wantW: 300, wantH: 100},
+		{name: "double density", w: 300, h: 100, dpi: 192, wantW: 600, wantH: 200},
+		{name: "capped", w: 1024, h: 1024, dpi: 600, wantW: 2048, wantH: 2048},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := RenderLineChartPNG(series, SVGOpts{Width: tt.w, Height: tt.h}, RasterOpts{DPI: tt.dpi})
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, err := png.Decode(bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got := img.Bounds().Size(); got.X != tt.wantW || got.Y != tt.wantH {
+				t.Fatalf("size = %v, want %dx%d", got, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestRenderHeatGridPNG(t *testing.T) {
+	cells := [][]GridCell{{{Color: "#ff0000"}}}
+	b, err := RenderHeatGridPNG(cells, GridOpts{CellSize: 20}, RasterOpts{Dark: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := newHeatGridLayout(cells, GridOpts{CellSize: 20})
+	x, y := l.Cell(0, 0)
+	tests := []struct {
+		name string
+		x, y int
+		r, g uint32
+	}{
+		{name: "cell centre is the cell colour", x: x + 10, y: y + 10, r: 0xff, g: 0},
+		{name: "corner is the dark background", x: 0, y: 0, r: 0x11, g: 0x18},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if r, g, _, _ := img.At(tt.x, tt.y).RGBA(); r>>8 != tt.r || g>>8 != tt.g {
+				t.Fatalf("pixel (%d,%d) = %v, want r %#x g %#x", tt.x, tt.y, img.At(tt.x, tt.y), tt.r, tt.g)
+			}
+		})
+	}
+}
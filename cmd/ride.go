@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	rideWeatherGridDeg = 0.1              // weather is fetched once per ~10 km cell along the track
+	rideMaxHourGap     = 90 * time.Minute // a segment further than this from any hour has no weather
+	rideFetchWorkers   = 4
+)
+
+// rideSegment is one stretch of a recorded ride, usually a kilometre, with
+// the weather of the hour it was ridden in.
+type rideSegment struct {
+	FromKm, ToKm float64
+	Start, End   time.Time
+	Bearing      float64 // heading from start to end, degrees
+	Lat, Lon     float64 // midpoint
+
+	HasWeather bool
+	Temp       float64 // °C
+	Precip     float64 // mm/h
+	WindSpeed  float64 // km/h
+	WindDir    float64 // degrees the wind comes FROM
+	Gust       float64 // km/h
+	Tailwind   float64 // km/h along the heading, negative is headwind
+}
+
+// Mid is the time halfway through the segment.
+func (s rideSegment) Mid() time.Time { return s.Start.Add(s.End.Sub(s.Start) / 2) }
+
+// rideReport summarises the weather experienced over a whole ride.
+type rideReport struct {
+	Start, End  time.Time
+	DistanceKm  float64
+	Segments    []rideSegment
+	Covered     int     // segments with weather
+	AvgTailwind float64 // km/h, distance-weighted; negative means mostly headwind
+	Headwind    int     // index of the segment with the strongest headwind, -1 if none
+	Wettest     int     // index of the wettest segment, -1 if dry throughout
+	TempMin     float64
+	TempMax     float64
+	RainMm      float64 // rain fallen on the rider, mm/h × time spent in each segment
+	Sources     []string
+}
+
+// splitTrack cuts a track into consecutive segments of about segKm, ending
+// each at the first fix past the boundary. A short tail is kept as its own
+// segment.
+func splitTrack(pts []trackPoint, segKm float64) []rideSegment {
+	var out []rideSegment
+	if len(pts) < 2 {
+		return out
+	}
+	startIdx, startKm, km := 0, 0.0, 0.0
+	closeAt := func(i int) {
+		a, b := pts[startIdx], pts[i]
+		out = append(out, rideSegment{
+			FromKm: startKm, ToKm: km,
+			Start: a.Time, End: b.Time,
+			Bearing: InitialBearing(a.Lat, a.Lon, b.Lat, b.Lon),
+			Lat:     (a.Lat + b.Lat) / 2, Lon: (a.Lon + b.Lon) / 2,
+		})
+		startIdx, startKm = i, km
+	}
+	for i := 1; i < len(pts); i++ {
+		km += HaversineKm(pts[i-1].Lat, pts[i-1].Lon, pts[i].Lat, pts[i].Lon)
+		if km-startKm >= segKm {
+			closeAt(i)
+		}
+	}
+	if startIdx < len(pts)-1 && km > startKm {
+		closeAt(len(pts) - 1)
+	}
+	return out
+}
+
+// buildRideReport splits the track, fetches the past weather for every grid
+// cell it passes through, and attaches the nearest hour to each segment.
+func buildRideReport(pts []trackPoint, segKm float64, prog Progress) (*rideReport, error) {
+	segs := splitTrack(pts, segKm)
+	if len(segs) == 0 {
+		return nil, fmt.Errorf("the track doesn't go anywhere")
+	}
+	from, to := pts[0].Time, pts[len(pts)-1].Time
+
+	type cell struct{ lat, lon float64 }
+	cellOf := func(s rideSegment) cell {
+		return cell{snapToGrid(s.Lat, rideWeatherGridDeg), snapToGrid(s.Lon, rideWeatherGridDeg)}
+	}
+	type cellWeather struct {
+		hours  []HourlyForecast
+		source string
+	}
+	weather := map[cell]*cellWeather{}
+	for _, s := range segs {
+		weather[cellOf(s)] = &cellWeather{}
+	}
+
+	prog.AddTotal(len(weather))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, rideFetchWorkers)
+	for c, w := range weather {
+		wg.Add(1)
+		go func(c cell, w *cellWeather) {
+			defer wg.Done()
+			defer prog.Inc(1)
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			// Pad a day either side: the archive is queried by local date and
+			// the track's times are UTC.
+			hours, source, err := GetOpenMeteoHistory(c.lat, c.lon, from.AddDate(0, 0, -1), to.AddDate(0, 0, 1))
+			if err != nil {
+				slog.Debug("ride: weather fetch failed", "lat", c.lat, "lon", c.lon, "err", err)
+				return
+			}
+			w.hours, w.source = hours, source
+		}(c, w)
+	}
+	wg.Wait()
+
+	// The fetches taught locationZone the ride's timezone; show local times.
+	zone := locationZone(segs[0].Lat, segs[0].Lon)
+	seen := map[string]bool{}
+	for i := range segs {
+		segs[i].Start, segs[i].End = segs[i].Start.In(zone), segs[i].End.In(zone)
+		w := weather[cellOf(segs[i])]
+		if w.source != "" {
+			seen[w.source] = true
+		}
+		if h, ok := nearestHour(w.hours, segs[i].Mid()); ok {
+			attachWeather(&segs[i], h)
+		}
+	}
+	r := summarizeRide(segs)
+	for s := range seen {
+		r.Sources = append(r.Sources, s)
+	}
+	sort.Strings(r.Sources)
+	if r.Covered == 0 {
+		return r, fmt.Errorf("no past weather found for the ride's time and place")
+	}
+	return r, nil
+}
+
+// nearestHour returns the hour closest to t, if one is within rideMaxHourGap.
+func nearestHour(hours []HourlyForecast, t time.Time) (HourlyForecast, bool) {
+	best, bestGap := HourlyForecast{}, rideMaxHourGap+1
+	for _, h := range hours {
+		gap := h.Time.Sub(t)
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap < bestGap {
+			best, bestGap = h, gap
+		}
+	}
+	return best, bestGap <= rideMaxHourGap
+}
+
+func attachWeather(s *rideSegment, h HourlyForecast) {
+	s.HasWeather = true
+	s.Temp = h.Temperature
+	s.Precip = h.Precipitation
+	s.WindSpeed = h.WindSpeed
+	s.WindDir = h.WindDirection
+	s.Gust = h.WindGusts
+	s.Tailwind = tailwindComponent(h.WindSpeed, h.WindDirection, s.Bearing)
+}
+
+// summarizeRide totals the segments that have weather.
+func summarizeRide(segs []rideSegment) *rideReport {
+	r := &rideReport{
+		Segments: segs,
+		Headwind: -1,
+		Wettest:  -1,
+		TempMin:  math.NaN(),
+		TempMax:  math.NaN(),
+	}
+	if len(segs) > 0 {
+		r.Start, r.End = segs[0].Start, segs[len(segs)-1].End
+		r.DistanceKm = segs[len(segs)-1].ToKm
+	}
+	tailKm, km := 0.0, 0.0
+	for i, s := range segs {
+		if !s.HasWeather {
+			continue
+		}
+		r.Covered++
+		segKm := s.ToKm - s.FromKm
+		tailKm += s.Tailwind * segKm
+		km += segKm
+		r.RainMm += s.Precip * s.End.Sub(s.Start).Hours()
+		if math.IsNaN(r.TempMin) || s.Temp < r.TempMin {
+			r.TempMin = s.Temp
+		}
+		if math.IsNaN(r.TempMax) || s.Temp > r.TempMax {
+			r.TempMax = s.Temp
+		}
+		if s.Tailwind < 0 && (r.Headwind < 0 || s.Tailwind < segs[r.Headwind].Tailwind) {
+			r.Headwind = i
+		}
+		if s.Precip >= DryThresholdMmH && (r.Wettest < 0 || s.Precip > segs[r.Wettest].Precip) {
+			r.Wettest = i
+		}
+	}
+	if km > 0 {
+		r.AvgTailwind = tailKm / km
+	}
+	return r
+}
+
+// windLabel describes a tail/headwind as "8 km/h tailwind" or "12 km/h
+// headwind".
+func windLabel(tail float64) string {
+	kmh := int(round(math.Abs(tail)))
+	switch {
+	case kmh == 0:
+		return "no wind along the way"
+	case tail > 0:
+		return fmt.Sprintf("%d km/h tailwind", kmh)
+	}
+	return fmt.Sprintf("%d km/h headwind", kmh)
+}
+
+// rideChartSVG plots the tail/headwind and temperature through the ride,
+// shading the wet stretches.
+func rideChartSVG(r *rideReport) template.HTML {
+	var tail, temp []ForecastDataPoint
+	var wet []SVGSpan
+	for _, s := range r.Segments {
+		if !s.HasWeather {
+			continue
+		}
+		tail = append(tail, ForecastDataPoint{Time: s.Mid(), Value: s.Tailwind})
+		temp = append(temp, ForecastDataPoint{Time: s.Mid(), Value: s.Temp})
+		if s.Precip >= DryThresholdMmH {
+			if n := len(wet); n > 0 && !s.Start.After(wet[n-1].To) {
+				wet[n-1].To = s.End
+			} else {
+				wet = append(wet, SVGSpan{From: s.Start, To: s.End})
+			}
+		}
+	}
+	xFmt := "15:04"
+	if r.End.Sub(r.Start) > 24*time.Hour {
+		xFmt = "Mon 15h"
+	}
+	return RenderLineChartSVG([]SVGSeries{
+		{Name: "Tailwind", Color: feelsColor, Data: tail},
+		{Name: "Temp", Color: tempColor, Data: temp},
+	}, SVGOpts{YUnit: "km/h · °C", XTimeFormat: xFmt, Highlights: wet})
+}
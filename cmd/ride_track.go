@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// trackPoint is one timestamped fix of a recorded ride.
+type trackPoint struct {
+	Time     time.Time
+	Lat, Lon float64
+}
+
+// readTrack reads a recorded ride from a .gpx or .fit file.
+func readTrack(path string) ([]trackPoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open track: %w", err)
+	}
+	defer closeBody(f, "track file")
+	return parseTrack(path, bufio.NewReader(f))
+}
+
+// parseTrack decodes a track by the extension of name, drops fixes without a
+// time or position, and returns the rest in time order.
+func parseTrack(name string, r io.Reader) ([]trackPoint, error) {
+	var pts []trackPoint
+	var err error
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".gpx":
+		pts, err = parseGPX(r)
+	case ".fit":
+		pts, err = parseFIT(r)
+	default:
+		return nil, fmt.Errorf("track %s: want a .gpx or .fit file", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse track %s: %w", name, err)
+	}
+	if len(pts) < 2 {
+		return nil, fmt.Errorf("track %s has fewer than two timestamped points", name)
+	}
+	sort.SliceStable(pts, func(i, j int) bool { return pts[i].Time.Before(pts[j].Time) })
+	return pts, nil
+}
+
+// gpxFile is the part of a GPX 1.1 document a ride report needs: the track
+// points. Routes (rtept) carry no times, so they are ignored.
+type gpxFile struct {
+	Tracks []struct {
+		Segments []struct {
+			Points []struct {
+				Lat  float64 `xml:"lat,attr"`
+				Lon  float64 `xml:"lon,attr"`
+				Time string  `xml:"time"`
+			} `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+func parseGPX(r io.Reader) ([]trackPoint, error) {
+	var g gpxFile
+	if err := xml.NewDecoder(r).Decode(&g); err != nil {
+		return nil, err
+	}
+	var out []trackPoint
+	for _, trk := range g.Tracks {
+		for _, seg := range trk.Segments {
+			for _, p := range seg.Points {
+				t, err := time.Parse(time.RFC3339, strings.TrimSpace(p.Time))
+				if err != nil {
+					continue // untimed point: nothing to match weather to
+				}
+				out = append(out, trackPoint{Time: t, Lat: p.Lat, Lon: p.Lon})
+			}
+		}
+	}
+	return out, nil
+}
+
+// FIT constants for the one message a ride report reads: "record", the
+// per-second log of position and time.
+const (
+	fitMesgRecord     = 20
+	fitFieldLat       = 0
+	fitFieldLon       = 1
+	fitFieldTimestamp = 253
+	fitEpoch          = 631065600 // 1989-12-31T00:00:00Z, FIT's time zero
+)
+
+type fitField struct {
+	num, size byte
+}
+
+type fitDefinition struct {
+	global    uint16
+	order     binary.ByteOrder
+	fields    []fitField
+	devLength int // bytes of developer fields to skip
+}
+
+// parseFIT decodes the record messages of a Garmin FIT activity. It reads
+// only what it needs — timestamp and position — and skips every other field
+// and message by the sizes their definitions give, so unknown messages and
+// developer fields pass through harmlessly.
+func parseFIT(r io.Reader) ([]trackPoint, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < 12 {
+		return nil, errors.New("too short for a FIT file")
+	}
+	hdr := int(raw[0])
+	if hdr < 12 || len(raw) < hdr || !bytes.Equal(raw[8:12], []byte(".FIT")) {
+		return nil, errors.New("not a FIT file")
+	}
+	end := hdr + int(binary.LittleEndian.Uint32(raw[4:8]))
+	if end > len(raw) {
+		end = len(raw) // truncated upload: read what is there
+	}
+
+	defs := map[byte]*fitDefinition{}
+	var out []trackPoint
+	var lastTS uint32
+	pos := hdr
+	need := func(n int) error {
+		if pos+n > end {
+			return fmt.Errorf("FIT record runs past the end at byte %d", pos)
+		}
+		return nil
+	}
+	for pos < end {
+		h := raw[pos]
+		pos++
+		var local byte
+		compressed := h&0x80 != 0
+		switch {
+		case compressed:
+			local = (h >> 5) & 0x03
+			offset := uint32(h & 0x1f)
+			lastTS += (offset - lastTS&0x1f) & 0x1f
+		case h&0x40 != 0:
+			if err := need(5); err != nil {
+				return out, err
+			}
+			def := &fitDefinition{order: binary.LittleEndian}
+			if raw[pos+1] == 1 {
+				def.order = binary.BigEndian
+			}
+			def.global = def.order.Uint16(raw[pos+2 : pos+4])
+			n := int(raw[pos+4])
+			pos += 5
+			if err := need(3 * n); err != nil {
+				return out, err
+			}
+			for i := 0; i < n; i++ {
+				def.fields = append(def.fields, fitField{num: raw[pos], size: raw[pos+1]})
+				pos += 3
+			}
+			if h&0x20 != 0 {
+				if err := need(1); err != nil {
+					return out, err
+				}
+				nd := int(raw[pos])
+				pos++
+				if err := need(3 * nd); err != nil {
+					return out, err
+				}
+				for i := 0; i < nd; i++ {
+					def.devLength += int(raw[pos+1])
+					pos += 3
+				}
+			}
+			defs[h&0x0f] = def
+			continue
+		default:
+			local = h & 0x0f
+		}
+
+		def, ok := defs[local]
+		if !ok {
+			return out, fmt.Errorf("FIT data message for undefined local type %d", local)
+		}
+		lat, lon := int32(0x7fffffff), int32(0x7fffffff)
+		for _, f := range def.fields {
+			if err := need(int(f.size)); err != nil {
+				return out, err
+			}
+			b := raw[pos : pos+int(f.size)]
+			pos += int(f.size)
+			if f.size != 4 {
+				continue
+			}
+			v := def.order.Uint32(b)
+			switch f.num {
+			case fitFieldTimestamp:
+				if v != 0xffffffff {
+					lastTS = v
+				}
+			case fitFieldLat:
+				lat = int32(v)
+			case fitFieldLon:
+				lon = int32(v)
+			}
+		}
+		if err := need(def.devLength); err != nil {
+			return out, err
+		}
+		pos += def.devLength
+
+		if def.global != fitMesgRecord || lat == 0x7fffffff || lon == 0x7fffffff || lastTS == 0 {
+			continue
+		}
+		out = append(out, trackPoint{
+			Time: time.Unix(int64(lastTS)+fitEpoch, 0).UTC(),
+			Lat:  semicirclesToDeg(lat),
+			Lon:  semicirclesToDeg(lon),
+		})
+	}
+	return out, nil
+}
+
+// semicirclesToDeg converts FIT's 2^31-per-180° angle to degrees.
+func semicirclesToDeg(v int32) float64 {
+	return float64(v) * 180 / (1 << 31)
+}
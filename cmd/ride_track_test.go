@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Due north along a meridian, ~1.1 km every 5 minutes; one point has no
+// time and one is out of order.
+const testGPX = `<?xml version="1.0"?><gpx version="1.1"><trk><trkseg>
+<trkpt lat="52.00" lon="5.0"><time>2026-06-12T14:10:00Z</time></trkpt>
+<trkpt lat="52.01" lon="5.0"><time>2026-06-12T14:15:00Z</time></trkpt>
+<trkpt lat="52.02" lon="5.0"></trkpt>
+<trkpt lat="52.03" lon="5.0"><time>2026-06-12T14:25:00Z</time></trkpt>
+<trkpt lat="52.02" lon="5.0"><time>2026-06-12T14:20:00Z</time></trkpt>
+</trkseg></trk></gpx>`
+
+// testFIT is a minimal FIT file: one record definition, a record, then one
+// 5 s later with a compressed timestamp header.
+func testFIT() string {
+	var data []byte
+	data = append(data, 0x40, 0, 0, fitMesgRecord, 0, 3,
+		fitFieldTimestamp, 4, 0x86, fitFieldLat, 4, 0x85, fitFieldLon, 4, 0x85)
+	rec := func(ts uint32, lat, lon float64) []byte {
+		b := binary.LittleEndian.AppendUint32(nil, ts)
+		b = binary.LittleEndian.AppendUint32(b, uint32(int32(lat/180*(1<<31))))
+		return binary.LittleEndian.AppendUint32(b, uint32(int32(lon/180*(1<<31))))
+	}
+	ts := uint32(time.Date(2026, 6, 12, 14, 10, 0, 0, time.UTC).Unix() - fitEpoch)
+	data = append(data, 0x00)
+	data = append(data, rec(ts, 52, 5)...)
+	// The definition still lists a timestamp; an invalid one defers to the
+	// compressed header's.
+	data = append(data, 0x80|byte((ts+5)&0x1f))
+	data = append(data, rec(0xffffffff, 52.001, 5)...)
+	hdr := []byte{12, 0x10, 0, 0, 0, 0, 0, 0, '.', 'F', 'I', 'T'}
+	binary.LittleEndian.PutUint32(hdr[4:8], uint32(len(data)))
+	return string(append(hdr, data...))
+}
+
+func TestParseTrack(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		data     string
+		n        int
+		lastLat  float64
+		lastStep time.Duration // between the last two points
+	}{
+		{"gpx drops untimed points and sorts", "ride.GPX", testGPX, 4, 52.03, 5 * time.Minute},
+		{"fit with a compressed timestamp", "ride.fit", testFIT(), 2, 52.001, 5 * time.Second},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pts, err := parseTrack(tc.file, strings.NewReader(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pts) != tc.n {
+				t.Fatalf("parseTrack kept %d points, want %d", len(pts), tc.n)
+			}
+			last := pts[len(pts)-1]
+			if math.Abs(last.Lat-tc.lastLat) > 1e-6 || last.Time.Sub(pts[len(pts)-2].Time) != tc.lastStep {
+				t.Fatalf("parseTrack ends at %+v after %v, want lat %v after %v", last, last.Time.Sub(pts[len(pts)-2].Time), tc.lastLat, tc.lastStep)
+			}
+		})
+	}
+}
+
+func TestRideWeather(t *testing.T) {
+	pts, err := parseTrack("ride.gpx", strings.NewReader(testGPX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	segs := splitTrack(pts, 1)
+	if len(segs) != 3 || math.Abs(segs[2].ToKm-3.336) > 0.01 {
+		t.Fatalf("splitTrack = %d segments to %.3f km, want 3 to 3.336", len(segs), segs[len(segs)-1].ToKm)
+	}
+	tests := []struct {
+		name     string
+		wind     HourlyForecast
+		tailwind float64
+	}{
+		{"southerly pushes a northbound rider", HourlyForecast{WindSpeed: 20, WindDirection: 180}, 20},
+		{"northerly is a headwind", HourlyForecast{WindSpeed: 20, WindDirection: 0}, -20},
+		{"westerly is across", HourlyForecast{WindSpeed: 20, WindDirection: 270}, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			seg := segs[0]
+			attachWeather(&seg, tc.wind)
+			if math.Abs(seg.Tailwind-tc.tailwind) > 1e-6 {
+				t.Fatalf("tailwind = %v, want %v", seg.Tailwind, tc.tailwind)
+			}
+		})
+	}
+	t.Run("summary", func(t *testing.T) {
+		attachWeather(&segs[0], HourlyForecast{Temperature: 18, WindSpeed: 20, WindDirection: 180})
+		attachWeather(&segs[1], HourlyForecast{Temperature: 20, WindSpeed: 20, WindDirection: 0, Precipitation: 2})
+		r := summarizeRide(segs)
+		if r.Covered != 2 || r.Headwind != 1 || r.Wettest != 1 || r.TempMin != 18 || r.TempMax != 20 || math.Abs(r.AvgTailwind) > 1e-6 {
+			t.Fatalf("summarizeRide = %+v, want 2 covered, headwind and wettest on 1, 18–20°, no average tailwind", r)
+		}
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"math"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -24,11 +25,16 @@ import (
 //go:embed web
 var webFS embed.FS
 
-var FlagServeAddr string
+var (
+	FlagServeAddr        string
+	FlagServeRecordEvery time.Duration
+)
 
 const (
 	buienalarmColor = "#06b6d4"
 	buineradarColor = "#a855f7"
+	motionColor     = "#10b981"
+	modelColor      = "#64748b"
 )
 
 var tmplFuncs = template.FuncMap{
@@ -50,6 +56,16 @@ var (
 	hourlyBodyTmpl   = template.Must(template.New("hourly_body.html.tmpl").Funcs(tmplFuncs).ParseFS(webFS, "web/hourly_body.html.tmpl"))
 	forecastHeadTmpl = template.Must(template.New("forecast_head.html.tmpl").Funcs(tmplFuncs).ParseFS(webFS, "web/forecast_head.html.tmpl"))
 	forecastBodyTmpl = template.Must(template.New("forecast_body.html.tmpl").Funcs(tmplFuncs).ParseFS(webFS, "web/forecast_body.html.tmpl"))
+	compareHeadTmpl  = template.Must(template.New("compare_head.html.tmpl").Funcs(tmplFuncs).ParseFS(webFS, "web/compare_head.html.tmpl"))
+	compareBodyTmpl  = template.Must(template.New("compare_body.html.tmpl").Funcs(tmplFuncs).ParseFS(webFS, "web/compare_body.html.tmpl"))
+	// /history streams its own head and reuses the hourly body.
+	historyHeadTmpl = template.Must(template.New("history_head.html.tmpl").Funcs(tmplFuncs).ParseFS(webFS, "web/history_head.html.tmpl"))
+	// /ride, /commute and /verification render in one piece: an upload can't
+	// stream a head before the form is read, a commute is a handful of
+	// cached point fetches, and verification only reads local files.
+	commuteTmpl      = template.Must(template.New("commute.html.tmpl").Funcs(tmplFuncs).ParseFS(webFS, "web/commute.html.tmpl"))
+	rideTmpl         = template.Must(template.New("ride.html.tmpl").Funcs(tmplFuncs).ParseFS(webFS, "web/ride.html.tmpl"))
+	verificationTmpl = template.Must(template.New("verification.html.tmpl").Funcs(tmplFuncs).ParseFS(webFS, "web/verification.html.tmpl"))
 )
 
 var serveCmd = &cobra.Command{
@@ -57,7 +73,22 @@ var serveCmd = &cobra.Command{
 	Short: "Run an HTTP server that serves the forecast in a browser",
 	Long: `Starts an HTTP server that exposes the same forecast as the CLI via:
   GET /                  HTML page with an inline SVG chart
-  GET /api/v1/rain       JSON 2-hour rain forecast
+  GET /api/v1/rain       JSON 2-hour rain forecast, with both providers merged
+                         into start, stop, peak and total (analysis) and
+                         blended by trust with their spread (fusion)
+  GET /api/v1/precip-timeline
+                         JSON 24-hour precipitation: the nowcast, then
+                         Open-Meteo's 15-minute model after the seam
+  GET /api/v1/forecast   JSON daily outlook with anomalies against the normals
+  GET /api/v1/ensemble   JSON ensemble spread per hour and per day: member
+                         percentiles and the chance of rain or strong gusts
+  GET /commute           briefing for the configured daily commute
+  GET /radar             animated rain radar centred on the location
+  GET /chart/rain.png    the rain chart as a PNG (also hourly.png, today.png;
+                         ?w=&h= in CSS px, ?dpi=, ?theme=dark)
+  GET /dashboard.png     dithered e-ink dashboard, e.g. ?w=800&h=480&bpp=1
+  GET /verification      forecast accuracy per provider (see --record-every)
+  POST /ride             weather along an uploaded GPX/FIT ride
 plus a PWA shell (manifest, service worker, icon) so the page can be
 installed on Android as a stand-in for a native widget.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -67,12 +98,27 @@ installed on Android as a stand-in for a native widget.`,
 		mux.HandleFunc("GET /", handleIndex)
 		mux.HandleFunc("GET /hourly", handleHourly)
 		mux.HandleFunc("GET /forecast", handleForecast)
+		mux.HandleFunc("GET /compare", handleCompare)
+		mux.HandleFunc("GET /history", handleHistory)
+		mux.HandleFunc("GET /ride", handleRide)
+		mux.HandleFunc("POST /ride", handleRide)
 		mux.HandleFunc("GET /today", handleToday)
+		mux.HandleFunc("GET /today/loop.gpx", handleTodayLoopGPX)
+		mux.HandleFunc("GET /commute", handleCommute)
 		mux.HandleFunc("GET /multiday", handleMultiday)
+		mux.HandleFunc("GET /verification", handleVerification)
 		mux.HandleFunc("GET /api/v1/rain", handleRainJSON)
 		mux.HandleFunc("GET /api/v1/glance", handleGlanceJSON)
+		mux.HandleFunc("GET /api/v1/precip-timeline", handlePrecipTimelineJSON)
+		mux.HandleFunc("GET /api/v1/forecast", handleForecastJSON)
+		mux.HandleFunc("GET /api/v1/ensemble", handleEnsembleJSON)
 		mux.HandleFunc("GET /api/v1/today", handleTodayJSON)
 		mux.HandleFunc("GET /api/v1/multiday", handleMultidayJSON)
+		mux.HandleFunc("GET /chart/rain.png", handleRainPNG)
+		mux.HandleFunc("GET /chart/hourly.png", handleHourlyPNG)
+		mux.HandleFunc("GET /chart/today.png", handleTodayPNG)
+		mux.HandleFunc("GET /dashboard.png", handleDashboardPNG)
+		mux.HandleFunc("GET /radar", handleRadar)
 		mux.HandleFunc("GET /radar.gif", handleRadarMap)
 		mux.HandleFunc("GET /manifest.webmanifest", embedHandler("web/manifest.webmanifest", "application/manifest+json"))
 		mux.HandleFunc("GET /sw.js", embedHandler("web/sw.js", "application/javascript"))
@@ -84,6 +130,25 @@ installed on Android as a stand-in for a native widget.`,
 		}
 		mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticFS))))
 
+		if FlagServeRecordEvery > 0 {
+			if FlagServeRecordEvery < time.Minute {
+				return fmt.Errorf("--record-every must be at least 1m")
+			}
+			places, err := recordPlaces()
+			if err != nil {
+				return err
+			}
+			store, err := openVerificationStore()
+			if err != nil {
+				return err
+			}
+			rec, err := newRecorder(store, places)
+			if err != nil {
+				return err
+			}
+			go runRecorder(rec, FlagServeRecordEvery)
+		}
+
 		srv := &http.Server{
 			Addr:              FlagServeAddr,
 			Handler:           accessLogMiddleware(mux),
@@ -170,6 +235,7 @@ func remoteIP(r *http.Request) string {
 
 func init() {
 	serveCmd.Flags().StringVar(&FlagServeAddr, "addr", "127.0.0.1:8080", "address to bind (use 0.0.0.0:8080 to expose on the LAN)")
+	serveCmd.Flags().DurationVar(&FlagServeRecordEvery, "record-every", 0, "snapshot forecasts for saved places this often for /verification, e.g. 10m (0 = off)")
 	rootCmd.AddCommand(serveCmd)
 }
 
@@ -192,15 +258,45 @@ func fetchRain(ctx context.Context, lat, lon float64, prog Progress) (alarm, rad
 	return
 }
 
+// rainHorizon is the later of the two providers' last points: the end of
+// the rain chart's x range. Zero when neither has points.
+func rainHorizon(alarm, radar *Forecast) time.Time {
+	var last time.Time
+	for _, pts := range [][]ForecastDataPoint{forecastPoints(alarm), forecastPoints(radar)} {
+		if len(pts) > 0 && pts[len(pts)-1].Time.After(last) {
+			last = pts[len(pts)-1].Time
+		}
+	}
+	return last
+}
+
+// capToHorizon drops points after last (when set), so every line on the rain
+// chart stays within the providers' x range (see rainHorizon).
+func capToHorizon(data []ForecastDataPoint, last time.Time) []ForecastDataPoint {
+	if last.IsZero() {
+		return data
+	}
+	for i, p := range data {
+		if p.Time.After(last) {
+			return data[:i]
+		}
+	}
+	return data
+}
+
 type indexData struct {
 	Location        Location
 	Description     string
 	ChartSVG        template.HTML
+	TimelineSVG     template.HTML // next 24 hours, nowcast then model; empty if dry
 	BuienalarmColor string
+	ModelColor      string
 	BuineradarColor string
+	MotionColor     string
 	Now             string
 	Q               template.URL // shared lat/lon query string for nav links
 	NameInput       string       // raw ?name= from the URL so the form round-trips
+	RadarKey        []radarKeyItem
 
 	// Hero/glance fields — populated from the unified Open-Meteo fetch.
 	HasGlance      bool
@@ -257,8 +353,11 @@ func handleIndex(w http.ResponseWriter, r *http.Request) {
 		Location:        loc,
 		BuienalarmColor: buienalarmColor,
 		BuineradarColor: buineradarColor,
+		MotionColor:     motionColor,
+		ModelColor:      modelColor,
 		Q:               locQuery(loc),
 		NameInput:       name,
+		RadarKey:        radarKey(),
 	}
 	if err := indexHeadTmpl.Execute(w, data); err != nil {
 		slog.Debug("template execute", "tmpl", "indexHead", "err", err)
@@ -272,8 +371,20 @@ func handleIndex(w http.ResponseWriter, r *http.Request) {
 	if flusher != nil {
 		prog = NewHTTPProgress(w, flusher)
 	}
-	glance, glanceErr := buildGlanceResponse(r.Context(), loc, prog)
+	// The 24-hour timeline's model data comes alongside the nowcasts.
+	var model []ForecastDataPoint
+	var modelWG sync.WaitGroup
+	modelWG.Add(1)
+	go func() {
+		defer modelWG.Done()
+		var err error
+		if model, err = GetOpenMeteoMinutely15(loc.Latitude, loc.Longitude); err != nil {
+			slog.Debug("index: no 15-minute model data", "err", err)
+		}
+	}()
+	glance, glanceErr := buildGlanceResponse(r.Context(), loc, true, prog)
 	prog.Finish()
+	modelWG.Wait()
 	if glanceErr != nil && glance == nil {
 		// Total upstream failure — render the page with an empty chart and a
 		// short note. The shell + nav stay visible so the user can navigate.
@@ -285,32 +396,8 @@ func handleIndex(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	alarm, radar := glance.Buienalarm, glance.Buineradar
-
-	series := []SVGSeries{}
-	var lastAlarmT time.Time
-	if alarm != nil && len(alarm.Data) > 0 {
-		series = append(series, SVGSeries{Name: "Buienalarm", Color: buienalarmColor, Data: alarm.Data})
-		lastAlarmT = alarm.Data[len(alarm.Data)-1].Time
-	}
-	if radar != nil && len(radar.Data) > 0 {
-		rdata := radar.Data
-		if !lastAlarmT.IsZero() {
-			cut := rdata
-			for i, p := range rdata {
-				if p.Time.After(lastAlarmT) {
-					cut = rdata[:i]
-					break
-				}
-			}
-			rdata = cut
-		}
-		if len(rdata) > 0 {
-			series = append(series, SVGSeries{Name: "Buineradar", Color: buineradarColor, Data: rdata})
-		}
-	}
-	if alarm != nil {
-		data.Description = alarm.Desc
+	if glance.Analysis != nil {
+		data.Description = glance.Analysis.Summary
 	}
 
 	data.HasGlance = true
@@ -346,15 +433,12 @@ func handleIndex(w http.ResponseWriter, r *http.Request) {
 	// Build SVG only when there's rain in the window — otherwise the hero
 	// carries the page on its own. MinYHi=1 keeps the axis from collapsing.
 	if !data.IsDry {
-		sunMarkers := buildSunMarkers(glance.Sun)
-		yUnit := PrecipitationForecast.Unit()
-		data.ChartSVG = RenderLineChartSVG(series, SVGOpts{
-			YUnit:       yUnit,
-			XTimeFormat: "15:04",
-			MinYHi:      1,
-			FillArea:    true,
-			SunEvents:   sunMarkers,
-		})
+		chart := rainChart(glance)
+		data.ChartSVG = RenderLineChartSVG(chart.Series, chart.Opts)
+	}
+	if tl := stitchPrecipTimeline(glance.Fusion, model, time.Now()); !tl.IsDry() {
+		chart := timelineChart(&tl)
+		data.TimelineSVG = RenderLineChartSVG(chart.Series, chart.Opts)
 	}
 	data.Now = time.Now().Format("15:04:05")
 
@@ -363,6 +447,44 @@ func handleIndex(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// rainChart is the 2-hour rain chart: both providers, each to its own end,
+// the radar-motion nowcast capped to the later of the two, and the
+// providers' blend with the envelope they span and their disagreements
+// shaded. Shared by / and /chart/rain.png.
+func rainChart(glance *glanceAPIResponse) lineChart {
+	alarm, radar := glance.Buienalarm, glance.Buineradar
+	series := []SVGSeries{}
+	opts := SVGOpts{
+		YUnit:       PrecipitationForecast.Unit(),
+		XTimeFormat: "15:04",
+		MinYHi:      1,
+		FillArea:    true,
+		SunEvents:   buildSunMarkers(glance.Sun),
+	}
+	horizon := rainHorizon(alarm, radar)
+	if alarm != nil && len(alarm.Data) > 0 {
+		series = append(series, SVGSeries{Name: "Buienalarm", Color: buienalarmColor, Data: alarm.Data})
+	}
+	if radar != nil && len(radar.Data) > 0 {
+		series = append(series, SVGSeries{Name: "Buineradar", Color: buineradarColor, Data: radar.Data})
+	}
+	// The blend only says something new when there are two lines to blend.
+	if f := glance.Fusion; f != nil && len(series) == 2 {
+		line, band := fusionBand(f, horizon)
+		series = append(series, line)
+		opts.Bands = []SVGBand{band}
+		for _, d := range f.Disagreements {
+			opts.Highlights = append(opts.Highlights, SVGSpan{From: d.From, To: d.To})
+		}
+	}
+	if motion := glance.Motion; motion != nil && len(motion.Data) > 0 {
+		if mdata := capToHorizon(motion.Data, horizon); len(mdata) > 0 {
+			series = append(series, SVGSeries{Name: "Radar motion", Color: motionColor, Data: mdata, Dashed: true})
+		}
+	}
+	return lineChart{Series: series, Opts: opts}
+}
+
 // makeWindView produces a HTML-ready wind summary with caution colouring.
 func makeWindView(w glanceWind) windView {
 	cls := "muted"
@@ -432,9 +554,11 @@ func conditionHumanLabel(token string) string {
 }
 
 type rainAPIResponse struct {
-	Location   Location  `json:"location"`
-	Buienalarm *Forecast `json:"buienalarm"`
-	Buineradar *Forecast `json:"buineradar"`
+	Location   Location      `json:"location"`
+	Buienalarm *Forecast     `json:"buienalarm"`
+	Buineradar *Forecast     `json:"buineradar"`
+	Analysis   *RainAnalysis `json:"analysis,omitempty"`
+	Fusion     *RainFusion   `json:"fusion,omitempty"`
 }
 
 func handleRainJSON(w http.ResponseWriter, r *http.Request) {
@@ -451,7 +575,14 @@ func handleRainJSON(w http.ResponseWriter, r *http.Request) {
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Cache-Control", "no-store")
-	if err := json.NewEncoder(w).Encode(rainAPIResponse{Location: loc, Buienalarm: alarm, Buineradar: radar}); err != nil {
+	resp := rainAPIResponse{
+		Location:   loc,
+		Buienalarm: alarm,
+		Buineradar: radar,
+		Analysis:   AnalyzeRain(alarm, radar, time.Now()),
+		Fusion:     FuseRain(alarm, radar, currentRainTrust(r.Context())),
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		slog.Log(r.Context(), LevelTrace, "encode rain response", "err", err)
 	}
 }
@@ -536,6 +667,19 @@ func parseTodayParams(r *http.Request, zone *time.Location) (hours int, start ti
 	return
 }
 
+// parseTodayLoopParams reads ?loop=KM (0 or absent = no loops) and ?speed=.
+func parseTodayLoopParams(r *http.Request) (loopKm, speed float64) {
+	q := r.URL.Query()
+	if v, err := strconv.ParseFloat(q.Get("loop"), 64); err == nil && v > 0 && v <= 400 {
+		loopKm = v
+	}
+	speed = todayDefaultSpeed
+	if v, err := strconv.ParseFloat(q.Get("speed"), 64); err == nil && v >= 5 && v <= 60 {
+		speed = v
+	}
+	return loopKm, speed
+}
+
 func handleTodayJSON(w http.ResponseWriter, r *http.Request) {
 	lat, lon, name := locationQuery(r)
 	loc, err := ResolveLocationFor(lat, lon, name)
@@ -546,12 +690,14 @@ func handleTodayJSON(w http.ResponseWriter, r *http.Request) {
 	hours, start, radius, grid, _ := parseTodayParams(r, locationZone(loc.Latitude, loc.Longitude))
 	result := runTodayGrid(loc.Latitude, loc.Longitude, start, hours, grid, radius, NoProgress)
 	rec := RecommendToday(result)
+	loopKm, speed := parseTodayLoopParams(r)
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Cache-Control", "no-store")
 	if err := json.NewEncoder(w).Encode(map[string]any{
 		"location":       loc,
 		"result":         result,
 		"recommendation": rec,
+		"loops":          PlanTodayLoops(result, loopKm, speed),
 	}); err != nil {
 		slog.Log(r.Context(), LevelTrace, "encode today response", "err", err)
 	}
@@ -563,6 +709,13 @@ type todaySectorRow struct {
 	OverWater bool
 }
 
+type todayLoopRow struct {
+	N      int
+	Color  string
+	Desc   string
+	GPXURL template.URL
+}
+
 type todayPageData struct {
 	// inputs (set before head render)
 	Location    Location
@@ -574,6 +727,8 @@ type todayPageData struct {
 	StartLabel  string
 	EndLabel    string
 	StartInput  string
+	LoopKm      float64
+	Speed       float64
 	// results (set before body render)
 	Recommendation TodayRecommendation
 	HeatmapSVG     template.HTML
@@ -582,6 +737,8 @@ type todayPageData struct {
 	BestDesc       string
 	BestWind       string
 	WorstDesc      string
+	Loops          []todayLoopRow
+	LoopNote       string
 	Now            string
 }
 
@@ -593,6 +750,7 @@ func handleToday(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	hours, start, radius, grid, startInput := parseTodayParams(r, locationZone(loc.Latitude, loc.Longitude))
+	loopKm, speed := parseTodayLoopParams(r)
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.Header().Set("Cache-Control", "no-store")
@@ -608,6 +766,8 @@ func handleToday(w http.ResponseWriter, r *http.Request) {
 		StartLabel:  start.Format("15:04"),
 		EndLabel:    start.Add(time.Duration(hours) * time.Hour).Format("15:04"),
 		StartInput:  startInput,
+		LoopKm:      loopKm,
+		Speed:       speed,
 	}
 	if err := todayHeadTmpl.Execute(w, page); err != nil {
 		slog.Debug("template execute", "tmpl", "todayHead", "err", err)
@@ -625,19 +785,22 @@ func handleToday(w http.ResponseWriter, r *http.Request) {
 	prog.Finish()
 
 	rec := RecommendToday(result)
-	gridCells := make([][]GridCell, len(result.Cells))
-	mid := result.Grid / 2
-	for rIdx, row := range result.Cells {
-		gridCells[rIdx] = make([]GridCell, len(row))
-		for cIdx, cell := range row {
-			gridCells[rIdx][cIdx] = todayCellToGrid(cell, rIdx == mid && cIdx == mid)
-		}
+	loops := PlanTodayLoops(result, loopKm, speed)
+	paths := loopPaths(result, loops)
+	for i, l := range loops {
+		q := r.URL.Query()
+		q.Set("rank", strconv.Itoa(i+1))
+		page.Loops = append(page.Loops, todayLoopRow{
+			N:      i + 1,
+			Color:  paths[i].Color,
+			Desc:   describeLoop(l),
+			GPXURL: template.URL("/today/loop.gpx?" + q.Encode()),
+		})
 	}
-	page.HeatmapSVG = RenderHeatGridSVG(gridCells, GridOpts{
-		CellSize: 22,
-		StepKm:   result.StepKm,
-		Title:    fmt.Sprintf("Rain timing  ·  %dh window", hours),
-	})
+	if loopKm > 0 && len(loops) == 0 {
+		page.LoopNote = fmt.Sprintf("No %.0f km loop fits on land inside the map — try a larger radius or a shorter loop.", loopKm)
+	}
+	page.HeatmapSVG = RenderHeatGridSVG(todayGrid(result, paths))
 	hourLabels := make([]string, 0, hours)
 	for i := 0; i < hours; i++ {
 		hourLabels = append(hourLabels, start.Add(time.Duration(i)*time.Hour).Format("15"))
@@ -672,6 +835,64 @@ func handleToday(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleTodayLoopGPX serves loop ?rank= (1 = best) of the /today page with
+// the same query as a GPX download. The grid fetch is cached, so this is
+// cheap right after the page itself was rendered.
+func handleTodayLoopGPX(w http.ResponseWriter, r *http.Request) {
+	lat, lon, name := locationQuery(r)
+	loc, err := ResolveLocationFor(lat, lon, name)
+	if err != nil {
+		http.Error(w, "could not resolve location: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	hours, start, radius, grid, _ := parseTodayParams(r, locationZone(loc.Latitude, loc.Longitude))
+	loopKm, speed := parseTodayLoopParams(r)
+	if loopKm == 0 {
+		http.Error(w, "loop= is required", http.StatusBadRequest)
+		return
+	}
+	rank, err := strconv.Atoi(r.URL.Query().Get("rank"))
+	if err != nil || rank < 1 {
+		rank = 1
+	}
+	result := runTodayGrid(loc.Latitude, loc.Longitude, start, hours, grid, radius, NoProgress)
+	loops := PlanTodayLoops(result, loopKm, speed)
+	if rank > len(loops) {
+		http.Error(w, "no such loop", http.StatusNotFound)
+		return
+	}
+	l := loops[rank-1]
+	data, err := loopGPX(l, fmt.Sprintf("%s %s", loc.Description, l.Name()))
+	if err != nil {
+		http.Error(w, "encode gpx: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/gpx+xml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="loop-%.0fkm-%d.gpx"`, loopKm, rank))
+	if _, err := w.Write(data); err != nil {
+		slog.Log(r.Context(), LevelTrace, "write gpx", "err", err)
+	}
+}
+
+// todayGrid is the today heatmap's model with paths (suggested loops)
+// drawn over it. Shared by /today and /chart/today.png.
+func todayGrid(result todayResult, paths []GridPath) ([][]GridCell, GridOpts) {
+	cells := make([][]GridCell, len(result.Cells))
+	mid := result.Grid / 2
+	for rIdx, row := range result.Cells {
+		cells[rIdx] = make([]GridCell, len(row))
+		for cIdx, cell := range row {
+			cells[rIdx][cIdx] = todayCellToGrid(cell, rIdx == mid && cIdx == mid)
+		}
+	}
+	return cells, GridOpts{
+		CellSize: 22,
+		StepKm:   result.StepKm,
+		Title:    fmt.Sprintf("Rain timing  ·  %dh window", result.WindowHours),
+		Paths:    paths,
+	}
+}
+
 func todayCellToGrid(c todayCell, isStart bool) GridCell {
 	if isStart {
 		sc := "#fff"
@@ -737,6 +958,20 @@ type multidayQuery struct {
 	TopN             int
 	Heatmap          bool
 	HeatmapGrid      int
+	Bearings         int
+	Refine           bool
+	LegSamples       int
+	WaterMask        bool
+	Ferries          bool
+	ClimbPenalty     float64
+	To               string
+	GoalRadiusKm     float64
+	RestDays         bool
+	RestPenalty      float64
+	MinKm            float64
+	MaxKm            float64
+	DistanceSteps    int
+	Risk             float64
 }
 
 func parseMultidayParams(r *http.Request) multidayQuery {
@@ -745,7 +980,9 @@ func parseMultidayParams(r *http.Request) multidayQuery {
 		Days: 5, KmPerDay: 100, MinTemp: 15,
 		BeamWidth: 16, PivotPenalty: 3,
 		RoundTripPenalty: 20, TopN: 3,
-		HeatmapGrid: 21,
+		HeatmapGrid: 21, Bearings: multidayDefaultBearings,
+		LegSamples: multidayDefaultSamples, WaterMask: true,
+		GoalRadiusKm: 15, RestPenalty: 5, DistanceSteps: multidayDistanceSteps,
 	}
 	if v, err := strconv.Atoi(q.Get("days")); err == nil && v > 0 && v <= 14 {
 		out.Days = v
@@ -762,7 +999,7 @@ func parseMultidayParams(r *http.Request) multidayQuery {
 	if v, err := strconv.ParseFloat(q.Get("pivot-penalty"), 64); err == nil {
 		out.PivotPenalty = v
 	}
-	if q.Get("round-trip") != "" {
+	if q.Get("round-trip") != "" && q.Get("to") == "" {
 		out.RoundTrip = true
 	}
 	if v, err := strconv.ParseFloat(q.Get("round-trip-penalty"), 64); err == nil {
@@ -771,18 +1008,49 @@ func parseMultidayParams(r *http.Request) multidayQuery {
 	if v, err := strconv.Atoi(q.Get("top")); err == nil && v > 0 {
 		out.TopN = v
 	}
+	if v, err := strconv.Atoi(q.Get("bearings")); err == nil && validBearingCount(v) {
+		out.Bearings = v
+	}
+	if q.Get("refine") != "" {
+		out.Refine = true
+	}
+	out.WaterMask = queryCheckbox(q, "water-mask", true)
+	if q.Get("ferries") != "" {
+		out.Ferries = true
+	}
+	if v, err := strconv.ParseFloat(q.Get("climb-penalty"), 64); err == nil && v >= 0 {
+		out.ClimbPenalty = v
+	}
+	if v, err := strconv.Atoi(q.Get("leg-samples")); err == nil && v > 0 && v <= multidayMaxSamples {
+		out.LegSamples = v
+	}
 	// Heatmap is the default view. The form submits an explicit value (a hidden
 	// "0" before the checkbox's "1"), so an unchecked box turns it off; a bare
 	// link with no heatmap param keeps the default on.
-	out.Heatmap = true
-	if vals, ok := q["heatmap"]; ok {
-		out.Heatmap = false
-		for _, v := range vals {
-			if v == "1" || v == "on" || v == "true" {
-				out.Heatmap = true
-			}
-		}
+	out.To = strings.TrimSpace(q.Get("to"))
+	if v, err := strconv.ParseFloat(q.Get("goal-radius"), 64); err == nil && v > 0 {
+		out.GoalRadiusKm = v
 	}
+	if q.Get("rest-days") != "" {
+		out.RestDays = true
+	}
+	if v, err := strconv.ParseFloat(q.Get("rest-penalty"), 64); err == nil {
+		out.RestPenalty = v
+	}
+	minKm, errMin := strconv.ParseFloat(q.Get("min-km"), 64)
+	maxKm, errMax := strconv.ParseFloat(q.Get("max-km"), 64)
+	if errMin == nil && errMax == nil && minKm > 0 && maxKm >= minKm {
+		out.MinKm, out.MaxKm = minKm, maxKm
+	}
+	if v, err := strconv.Atoi(q.Get("distance-steps")); err == nil && v >= 2 && v <= 7 {
+		out.DistanceSteps = v
+	}
+	if v, err := strconv.ParseFloat(q.Get("risk"), 64); err == nil && v >= 0 && v < 100 {
+		out.Risk = v
+	}
+	// A destination only makes sense for the trip search, so a link with
+	// ?to= and no heatmap param defaults to trips.
+	out.Heatmap = queryCheckbox(q, "heatmap", out.To == "")
 	if v, err := strconv.Atoi(q.Get("heatmap-grid")); err == nil && v >= 5 {
 		out.HeatmapGrid = v
 	}
@@ -799,18 +1067,55 @@ func parseMultidayParams(r *http.Request) multidayQuery {
 	return out
 }
 
-func (sq multidayQuery) Config() beamConfig {
-	return beamConfig{
+// queryCheckbox reads a checkbox that defaults to on. Such forms submit a
+// hidden "0" before the checkbox's "1", so any truthy value wins; a link
+// without the parameter keeps def.
+func queryCheckbox(q url.Values, name string, def bool) bool {
+	vals, ok := q[name]
+	if !ok {
+		return def
+	}
+	for _, v := range vals {
+		if v == "1" || v == "on" || v == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+func (sq multidayQuery) Config() (beamConfig, error) {
+	obstacles, err := loadObstacles(sq.WaterMask, "", sq.Ferries)
+	if err != nil {
+		return beamConfig{}, err
+	}
+	cfg := beamConfig{
 		KmPerDay: sq.KmPerDay, MinTemp: sq.MinTemp,
 		BeamWidth: sq.BeamWidth, PivotPenalty: sq.PivotPenalty,
 		RoundTrip: sq.RoundTrip, RoundTripPenalty: sq.RoundTripPenalty,
+		Bearings: sq.Bearings, Refine: sq.Refine,
+		LegSamples: sq.LegSamples, Obstacles: obstacles,
+		ClimbPenalty: sq.ClimbPenalty, RestDays: sq.RestDays,
+		RestPenalty: sq.RestPenalty,
+		MinKm:       sq.MinKm, MaxKm: sq.MaxKm, DistanceSteps: sq.DistanceSteps,
+		Risk: sq.Risk,
+	}
+	if sq.To != "" {
+		goal, err := GetLocationFromString(sq.To)
+		if err != nil {
+			return beamConfig{}, fmt.Errorf("resolve destination %q: %w", sq.To, err)
+		}
+		cfg.Goal = &latLon{goal.Latitude, goal.Longitude}
+		cfg.GoalRadiusKm = sq.GoalRadiusKm
+		cfg.GoalPull = multidayGoalPull
 	}
+	return cfg, nil
 }
 
 type multidayTripJSON struct {
 	Score       float64    `json:"score"`
 	Bearings    []float64  `json:"bearings"`
 	Positions   []latLon   `json:"positions"`
+	Stops       []string   `json:"stops"`
 	DailyScores []DayScore `json:"dailyScores"`
 	Labels      []string   `json:"labels"`
 }
@@ -823,16 +1128,31 @@ func handleMultidayJSON(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	sq := parseMultidayParams(r)
-	cfg := sq.Config()
+	cfg, err := sq.Config()
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
 
 	resp := map[string]any{
 		"location": loc,
 		"config": map[string]any{
-			"days":      sq.Days,
-			"kmPerDay":  sq.KmPerDay,
-			"minTemp":   sq.MinTemp,
-			"startDate": sq.StartDate.Format("2006-01-02"),
-			"roundTrip": sq.RoundTrip,
+			"days":         sq.Days,
+			"kmPerDay":     sq.KmPerDay,
+			"minTemp":      sq.MinTemp,
+			"startDate":    sq.StartDate.Format("2006-01-02"),
+			"roundTrip":    sq.RoundTrip,
+			"bearings":     sq.Bearings,
+			"refine":       sq.Refine,
+			"legSamples":   sq.LegSamples,
+			"climbPenalty": sq.ClimbPenalty,
+			"to":           sq.To,
+			"goalRadiusKm": sq.GoalRadiusKm,
+			"restDays":     sq.RestDays,
+			"restPenalty":  sq.RestPenalty,
+			"minKm":        sq.MinKm,
+			"maxKm":        sq.MaxKm,
+			"risk":         sq.Risk,
 		},
 	}
 
@@ -849,7 +1169,7 @@ func handleMultidayJSON(w http.ResponseWriter, r *http.Request) {
 		for i, t := range trips {
 			out[i] = multidayTripJSON{
 				Score: t.Score, Bearings: t.Bearings, Positions: t.Positions,
-				DailyScores: t.DailyScores, Labels: labels[i],
+				Stops: t.Stops, DailyScores: t.DailyScores, Labels: labels[i],
 			}
 		}
 		resp["trips"] = out
@@ -869,6 +1189,9 @@ type multidayTripRow struct {
 	Cold      bool
 	Wind      string
 	WindColor string
+	Climb     string
+	Km        string
+	Rest      bool
 }
 
 type multidayTripView struct {
@@ -876,6 +1199,8 @@ type multidayTripView struct {
 	Path      string
 	EndLabel  string
 	EndDistKm float64
+	DayCount  int
+	TotalKm   float64
 	Days      []multidayTripRow
 }
 
@@ -911,6 +1236,21 @@ type multidayPageCfg struct {
 	MinTempMinus5 float64 // = MinTemp - 5
 	TopN          int
 	RoundTrip     bool
+	Bearings      int
+	Refine        bool
+	LegSamples    int
+	WaterMask     bool
+	Ferries       bool
+	ClimbPenalty  float64
+	To            string
+	GoalRadiusKm  float64
+	RestDays      bool
+	RestPenalty   float64
+	MinKm         float64
+	MaxKm         float64
+	DistanceSteps int
+	DistanceLabel string // "100" or "60–140"
+	Risk          float64
 }
 
 func handleMultiday(w http.ResponseWriter, r *http.Request) {
@@ -921,7 +1261,11 @@ func handleMultiday(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	sq := parseMultidayParams(r)
-	cfg := sq.Config()
+	cfg, err := sq.Config()
+	if err != nil {
+		http.Error(w, "could not set up search: "+err.Error(), http.StatusBadRequest)
+		return
+	}
 	endDate := sq.StartDate.AddDate(0, 0, sq.Days-1)
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -936,6 +1280,14 @@ func handleMultiday(w http.ResponseWriter, r *http.Request) {
 			Days: sq.Days, KmPerDay: sq.KmPerDay, MinTemp: sq.MinTemp,
 			MinTempPlus5: sq.MinTemp + 5, MinTempMinus5: sq.MinTemp - 5,
 			TopN: sq.TopN, RoundTrip: sq.RoundTrip,
+			Bearings: sq.Bearings, Refine: sq.Refine,
+			LegSamples: sq.LegSamples, WaterMask: sq.WaterMask, Ferries: sq.Ferries,
+			ClimbPenalty: sq.ClimbPenalty,
+			To:           sq.To, GoalRadiusKm: sq.GoalRadiusKm, RestDays: sq.RestDays,
+			RestPenalty: sq.RestPenalty,
+			MinKm:       sq.MinKm, MaxKm: sq.MaxKm, DistanceSteps: sq.DistanceSteps,
+			DistanceLabel: dayDistanceLabel(cfg),
+			Risk:          sq.Risk,
 		},
 		IsHeatmap:  sq.Heatmap,
 		StartLabel: sq.StartDate.Format("2006-01-02"),
@@ -986,10 +1338,10 @@ func handleMultiday(w http.ResponseWriter, r *http.Request) {
 		labels := annotateTripLabels(trips, prog)
 		prog.Finish()
 		for i, t := range trips {
-			page.Trips = append(page.Trips, tripToView(t, labels[i], loc.Latitude, loc.Longitude, sq.RoundTrip))
+			page.Trips = append(page.Trips, tripToView(t, labels[i], loc.Latitude, loc.Longitude))
 		}
 		if len(trips) > 0 {
-			page.RecommendationText = summarizeWinner(trips[0], labels[0], sq.RoundTrip)
+			page.RecommendationText = summarizeWinner(trips[0], labels[0])
 		}
 	}
 	page.Now = time.Now().Format("15:04:05")
@@ -1004,23 +1356,45 @@ func handleMultiday(w http.ResponseWriter, r *http.Request) {
 // before they trigger the fan-out (the free-tier rate limit is low).
 func estimateMultidayRequests(sq multidayQuery) int {
 	if sq.Heatmap {
-		// RunHeatmap fetches one forecast per grid cell.
+		// RunHeatmap fetches one forecast per grid cell off the water mask
+		// (so this is an upper bound), and with a risk percentile one
+		// ensemble run per cell at most (cells closer than the 0.25° ensemble
+		// grid share one).
+		if sq.Risk > 0 {
+			return 2 * sq.HeatmapGrid * sq.HeatmapGrid
+		}
 		return sq.HeatmapGrid * sq.HeatmapGrid
 	}
-	// Beam search: each day fans every surviving node into 8 bearings; the
-	// midpoint forecasts are deduped, so the worst case per day is
-	// min(beamWidth*8, beamWidth*8) unique points. Use that as an upper bound.
-	perDay := sq.BeamWidth * multidayNumDirections
+	// Beam search: each day fans every surviving node into sq.Bearings
+	// bearings, each sampled at LegSamples points; the forecasts are deduped,
+	// so beamWidth*bearings*samples unique points per day is an upper bound.
+	// The refine round gives each node up to two neighbours of each of its
+	// own top bearings (never more than there are first-round bearings),
+	// many of which land on grid points the first round already fetched.
+	legs := sq.BeamWidth * sq.Bearings
+	if sq.Refine {
+		legs += sq.BeamWidth * min(2*multidayRefineTop, sq.Bearings)
+	}
+	if sq.To != "" {
+		legs += sq.BeamWidth // the direct leg towards the destination
+	}
+	if sq.MaxKm > sq.MinKm && sq.MinKm > 0 {
+		legs *= sq.DistanceSteps
+	}
+	perDay := legs * sq.LegSamples
+	if sq.Risk > 0 {
+		perDay *= 2 // an ensemble run per point at most; they share 0.25° cells
+	}
+	if sq.ClimbPenalty > 0 {
+		// Elevation profiles go out elevationBatchSize points per call.
+		profile := legs * (int(math.Ceil(sq.KmPerDay/climbStepKm)) + 1)
+		perDay += (profile + elevationBatchSize - 1) / elevationBatchSize
+	}
 	return perDay * sq.Days
 }
 
-func tripToView(t beamNode, labels []string, startLat, startLon float64, roundTrip bool) multidayTripView {
-	v := multidayTripView{Score: t.Score}
-	parts := make([]string, 0, len(t.Bearings))
-	for _, b := range t.Bearings {
-		parts = append(parts, CompassName(b))
-	}
-	v.Path = strings.Join(parts, " → ")
+func tripToView(t beamNode, labels []string, startLat, startLon float64) multidayTripView {
+	v := multidayTripView{Score: t.Score, Path: tripPath(t), DayCount: len(t.Bearings), TotalKm: tripDistanceKm(t)}
 	end := t.Positions[len(t.Positions)-1]
 	v.EndDistKm = HaversineKm(end.Lat, end.Lon, startLat, startLon)
 	if len(labels) > 0 {
@@ -1028,14 +1402,22 @@ func tripToView(t beamNode, labels []string, startLat, startLon float64, roundTr
 	}
 	for i, b := range t.Bearings {
 		ds := t.DailyScores[i]
-		row := multidayTripRow{
-			Dir:  fmt.Sprintf("%s %s", CompassName(b), CompassArrow(b)),
-			Temp: fmt.Sprintf("%.0f°", ds.MaxTemp),
-			Cold: ds.BelowMinTemp,
-		}
+		row := multidayTripRow{Rest: ds.Rest}
 		if i < len(labels) {
 			row.Label = labels[i]
 		}
+		if ds.Rest {
+			row.Dir = "rest"
+			v.Days = append(v.Days, row)
+			continue
+		}
+		row.Dir = fmt.Sprintf("%s %s", CompassName(b), CompassArrow(b))
+		row.Km = fmt.Sprintf("%.0f", ds.DistanceKm)
+		row.Temp = fmt.Sprintf("%.0f°", ds.MaxTemp)
+		row.Cold = ds.BelowMinTemp
+		if ds.ClimbM >= 1 {
+			row.Climb = fmt.Sprintf("↑%.0f m", ds.ClimbM)
+		}
 		switch {
 		case ds.TailwindAvg > tailHeadSwitchKmh:
 			row.Wind = fmt.Sprintf("T%.0f", ds.TailwindAvg)
@@ -1052,15 +1434,18 @@ func tripToView(t beamNode, labels []string, startLat, startLon float64, roundTr
 	return v
 }
 
-func summarizeWinner(winner beamNode, labels []string, roundTrip bool) string {
+func summarizeWinner(winner beamNode, labels []string) string {
 	endLabel := "the endpoint"
 	if len(labels) > 0 {
 		endLabel = "~" + labels[len(labels)-1]
 	}
 	minT, maxT := math.MaxFloat64, -math.MaxFloat64
 	twSum, twCount := 0.0, 0
-	pivots := countPivots(winner.Bearings)
+	pivots := countPivots(winner)
 	for _, ds := range winner.DailyScores {
+		if ds.Rest {
+			continue
+		}
 		if ds.MaxTemp > maxT {
 			maxT = ds.MaxTemp
 		}
@@ -1070,10 +1455,10 @@ func summarizeWinner(winner beamNode, labels []string, roundTrip bool) string {
 		twSum += ds.TailwindAvg
 		twCount++
 	}
-	twAvg := 0.0
-	if twCount > 0 {
-		twAvg = twSum / float64(twCount)
+	if twCount == 0 {
+		return fmt.Sprintf("Trip 1 — stay at %s; no day is rideable.", endLabel)
 	}
+	twAvg := twSum / float64(twCount)
 	shape := "a straight bearing"
 	if pivots == 1 {
 		shape = "one pivot"
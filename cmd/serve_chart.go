@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ---------- /chart/*.png (raster charts for bots, e-ink and email) ----------
+
+// parseRasterParams reads the size and look shared by the PNG endpoints:
+// ?w and ?h in CSS pixels (the SVG viewBox units), ?dpi to scale them, and
+// ?theme=dark. Out-of-range values keep the defaults, as for an <img>, and
+// the canvas lowers the DPI of anything over rasterMaxPixels.
+func parseRasterParams(r *http.Request) (w, h int, ro RasterOpts) {
+	q := r.URL.Query()
+	if v, err := strconv.Atoi(q.Get("w")); err == nil && v >= 160 && v <= 2000 {
+		w = v
+	}
+	if v, err := strconv.Atoi(q.Get("h")); err == nil && v >= 100 && v <= 2000 {
+		h = v
+	}
+	if v, err := strconv.ParseFloat(q.Get("dpi"), 64); err == nil && v >= rasterMinDPI && v <= rasterMaxDPI {
+		ro.DPI = v
+	}
+	ro.Dark = q.Get("theme") == "dark"
+	return w, h, ro
+}
+
+// writePNG sends a rendered chart, or a bare 502 when it couldn't be drawn;
+// these are images, so there's no page to carry an error message.
+func writePNG(w http.ResponseWriter, r *http.Request, img []byte, err error) {
+	if err != nil {
+		slog.Debug("chart png failed", "path", r.URL.Path, "err", err)
+		http.Error(w, "chart unavailable", http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	if _, werr := w.Write(img); werr != nil {
+		slog.Log(r.Context(), LevelTrace, "write chart png", "err", werr)
+	}
+}
+
+// handleRainPNG draws the / rain chart. Unlike the page it draws a dry
+// forecast too: a flat line is the answer a bot was asked for.
+func handleRainPNG(w http.ResponseWriter, r *http.Request) {
+	lat, lon, name := locationQuery(r)
+	loc, err := ResolveLocationFor(lat, lon, name)
+	if err != nil {
+		http.Error(w, "could not resolve location: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	glance, err := buildGlanceResponse(r.Context(), loc, true, NoProgress)
+	if glance == nil {
+		writePNG(w, r, nil, err)
+		return
+	}
+	chart := rainChart(glance)
+	var ro RasterOpts
+	chart.Opts.Width, chart.Opts.Height, ro = parseRasterParams(r)
+	img, err := RenderLineChartPNG(chart.Series, chart.Opts, ro)
+	writePNG(w, r, img, err)
+}
+
+// handleHourlyPNG draws the /hourly charts, temperature over precipitation;
+// ?h is the height of the pair.
+func handleHourlyPNG(w http.ResponseWriter, r *http.Request) {
+	lat, lon, name := locationQuery(r)
+	loc, err := ResolveLocationFor(lat, lon, name)
+	if err != nil {
+		http.Error(w, "could not resolve location: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	hours := parseHoursParam(r)
+	now := time.Now().In(locationZone(loc.Latitude, loc.Longitude))
+	start := now.Truncate(time.Hour)
+	end := start.Add(time.Duration(hours) * time.Hour)
+	data, err := GetOpenMeteoRange(loc.Latitude, loc.Longitude, now, end.Add(2*time.Hour))
+	if err != nil {
+		writePNG(w, r, nil, err)
+		return
+	}
+	var rows []HourlyForecast
+	for _, h := range data.Hourly {
+		if !h.Time.Before(start) && !h.Time.After(end) {
+			rows = append(rows, h)
+		}
+	}
+	charts := hourlyCharts(rows)
+	if charts == nil {
+		charts = []lineChart{{}} // draws "no data"
+	}
+	width, height, ro := parseRasterParams(r)
+	for i := range charts {
+		charts[i].Opts.Width = width
+		if height > 0 {
+			charts[i].Opts.Height = height / len(charts)
+		}
+	}
+	img, err := RenderLineChartsPNG(charts, ro)
+	writePNG(w, r, img, err)
+}
+
+// handleTodayPNG draws the /today heatmap with its loops. The grid's size
+// follows from the cell size, so ?w picks the cell size that fits and ?h is
+// ignored.
+func handleTodayPNG(w http.ResponseWriter, r *http.Request) {
+	lat, lon, name := locationQuery(r)
+	loc, err := ResolveLocationFor(lat, lon, name)
+	if err != nil {
+		http.Error(w, "could not resolve location: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	hours, start, radius, grid, _ := parseTodayParams(r, locationZone(loc.Latitude, loc.Longitude))
+	result := runTodayGrid(loc.Latitude, loc.Longitude, start, hours, grid, radius, NoProgress)
+	loopKm, speed := parseTodayLoopParams(r)
+	cells, opts := todayGrid(result, loopPaths(result, PlanTodayLoops(result, loopKm, speed)))
+	width, _, ro := parseRasterParams(r)
+	if width > 0 {
+		opts.CellSize = max(10, (width-52)/result.Grid)
+	}
+	img, err := RenderHeatGridPNG(cells, opts, ro)
+	writePNG(w, r, img, err)
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// ---------- /commute (daily commute briefing) ----------
+
+type commuteOptionCell struct {
+	Time  string
+	Rain  string
+	Class string // "best", "wet" or ""
+	Usual bool
+}
+
+type commuteLegView struct {
+	Name    string
+	When    string
+	Route   string
+	Source  string
+	Advice  string
+	Dry     bool
+	Wind    string
+	Options []commuteOptionCell
+}
+
+type commutePageData struct {
+	Q                template.URL
+	From, To         string
+	Morning, Evening string
+	Title            string
+	Sub              string
+	Legs             []commuteLegView
+	Note             string
+	RadarURL         template.URL // radar loop framing the route
+	Now              string
+}
+
+func handleCommute(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page := commutePageData{
+		Q:       navQuery(q),
+		From:    q.Get("from"),
+		To:      q.Get("to"),
+		Morning: q.Get("morning"),
+		Evening: q.Get("evening"),
+		Title:   "Commute",
+		Now:     time.Now().Format("15:04:05"),
+	}
+	defer func() {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := commuteTmpl.Execute(w, page); err != nil {
+			slog.Debug("template execute", "tmpl", "commute", "err", err)
+		}
+	}()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		page.Note = err.Error()
+		return
+	}
+	c, err := configuredCommute(cfg, page.From, page.To, page.Morning, page.Evening)
+	if err != nil {
+		page.Note = err.Error()
+		return
+	}
+	page.From, page.To, page.Morning, page.Evening = c.From, c.To, c.Morning, c.Evening
+	report, err := PlanCommute(c, cfg, time.Now(), NoProgress)
+	if err != nil {
+		page.Note = err.Error()
+		return
+	}
+
+	page.Title = fmt.Sprintf("%s ⇄ %s", report.From.Description, report.To.Description)
+	page.Sub = fmt.Sprintf("%.1f km · about %.0f min at %.0f km/h",
+		report.DistanceKm, report.DistanceKm/report.SpeedKmh*60, report.SpeedKmh)
+	page.RadarURL = commuteRadarURL(report.From, report.To)
+	for _, l := range report.Legs {
+		v := commuteLegView{
+			Name:   l.Name,
+			When:   fmt.Sprintf("%s → %s", l.Depart.Format("Mon 15:04"), l.Arrive.Format("15:04")),
+			Route:  fmt.Sprintf("%s → %s", l.From.Description, l.To.Description),
+			Source: l.Source,
+			Advice: commuteAdviceSentence(l.Advice),
+			Dry:    l.Options[l.Best].WetMin == 0,
+		}
+		if l.HasWind {
+			v.Wind = fmt.Sprintf("%s %s · %.0f°", windLabel(l.Tailwind), commuteHeadingLabel(l), l.Temp)
+		}
+		for i, o := range l.Options {
+			cell := commuteOptionCell{Time: o.Depart.Format("15:04"), Rain: fmt.Sprintf("%.1f", o.RainMm), Usual: i == l.Usual}
+			switch {
+			case i == l.Best:
+				cell.Class = "best"
+			case o.WetMin > 0:
+				cell.Class = "wet"
+			}
+			v.Options = append(v.Options, cell)
+		}
+		page.Legs = append(page.Legs, v)
+	}
+}
+
+// commuteRadarURL frames the commute on /radar: centred between the two
+// places, zoomed to fit, with the route drawn in.
+func commuteRadarURL(from, to Location) template.URL {
+	midLat, midLon := (from.Latitude+to.Latitude)/2, (from.Longitude+to.Longitude)/2
+	return template.URL(fmt.Sprintf("/radar?lat=%.4f&lon=%.4f&zoom=%d&path=%.4f,%.4f;%.4f,%.4f",
+		midLat, midLon, radarZoomFor(from, to, radarDefaultSize),
+		from.Latitude, from.Longitude, to.Latitude, to.Longitude))
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ---------- /compare (multi-model overlay) ----------
+
+type compareModelOpt struct {
+	Key     string
+	Label   string
+	Color   string
+	Checked bool
+}
+
+type compareCell struct {
+	Temp   int
+	Precip string // formatted mm, blank when ~0
+	Err    bool   // model has no value for this hour
+}
+
+type compareRow struct {
+	Time     string
+	NewDay   bool
+	Cells    []compareCell // one per shown model, in Models order
+	Temp     int           // consensus
+	Precip   string
+	Disagree string // reason, empty when the models agree
+}
+
+type comparePageData struct {
+	Location       Location
+	Q              template.URL
+	NameInput      string
+	Hours          int
+	StartLabel     string
+	EndLabel       string
+	Options        []compareModelOpt // every model, for the form
+	Models         []compareModel    // models shown in the charts and table
+	Failed         []string          // labels of models that returned no data
+	TempChartSVG   template.HTML
+	PrecipChartSVG template.HTML
+	Disagreements  []string
+	Legend         string
+	Rows           []compareRow
+	Now            string
+	Note           string
+}
+
+func handleCompare(w http.ResponseWriter, r *http.Request) {
+	lat, lon, name := locationQuery(r)
+	loc, err := ResolveLocationFor(lat, lon, name)
+	if err != nil {
+		http.Error(w, "could not resolve location: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	q := r.URL.Query()
+	hours := 48
+	if n, err := strconv.Atoi(q.Get("hours")); err == nil && n >= 6 && n <= 168 {
+		hours = n
+	}
+	models, err := parseCompareModels(q["model"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	now := time.Now().In(locationZone(loc.Latitude, loc.Longitude))
+	start := now.Truncate(time.Hour)
+	end := start.Add(time.Duration(hours) * time.Hour)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	flusher, _ := w.(http.Flusher)
+
+	page := comparePageData{
+		Location:   loc,
+		Q:          locQuery(loc),
+		NameInput:  name,
+		Hours:      hours,
+		StartLabel: start.Format("Mon 15:04"),
+		EndLabel:   end.Format("Mon 15:04"),
+	}
+	for _, m := range compareModels {
+		opt := compareModelOpt{Key: m.Key, Label: m.Label, Color: m.Color}
+		for _, s := range models {
+			opt.Checked = opt.Checked || s.Key == m.Key
+		}
+		page.Options = append(page.Options, opt)
+	}
+	if err := compareHeadTmpl.Execute(w, page); err != nil {
+		slog.Debug("template execute", "tmpl", "compareHead", "err", err)
+		return
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	prog := NoProgress
+	if flusher != nil {
+		prog = NewHTTPProgress(w, flusher)
+	}
+	runs := fetchModelRuns(loc.Latitude, loc.Longitude, start, end, models, prog)
+	prog.Finish()
+
+	page.Now = time.Now().Format("15:04:05")
+	var ok []modelRun
+	for _, run := range runs {
+		if run.Err != nil {
+			page.Failed = append(page.Failed, run.Model.Label)
+			continue
+		}
+		ok = append(ok, run)
+		page.Models = append(page.Models, run.Model)
+	}
+	if len(ok) == 0 {
+		page.Note = "Unable to fetch any model forecast right now."
+		if err := compareBodyTmpl.Execute(w, page); err != nil {
+			slog.Debug("template execute", "tmpl", "compareBody", "err", err)
+		}
+		return
+	}
+
+	consensus := modelConsensus(ok)
+	spans := disagreementSpans(consensus)
+	page.Disagreements = describeSpans(spans)
+	page.Legend = describeCompareThresholds()
+
+	var tempSeries, precipSeries []SVGSeries
+	for _, run := range ok {
+		var tPts, pPts []ForecastDataPoint
+		for _, h := range run.Hourly {
+			tPts = append(tPts, ForecastDataPoint{Time: h.Time, Value: h.Temperature})
+			pPts = append(pPts, ForecastDataPoint{Time: h.Time, Value: h.Precipitation})
+		}
+		tempSeries = append(tempSeries, SVGSeries{Name: run.Model.Label, Color: run.Model.Color, Data: tPts})
+		precipSeries = append(precipSeries, SVGSeries{Name: run.Model.Label, Color: run.Model.Color, Data: pPts})
+	}
+	var ctPts, cpPts []ForecastDataPoint
+	for _, c := range consensus {
+		ctPts = append(ctPts, ForecastDataPoint{Time: c.Time, Value: c.Temp})
+		cpPts = append(cpPts, ForecastDataPoint{Time: c.Time, Value: c.Precip})
+	}
+	if len(ok) >= 2 {
+		tempSeries = append(tempSeries, SVGSeries{Name: "Consensus", Color: "currentColor", Data: ctPts, Dashed: true})
+		precipSeries = append(precipSeries, SVGSeries{Name: "Consensus", Color: "currentColor", Data: cpPts, Dashed: true})
+	}
+	xFmt := "15:04"
+	if hours > 24 {
+		xFmt = "Mon 15h"
+	}
+	page.TempChartSVG = RenderLineChartSVG(tempSeries, SVGOpts{YUnit: "°C", XTimeFormat: xFmt, Highlights: spans})
+	page.PrecipChartSVG = RenderLineChartSVG(precipSeries, SVGOpts{YUnit: "mm/h", XTimeFormat: xFmt, MinYHi: 1, Highlights: spans})
+
+	byModel := make([]map[int64]HourlyForecast, len(ok))
+	for i, run := range ok {
+		byModel[i] = map[int64]HourlyForecast{}
+		for _, h := range run.Hourly {
+			byModel[i][h.Time.Unix()] = h
+		}
+	}
+	every := 1
+	if hours > 24 {
+		every = 3
+	}
+	lastDay := -1
+	for _, c := range consensus {
+		if c.Time.Hour()%every != 0 && !c.Disagree {
+			continue
+		}
+		row := compareRow{
+			Time:     c.Time.Format("15:04"),
+			Temp:     int(round(c.Temp)),
+			Precip:   formatPrecip(c.Precip),
+			Disagree: c.Reason,
+		}
+		if c.Time.YearDay() != lastDay {
+			row.Time = c.Time.Format("Mon 15:04")
+			row.NewDay = lastDay != -1
+			lastDay = c.Time.YearDay()
+		}
+		for i := range ok {
+			h, found := byModel[i][c.Time.Unix()]
+			if !found {
+				row.Cells = append(row.Cells, compareCell{Err: true})
+				continue
+			}
+			row.Cells = append(row.Cells, compareCell{Temp: int(round(h.Temperature)), Precip: formatPrecip(h.Precipitation)})
+		}
+		page.Rows = append(page.Rows, row)
+	}
+
+	if err := compareBodyTmpl.Execute(w, page); err != nil {
+		slog.Debug("template execute", "tmpl", "compareBody", "err", err)
+	}
+}
+
+// describeCompareThresholds is the legend line explaining what counts as a
+// disagreement, kept next to the constants it quotes.
+func describeCompareThresholds() string {
+	return fmt.Sprintf("shaded — models ≥%.0f °C apart, or one ≥%.1f mm while another is dry",
+		compareTempSpreadC, compareWetMm)
+}
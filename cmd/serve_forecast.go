@@ -6,6 +6,7 @@ import (
 	"html/template"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
@@ -39,6 +40,20 @@ type DailyAggregate struct {
 	UVMax           float64
 	Sunrise         time.Time
 	Sunset          time.Time
+	// Climate is how the day compares with the 1991–2020 normals for the
+	// date; nil until withClimate fills it, or when they're unavailable.
+	Climate *DailyClimate `json:",omitempty"`
+}
+
+// DailyClimate is the climate context of one forecast day.
+type DailyClimate struct {
+	NormalMax     float64 // mean high for the date, °C
+	NormalMin     float64
+	MaxAnomaly    float64 // forecast high − NormalMax
+	MinAnomaly    float64
+	MaxPercentile float64 // rank of the forecast high among past highs for the date, 0–100
+	MinPercentile float64
+	NormalPrecip  float64 // mean mm for the date
 }
 
 type openMeteoDailyResponse struct {
@@ -97,25 +112,31 @@ func openMeteoGetBody(url string) ([]byte, error) {
 // boundaries and sun times are local. Cached process-wide (openMeteoDailyCache);
 // the returned slice MUST be treated as read-only.
 func GetOpenMeteoDailyRange(lat, lon float64, days int) ([]DailyAggregate, error) {
+	return GetOpenMeteoDailyRangeModel(lat, lon, days, "")
+}
+
+// GetOpenMeteoDailyRangeModel is GetOpenMeteoDailyRange for one named
+// Open-Meteo model; an empty model is the default best-match blend.
+func GetOpenMeteoDailyRangeModel(lat, lon float64, days int, model string) ([]DailyAggregate, error) {
 	if days < 1 {
 		days = 1
 	}
 	if days > 16 { // Open-Meteo caps the free daily horizon at 16 days
 		days = 16
 	}
-	key := fmt.Sprintf("%.3f|%.3f|%d", lat, lon, days)
+	key := fmt.Sprintf("%.3f|%.3f|%d|%s", lat, lon, days, model)
 	return memo(openMeteoDailyCache, key, func() ([]DailyAggregate, error) {
-		return getOpenMeteoDailyRangeUncached(lat, lon, days)
+		return getOpenMeteoDailyRangeUncached(lat, lon, days, model)
 	})
 }
 
-func getOpenMeteoDailyRangeUncached(lat, lon float64, days int) ([]DailyAggregate, error) {
+func getOpenMeteoDailyRangeUncached(lat, lon float64, days int, model string) ([]DailyAggregate, error) {
 	url := fmt.Sprintf(
 		"https://api.open-meteo.com/v1/forecast?latitude=%.4f&longitude=%.4f"+
 			"&daily=weather_code,temperature_2m_max,temperature_2m_min,apparent_temperature_max,apparent_temperature_min,"+
 			"precipitation_sum,precipitation_probability_max,wind_speed_10m_max,wind_gusts_10m_max,wind_direction_10m_dominant,"+
-			"uv_index_max,sunrise,sunset&timezone=auto&forecast_days=%d",
-		lat, lon, days,
+			"uv_index_max,sunrise,sunset&timezone=auto&forecast_days=%d%s",
+		lat, lon, days, openMeteoModelParam(model),
 	)
 	body, err := openMeteoGetBody(url)
 	if err != nil {
@@ -233,6 +254,7 @@ type hourlyPageData struct {
 	Rows           []hourlyRow
 	Now            string
 	Note           string // populated when the upstream fetch failed entirely
+	Source         string // /history only: which archive the hours came from
 }
 
 func parseHoursParam(r *http.Request) int {
@@ -301,12 +323,24 @@ func handleHourly(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var tempPts, feelsPts, precipPts []ForecastDataPoint
-	lastDay := -1
+	var rows []HourlyForecast
 	for _, h := range data.Hourly {
-		if h.Time.Before(start) || h.Time.After(end) {
-			continue
+		if !h.Time.Before(start) && !h.Time.After(end) {
+			rows = append(rows, h)
 		}
+	}
+	fillHourlyPage(&page, rows)
+
+	if err := hourlyBodyTmpl.Execute(w, page); err != nil {
+		slog.Debug("template execute", "tmpl", "hourlyBody", "err", err)
+	}
+}
+
+// fillHourlyPage builds the table rows and charts of the hourly body
+// template from rows. Shared by /hourly and /history.
+func fillHourlyPage(page *hourlyPageData, rows []HourlyForecast) {
+	lastDay := -1
+	for _, h := range rows {
 		windKmh := int(round(h.WindSpeed))
 		uv := int(round(h.UVIndex))
 		newDay := h.Time.YearDay() != lastDay
@@ -329,23 +363,35 @@ func handleHourly(w http.ResponseWriter, r *http.Request) {
 			UVClass:   uvClassFor(uv),
 			Condition: conditionHumanLabel(wmoCondition(h.WeatherCode)),
 		})
+	}
+
+	if charts := hourlyCharts(rows); charts != nil {
+		page.TempChartSVG = RenderLineChartSVG(charts[0].Series, charts[0].Opts)
+		page.PrecipChartSVG = RenderLineChartSVG(charts[1].Series, charts[1].Opts)
+	}
+}
+
+// hourlyCharts is the temperature chart over the precipitation chart for
+// rows, nil with fewer than two hours to draw. Shared by /hourly, /history
+// and /chart/hourly.png.
+func hourlyCharts(rows []HourlyForecast) []lineChart {
+	if len(rows) < 2 {
+		return nil
+	}
+	var tempPts, feelsPts, precipPts []ForecastDataPoint
+	for _, h := range rows {
 		tempPts = append(tempPts, ForecastDataPoint{Time: h.Time, Value: h.Temperature})
 		feelsPts = append(feelsPts, ForecastDataPoint{Time: h.Time, Value: h.ApparentTemperature})
 		precipPts = append(precipPts, ForecastDataPoint{Time: h.Time, Value: h.Precipitation})
 	}
-
-	if len(tempPts) >= 2 {
-		page.TempChartSVG = RenderLineChartSVG([]SVGSeries{
+	return []lineChart{
+		{Series: []SVGSeries{
 			{Name: "Temp", Color: tempColor, Data: tempPts},
 			{Name: "Feels", Color: feelsColor, Data: feelsPts},
-		}, SVGOpts{YUnit: "°C", XTimeFormat: "Mon 15h"})
-		page.PrecipChartSVG = RenderLineChartSVG([]SVGSeries{
+		}, Opts: SVGOpts{YUnit: "°C", XTimeFormat: "Mon 15h"}},
+		{Series: []SVGSeries{
 			{Name: "Precip", Color: buienalarmColor, Data: precipPts},
-		}, SVGOpts{YUnit: "mm", XTimeFormat: "Mon 15h", MinYHi: 1, FillArea: true})
-	}
-
-	if err := hourlyBodyTmpl.Execute(w, page); err != nil {
-		slog.Debug("template execute", "tmpl", "hourlyBody", "err", err)
+		}, Opts: SVGOpts{YUnit: "mm", XTimeFormat: "Mon 15h", MinYHi: 1, FillArea: true}},
 	}
 }
 
@@ -370,6 +416,23 @@ type dailyRow struct {
 	// column reads as a sparkline of warming/cooling across the fortnight.
 	BarLeftPct  float64
 	BarWidthPct float64
+	// Ensemble spread, when the ensemble covers the day: a whisker from the
+	// 10th-percentile low to the 90th-percentile high behind the bar, and the
+	// share of members with a wet (≥1 mm) day.
+	HasSpread       bool
+	WhiskerLeftPct  float64
+	WhiskerWidthPct float64
+	SpreadTitle     string // "low 6–9° · high 14–18° (p10–p90)"
+	WetPct          int
+	// Climate context, when the normals are available: the high's departure
+	// from normal, its rank among the normal period's highs, and a tick on
+	// the bar at the normal high.
+	HasNormal    bool
+	Anomaly      string // "+8°"
+	AnomalyClass string // anom-warm / anom-cold / muted
+	Percentile   string // "p97" or "record"
+	NormalPct    float64
+	ClimateTitle string // "normal 16° / 7°"
 }
 
 type forecastPageData struct {
@@ -377,6 +440,8 @@ type forecastPageData struct {
 	Q            template.URL
 	NameInput    string
 	Days         int
+	Ensemble     bool // ?ensemble=1: fetch the member spread (~50× the payload)
+	Climate      bool // ?climate=1: compare with the 1991–2020 normals
 	StartLabel   string
 	EndLabel     string
 	TempChartSVG template.HTML
@@ -413,6 +478,8 @@ func handleForecast(w http.ResponseWriter, r *http.Request) {
 		Q:         locQuery(loc),
 		NameInput: name,
 		Days:      days,
+		Ensemble:  queryCheckbox(r.URL.Query(), "ensemble", false),
+		Climate:   queryCheckbox(r.URL.Query(), "climate", false),
 	}
 	if err := forecastHeadTmpl.Execute(w, page); err != nil {
 		slog.Debug("template execute", "tmpl", "forecastHead", "err", err)
@@ -429,6 +496,19 @@ func handleForecast(w http.ResponseWriter, r *http.Request) {
 	prog.AddTotal(1)
 	daily, fetchErr := GetOpenMeteoDailyRange(loc.Latitude, loc.Longitude, days)
 	prog.Inc(1)
+	var spread map[string]EnsembleDay
+	if fetchErr == nil {
+		if page.Ensemble {
+			prog.AddTotal(1)
+			spread = ensembleDaysByDate(loc.Latitude, loc.Longitude, min(days, ensembleMaxDays))
+			prog.Inc(1)
+		}
+		if page.Climate {
+			prog.AddTotal(1)
+			daily = withClimate(loc.Latitude, loc.Longitude, daily)
+			prog.Inc(1)
+		}
+	}
 	prog.Finish()
 
 	page.Now = time.Now().Format("15:04:05")
@@ -444,15 +524,7 @@ func handleForecast(w http.ResponseWriter, r *http.Request) {
 	page.EndLabel = daily[len(daily)-1].Date.Format("Mon 2 Jan")
 
 	// Global temperature span drives the per-row range bars.
-	gMin, gMax := daily[0].TempMin, daily[0].TempMax
-	for _, d := range daily {
-		if d.TempMin < gMin {
-			gMin = d.TempMin
-		}
-		if d.TempMax > gMax {
-			gMax = d.TempMax
-		}
-	}
+	gMin, gMax := forecastTempSpan(daily, spread)
 	span := gMax - gMin
 	if span < 1 {
 		span = 1
@@ -462,7 +534,7 @@ func handleForecast(w http.ResponseWriter, r *http.Request) {
 	for _, d := range daily {
 		windKmh := int(round(d.WindMax))
 		uv := int(round(d.UVMax))
-		page.Rows = append(page.Rows, dailyRow{
+		row := dailyRow{
 			Date:        d.Date.Format("Mon 2 Jan"),
 			Condition:   conditionHumanLabel(d.Condition),
 			TempMax:     int(round(d.TempMax)),
@@ -479,7 +551,25 @@ func handleForecast(w http.ResponseWriter, r *http.Request) {
 			UVClass:     uvClassFor(uv),
 			BarLeftPct:  (d.TempMin - gMin) / span * 100,
 			BarWidthPct: (d.TempMax - d.TempMin) / span * 100,
-		})
+		}
+		if e, ok := spread[d.Date.Format("2006-01-02")]; ok && !math.IsNaN(e.TempMin.P10) {
+			lo, hi := math.Min(e.TempMin.P10, d.TempMin), math.Max(e.TempMax.P90, d.TempMax)
+			row.HasSpread = true
+			row.WhiskerLeftPct = (lo - gMin) / span * 100
+			row.WhiskerWidthPct = (hi - lo) / span * 100
+			row.SpreadTitle = fmt.Sprintf("low %.0f–%.0f° · high %.0f–%.0f° (p10–p90)",
+				e.TempMin.P10, e.TempMin.P90, e.TempMax.P10, e.TempMax.P90)
+			row.WetPct = int(round(e.WetChance * 100))
+		}
+		if c := d.Climate; c != nil {
+			row.HasNormal = true
+			row.Anomaly = anomalyLabel(c.MaxAnomaly)
+			row.AnomalyClass = anomalyClass(c.MaxAnomaly)
+			row.Percentile = percentileLabel(c.MaxPercentile)
+			row.NormalPct = (c.NormalMax - gMin) / span * 100
+			row.ClimateTitle = fmt.Sprintf("normal %.0f° / %.0f°", c.NormalMax, c.NormalMin)
+		}
+		page.Rows = append(page.Rows, row)
 		// Anchor each day's point at local noon so the line reads as one
 		// sample per day rather than at midnight edges.
 		noon := time.Date(d.Date.Year(), d.Date.Month(), d.Date.Day(), 12, 0, 0, 0, time.Local)
@@ -499,6 +589,62 @@ func handleForecast(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// forecastAPIResponse is the /api/v1/forecast payload.
+type forecastAPIResponse struct {
+	Location Location
+	Daily    []DailyAggregate
+}
+
+// handleForecastJSON serves the daily outlook with the climate context filled
+// in, for widgets and scripts.
+func handleForecastJSON(w http.ResponseWriter, r *http.Request) {
+	lat, lon, name := locationQuery(r)
+	loc, err := ResolveLocationFor(lat, lon, name)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	daily, err := GetOpenMeteoDailyRange(loc.Latitude, loc.Longitude, parseDaysParam(r))
+	if err != nil {
+		writeJSONError(w, http.StatusBadGateway, err)
+		return
+	}
+	daily = withClimate(loc.Latitude, loc.Longitude, daily)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(forecastAPIResponse{Location: loc, Daily: daily}); err != nil {
+		slog.Log(r.Context(), LevelTrace, "encode forecast response", "err", err)
+	}
+}
+
+// ensembleAPIResponse is the /api/v1/ensemble payload.
+type ensembleAPIResponse struct {
+	Location Location
+	Hours    []EnsembleHour
+	Days     []EnsembleDay
+}
+
+// handleEnsembleJSON serves the ensemble spread hour by hour and day by day:
+// percentiles across members and the chance of rain or a disqualifying gust.
+func handleEnsembleJSON(w http.ResponseWriter, r *http.Request) {
+	lat, lon, name := locationQuery(r)
+	loc, err := ResolveLocationFor(lat, lon, name)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	ens, err := GetOpenMeteoEnsembleDays(loc.Latitude, loc.Longitude, min(parseDaysParam(r), ensembleMaxDays))
+	if err != nil {
+		writeJSONError(w, http.StatusBadGateway, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(ensembleAPIResponse{Location: loc, Hours: ens.Hours(), Days: ens.Days()}); err != nil {
+		slog.Log(r.Context(), LevelTrace, "encode ensemble response", "err", err)
+	}
+}
+
 // formatPrecip renders a precipitation amount for a table cell: blank below a
 // hair (so dry rows stay quiet), one decimal under 10 mm, whole numbers above.
 func formatPrecip(mm float64) string {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"sync"
@@ -40,8 +41,13 @@ type glanceAPIResponse struct {
 	Wind        glanceWindPair `json:"wind"`        // km/h + degrees-from-N
 	UVIndex     glancePair     `json:"uv_index"`    // integer 0..11+
 	Condition   string         `json:"condition"`
-	Sun         []sunEvent     `json:"sun"`    // events within [now, now+2h], empty if none
-	Sunset      string         `json:"sunset"` // next sunset, RFC3339 local; "" if unknown
+	Sun         []sunEvent     `json:"sun"`                // events within [now, now+2h], empty if none
+	Sunset      string         `json:"sunset"`             // next sunset, RFC3339 local; "" if unknown
+	Night       bool           `json:"night"`              // the sun is down now, for day/night condition icons
+	Motion      *Forecast      `json:"motion,omitempty"`   // radar cell-motion nowcast when asked for, see radar_motion.go
+	Analysis    *RainAnalysis  `json:"analysis,omitempty"` // both rain providers merged, see rain_analysis.go
+	Fusion      *RainFusion    `json:"fusion,omitempty"`   // both rain providers blended, see fusion.go
+	Commute     *commuteGlance `json:"commute,omitempty"`  // next leg of the configured commute, /api/v1/glance only
 }
 
 type sunEvent struct {
@@ -73,11 +79,13 @@ func handleGlanceJSON(w http.ResponseWriter, r *http.Request) {
 		writeJSONError(w, http.StatusBadRequest, err)
 		return
 	}
-	resp, err := buildGlanceResponse(r.Context(), loc, NoProgress)
+	// The widget doesn't draw the radar-motion series, so it's opt-in.
+	resp, err := buildGlanceResponse(r.Context(), loc, r.URL.Query().Get("motion") == "1", NoProgress)
 	if err != nil {
 		writeJSONError(w, http.StatusBadGateway, err)
 		return
 	}
+	resp.Commute = glanceCommuteFor(r)
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Cache-Control", "no-store")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -85,26 +93,64 @@ func handleGlanceJSON(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// glanceCommuteFor briefs the configured commute for the widget. The
+// commute is a bonus on top of the glance, so every failure — including no
+// commute being configured — just leaves it out. The briefing only moves
+// with the departure-shift slot, so it's cached per commute and slot.
+func glanceCommuteFor(r *http.Request) *commuteGlance {
+	cfg, err := cachedConfig()
+	if err != nil || cfg.Commute == nil {
+		return nil
+	}
+	c, now := *cfg.Commute, time.Now()
+	key := fmt.Sprintf("%+v|%d", c, now.Truncate(commuteShiftStep).Unix())
+	report, err := memo(commuteGlanceCache, key, func() (*commuteReport, error) {
+		return PlanCommute(c, cfg, now, NoProgress)
+	})
+	if err != nil {
+		slog.Log(r.Context(), LevelTrace, "glance commute", "err", err)
+		return nil
+	}
+	return glanceCommute(report)
+}
+
 // buildGlanceResponse fans out the rain providers + Open-Meteo for `loc` and
 // returns the unified payload consumed by /api/v1/glance, /, and the CLI
 // root command. Returns an error only when every upstream failed; partial
 // results (e.g. radar present but alarm down) are passed through with the
-// missing fields nil/zero so the renderer can degrade gracefully.
-func buildGlanceResponse(ctx context.Context, loc Location, prog Progress) (*glanceAPIResponse, error) {
+// missing fields nil/zero so the renderer can degrade gracefully. The
+// radar-motion nowcast fetches a few dozen radar tiles, so only callers that
+// draw it ask for it with withMotion.
+func buildGlanceResponse(ctx context.Context, loc Location, withMotion bool, prog Progress) (*glanceAPIResponse, error) {
 	var (
 		alarm, radar       *Forecast
 		alarmErr, radarErr error
+		motion             *Forecast
 		meteo              *OpenMeteoData
 		meteoErr           error
 		wg                 sync.WaitGroup
 	)
-	// Open-Meteo is the third unit of work alongside the two rain fetches.
+	// Open-Meteo, and the radar-motion nowcast when asked for, are further
+	// units of work alongside the two rain fetches.
 	prog.AddTotal(1)
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
 		alarm, radar, alarmErr, radarErr = fetchRain(ctx, loc.Latitude, loc.Longitude, prog)
 	}()
+	if withMotion {
+		prog.AddTotal(1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer prog.Inc(1)
+			// Optional: the point providers carry the chart without it.
+			var err error
+			if motion, err = GetRadarNowcastForecast(loc.Latitude, loc.Longitude); err != nil {
+				slog.Debug("radar nowcast unavailable", "err", err)
+			}
+		}()
+	}
 	go func() {
 		defer wg.Done()
 		defer prog.Inc(1)
@@ -121,6 +167,9 @@ func buildGlanceResponse(ctx context.Context, loc Location, prog Progress) (*gla
 		Location:   loc,
 		Buienalarm: alarm,
 		Buineradar: radar,
+		Motion:     motion,
+		Analysis:   AnalyzeRain(alarm, radar, time.Now()),
+		Fusion:     FuseRain(alarm, radar, currentRainTrust(ctx)),
 	}
 
 	if meteo != nil && len(meteo.Hourly) > 0 {
@@ -151,6 +200,7 @@ func buildGlanceResponse(ctx context.Context, loc Location, prog Progress) (*gla
 		resp.Condition = wmoCondition(weatherCodeAt(meteo.Hourly, now))
 		resp.Sun = sunEventsInWindow(meteo.Daily, now, end)
 		resp.Sunset = nextSunset(meteo.Daily, now)
+		resp.Night = isNight(meteo.Daily, now)
 	}
 	return resp, nil
 }
@@ -174,6 +224,22 @@ func nextSunset(daily []DailyForecast, now time.Time) string {
 	return fallback
 }
 
+// isNight reports whether now falls outside today's sunrise–sunset. Without
+// a sun record for today it's false: day icons are the safer guess.
+func isNight(daily []DailyForecast, now time.Time) bool {
+	for _, d := range daily {
+		if d.Sunrise.IsZero() || d.Sunset.IsZero() {
+			continue
+		}
+		local := now.In(d.Sunrise.Location())
+		if d.Sunrise.YearDay() != local.YearDay() || d.Sunrise.Year() != local.Year() {
+			continue
+		}
+		return now.Before(d.Sunrise) || !now.Before(d.Sunset)
+	}
+	return false
+}
+
 // IsDry returns true when both providers stay below the dry threshold across
 // the visible chart window. Radar is capped to Buienalarm's horizon — the
 // window the chart actually shows — so this decision and the chart can never
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// ---------- /history (past weather) ----------
+
+// historyPageData is the hourly page plus the lookup form. The body is the
+// shared hourly body template, reading the embedded fields.
+type historyPageData struct {
+	hourlyPageData
+	Date string // YYYY-MM-DD
+	From string // HH:MM, empty for the whole day
+	To   string
+}
+
+func handleHistory(w http.ResponseWriter, r *http.Request) {
+	lat, lon, name := locationQuery(r)
+	loc, err := ResolveLocationFor(lat, lon, name)
+	if err != nil {
+		http.Error(w, "could not resolve location: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	q := r.URL.Query()
+	zone := locationZone(loc.Latitude, loc.Longitude)
+	// Yesterday by default: the most recent whole day that has happened.
+	day := time.Now().In(zone).AddDate(0, 0, -1)
+	if v := q.Get("date"); v != "" {
+		day, err = time.ParseInLocation("2006-01-02", v, zone)
+		if err != nil {
+			http.Error(w, "date: want YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+	from, to, err := parseHistoryWindow(q.Get("from"), q.Get("to"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	flusher, _ := w.(http.Flusher)
+
+	page := historyPageData{
+		hourlyPageData: hourlyPageData{
+			Location:   loc,
+			Q:          locQuery(loc),
+			NameInput:  name,
+			StartLabel: day.Format("Mon 2 Jan 2006"),
+		},
+		Date: day.Format("2006-01-02"),
+		From: q.Get("from"),
+		To:   q.Get("to"),
+	}
+	if err := historyHeadTmpl.Execute(w, page); err != nil {
+		slog.Debug("template execute", "tmpl", "historyHead", "err", err)
+		return
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	prog := NoProgress
+	if flusher != nil {
+		prog = NewHTTPProgress(w, flusher)
+	}
+	prog.AddTotal(1)
+	hours, source, fetchErr := GetOpenMeteoHistory(loc.Latitude, loc.Longitude, day, day)
+	prog.Inc(1)
+	prog.Finish()
+
+	page.Now = time.Now().Format("15:04:05")
+	rows := clipHours(hours, from, to)
+	if fetchErr != nil || len(rows) == 0 {
+		page.Note = "No past weather for that day and time."
+		if fetchErr != nil {
+			page.Note = "Unable to fetch past weather: " + fetchErr.Error()
+		}
+		if err := hourlyBodyTmpl.Execute(w, page); err != nil {
+			slog.Debug("template execute", "tmpl", "hourlyBody", "err", err)
+		}
+		return
+	}
+	page.Source = source
+	if source == historySourceArchive {
+		page.Source += ", which has no UV index or rain chance"
+	}
+	fillHourlyPage(&page.hourlyPageData, rows)
+
+	if err := hourlyBodyTmpl.Execute(w, page); err != nil {
+		slog.Debug("template execute", "tmpl", "hourlyBody", "err", err)
+	}
+}
@@ -1,13 +1,20 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
+	"image/gif"
 	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// /radar draws its own loop centred on the location (see radar.go); the KNMI
+// relay below stays at /radar.gif for its national overview with lightning.
+//
 // KNMI publishes a ready-composited national radar loop — precipitation +
 // lightning + temperature bubbles + legend, all baked into one animated GIF.
 // It is NL-only and fixed-frame (can't centre on the user), which matches the
@@ -60,3 +67,88 @@ func handleRadarMap(w http.ResponseWriter, r *http.Request) {
 		slog.Log(r.Context(), LevelTrace, "write radar map", "err", werr)
 	}
 }
+
+// parseRadarParams reads /radar's zoom, size and path; out-of-range values
+// fall back to the defaults rather than failing an <img>.
+func parseRadarParams(r *http.Request) (zoom, size int, route [][2]float64) {
+	q := r.URL.Query()
+	zoom, size = radarDefaultZoom, radarDefaultSize
+	if v, err := strconv.Atoi(q.Get("zoom")); err == nil && v >= radarMinZoom && v <= radarMaxZoom {
+		zoom = v
+	}
+	if v, err := strconv.Atoi(q.Get("size")); err == nil && v >= 120 && v <= 800 {
+		size = v
+	}
+	route = parseRadarPath(q.Get("path"))
+	return zoom, size, route
+}
+
+// parseRadarPath reads "lat,lon;lat,lon;…" into points, skipping any that
+// don't parse.
+func parseRadarPath(s string) [][2]float64 {
+	var out [][2]float64
+	for _, p := range strings.Split(s, ";") {
+		la, lo, ok := strings.Cut(p, ",")
+		if !ok {
+			continue
+		}
+		lat, err1 := strconv.ParseFloat(strings.TrimSpace(la), 64)
+		lon, err2 := strconv.ParseFloat(strings.TrimSpace(lo), 64)
+		if err1 != nil || err2 != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+			continue
+		}
+		out = append(out, [2]float64{lat, lon})
+	}
+	return out
+}
+
+// RenderRadarGIF fetches the radar loop and base map around lat/lon and
+// encodes the animated GIF. The base map is optional: without it the rain
+// still draws on a plain background.
+func RenderRadarGIF(lat, lon float64, zoom, size int, route [][2]float64) ([]byte, error) {
+	frames, err := GetRadarFrames(lat, lon, zoom, size, size)
+	if err != nil {
+		return nil, err
+	}
+	base, err := getRadarBasemap(zoom, frames[0].X0, frames[0].Y0, size, size)
+	if err != nil {
+		slog.Debug("radar: base map unavailable", "err", err)
+		base = nil
+	}
+	px := make([][2]float64, len(route))
+	for i, p := range route {
+		px[i][0], px[i][1] = mercatorPixel(p[0], p[1], zoom)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, renderRadarGIF(frames, base, px)); err != nil {
+		return nil, fmt.Errorf("encode radar gif: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// handleRadar serves the self-rendered radar loop for
+// /radar?lat=..&lon=..&zoom=..&size=..&path=... Like /radar.gif it is an
+// image, so failures are a bare 502 rather than a JSON error.
+func handleRadar(w http.ResponseWriter, r *http.Request) {
+	lat, lon, name := locationQuery(r)
+	loc, err := ResolveLocationFor(lat, lon, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	zoom, size, route := parseRadarParams(r)
+	key := fmt.Sprintf("%.3f|%.3f|%d|%d|%v", loc.Latitude, loc.Longitude, zoom, size, route)
+	img, err := memo(radarGIFCache, key, func() ([]byte, error) {
+		return RenderRadarGIF(loc.Latitude, loc.Longitude, zoom, size, route)
+	})
+	if err != nil {
+		slog.Debug("radar: render failed", "err", err)
+		http.Error(w, "radar unavailable", http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "image/gif")
+	w.Header().Set("Cache-Control", "public, max-age=120")
+	if _, werr := w.Write(img); werr != nil {
+		slog.Log(r.Context(), LevelTrace, "write radar", "err", werr)
+	}
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ---------- /ride (recorded ride upload) ----------
+
+// rideMaxUpload caps a track upload; a day-long FIT file is a few MB.
+const rideMaxUpload = 32 << 20
+
+type rideRow struct {
+	Km        string
+	Time      string
+	Heading   string
+	Missing   bool
+	WindArrow string
+	WindKmh   int
+	WindClass string
+	Along     int
+	AlongCls  string // g-mi-caution for a real headwind, water for a real tailwind
+	Temp      int
+	Precip    string
+}
+
+type ridePageData struct {
+	Q         template.URL
+	SegmentKm float64
+	FileName  string
+	Title     string
+	Summary   []string
+	ChartSVG  template.HTML
+	Rows      []rideRow
+	Note      string
+	Now       string
+}
+
+func handleRide(w http.ResponseWriter, r *http.Request) {
+	page := ridePageData{
+		Q:         navQuery(r.URL.Query()),
+		SegmentKm: 1,
+		Now:       time.Now().Format("15:04:05"),
+	}
+	if r.Method == http.MethodPost {
+		fillRidePage(w, r, &page)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := rideTmpl.Execute(w, page); err != nil {
+		slog.Debug("template execute", "tmpl", "ride", "err", err)
+	}
+}
+
+// fillRidePage reads the uploaded track and builds the report into page,
+// leaving a Note instead when anything goes wrong.
+func fillRidePage(w http.ResponseWriter, r *http.Request, page *ridePageData) {
+	r.Body = http.MaxBytesReader(w, r.Body, rideMaxUpload)
+	if err := r.ParseMultipartForm(rideMaxUpload); err != nil {
+		page.Note = "Could not read the upload: " + err.Error()
+		return
+	}
+	if v, err := strconv.ParseFloat(r.FormValue("segment"), 64); err == nil && v >= 0.2 && v <= 50 {
+		page.SegmentKm = v
+	}
+	f, hdr, err := r.FormFile("track")
+	if err != nil {
+		page.Note = "Choose a .gpx or .fit file to upload."
+		return
+	}
+	defer closeBody(f, "ride upload")
+	page.FileName = hdr.Filename
+
+	pts, err := parseTrack(hdr.Filename, f)
+	if err != nil {
+		page.Note = err.Error()
+		return
+	}
+	report, err := buildRideReport(pts, page.SegmentKm, NoProgress)
+	if err != nil {
+		page.Note = "Ride report: " + err.Error()
+		return
+	}
+
+	page.Title = fmt.Sprintf("Ride of %s · %s → %s · %.1f km",
+		report.Start.Format("Mon 2 Jan 2006"), report.Start.Format("15:04"), report.End.Format("15:04"), report.DistanceKm)
+	page.Summary = rideSummaryLines(report)
+	page.ChartSVG = rideChartSVG(report)
+	for _, s := range report.Segments {
+		row := rideRow{
+			Km:      fmt.Sprintf("%.1f–%.1f", s.FromKm, s.ToKm),
+			Time:    s.Start.Format("15:04"),
+			Heading: CompassArrow(s.Bearing) + " " + CompassName(s.Bearing),
+			Missing: !s.HasWeather,
+		}
+		if s.HasWeather {
+			row.WindArrow = windArrowFor(int(round(s.WindDir)))
+			row.WindKmh = int(round(s.WindSpeed))
+			row.WindClass = windClassFor(row.WindKmh)
+			row.Along = int(round(s.Tailwind))
+			row.AlongCls = "muted"
+			if math.Abs(s.Tailwind) >= windCalmKmh {
+				row.AlongCls = "water"
+				if s.Tailwind < 0 {
+					row.AlongCls = "g-mi-caution"
+				}
+			}
+			row.Temp = int(round(s.Temp))
+			row.Precip = formatPrecip(s.Precip)
+		}
+		page.Rows = append(page.Rows, row)
+	}
+}
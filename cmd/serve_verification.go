@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ---------- /verification (forecast accuracy) ----------
+
+type verificationRow struct {
+	Provider string
+	Lead     string
+	N        int
+	MAE      string
+	Bias     string
+	RMSE     string
+	Agree    string // "—" for temperature
+	Best     bool   // lowest MAE among the providers at this lead
+}
+
+type verificationTable struct {
+	Title string
+	Unit  string
+	Rows  []verificationRow
+}
+
+type verificationPageData struct {
+	Q            template.URL
+	Place        string
+	Places       []string
+	Tables       []verificationTable
+	Snapshots    int
+	Observations int
+	Since        string // first snapshot, empty when nothing is recorded
+	Note         string
+	Now          string
+}
+
+func handleVerification(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page := verificationPageData{
+		Q:     navQuery(q),
+		Place: q.Get("place"),
+		Now:   time.Now().Format("15:04:05"),
+	}
+
+	store, err := openVerificationStore()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	score, err := scoreVerification(store, page.Place)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	page.Places = score.Places
+	page.Snapshots, page.Observations = score.Snapshots, score.Observations
+	if !score.Since.IsZero() {
+		page.Since = score.Since.Format("Mon 2 Jan 15:04")
+	}
+
+	page.Tables = verificationTables(score.Metrics)
+	switch {
+	case score.Snapshots == 0:
+		page.Note = "Nothing recorded yet. Run the server with --record-every 10m, or weather record from cron, for the places saved in the config file."
+	case len(page.Tables) == 0:
+		page.Note = "No forecast has been matched to an observation yet. Nowcasts score after about two hours; Open-Meteo after the reanalysis catches up, roughly five days."
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := verificationTmpl.Execute(w, page); err != nil {
+		slog.Debug("template execute", "tmpl", "verification", "err", err)
+	}
+}
+
+// verificationScore is what /verification shows for one place (or all).
+type verificationScore struct {
+	Places       []string
+	Snapshots    int
+	Observations int
+	Since        time.Time // first snapshot; zero when nothing is recorded
+	Metrics      []verificationMetric
+}
+
+// scoreVerification reads and scores the whole store, which grows with every
+// recording pass, so the result is cached per place (verificationCache).
+func scoreVerification(store *verificationStore, place string) (*verificationScore, error) {
+	return memo(verificationCache, place, func() (*verificationScore, error) {
+		snaps, err := store.Snapshots()
+		if err != nil {
+			return nil, err
+		}
+		obs, err := store.Observations()
+		if err != nil {
+			return nil, err
+		}
+		score := &verificationScore{
+			Places:       verificationPlaces(snaps),
+			Snapshots:    len(snaps),
+			Observations: len(obs),
+			Metrics:      computeVerification(snaps, obs, place),
+		}
+		for _, s := range snaps {
+			if score.Since.IsZero() || s.IssuedAt.Before(score.Since) {
+				score.Since = s.IssuedAt
+			}
+		}
+		return score, nil
+	})
+}
+
+// verificationTables splits the metrics into one table per variable and marks
+// the provider with the lowest MAE at each lead.
+func verificationTables(metrics []verificationMetric) []verificationTable {
+	best := map[string]float64{}
+	for _, m := range metrics {
+		k := m.Variable + "|" + m.Lead
+		if v, ok := best[k]; !ok || m.MAE < v {
+			best[k] = m.MAE
+		}
+	}
+	var out []verificationTable
+	variable := ""
+	for _, m := range metrics {
+		if m.Variable != variable {
+			variable = m.Variable
+			t := verificationTable{Title: "Precipitation", Unit: "mm/h"}
+			if variable == varTemp {
+				t = verificationTable{Title: "Temperature", Unit: "°C"}
+			}
+			out = append(out, t)
+		}
+		row := verificationRow{
+			Provider: m.Provider,
+			Lead:     m.Lead,
+			N:        m.N,
+			MAE:      fmt.Sprintf("%.2f", m.MAE),
+			Bias:     fmt.Sprintf("%+.2f", m.Bias),
+			RMSE:     fmt.Sprintf("%.2f", m.RMSE),
+			Agree:    "—",
+			Best:     m.MAE == best[m.Variable+"|"+m.Lead],
+		}
+		if m.Agree >= 0 {
+			row.Agree = fmt.Sprintf("%.0f%%", m.Agree*100)
+		}
+		out[len(out)-1].Rows = append(out[len(out)-1].Rows, row)
+	}
+	return out
+}
+
+// navQuery keeps the location parameters of the request for the nav links on
+// pages that aren't tied to one location themselves.
+func navQuery(q url.Values) template.URL {
+	keep := url.Values{}
+	for _, k := range []string{"lat", "lon", "name"} {
+		if v := q.Get(k); v != "" {
+			keep.Set(k, v)
+		}
+	}
+	return template.URL(keep.Encode())
+}
@@ -10,9 +10,11 @@ import (
 
 // SVGSeries is a single named, colored line for RenderLineChartSVG.
 type SVGSeries struct {
-	Name  string
-	Color string
-	Data  []ForecastDataPoint
+	Name   string
+	Color  string
+	Data   []ForecastDataPoint
+	Dashed bool // draw as a dashed line, e.g. a derived consensus over the raw series
+	NoFill bool // leave out of FillArea, e.g. a line derived from the filled ones
 }
 
 // SVGOpts controls the SVG chart geometry and labels.
@@ -31,6 +33,42 @@ type SVGOpts struct {
 	// SunEvents draws a vertical hairline plus a small glyph (↑ sunrise,
 	// ↓ sunset) at each event whose time falls within the chart's x range.
 	SunEvents []SVGSunEvent
+
+	// Highlights shades time spans across the full plot height — used to
+	// flag where the compared models disagree. Spans outside the chart's x
+	// range are clipped.
+	Highlights []SVGSpan
+
+	// Markers draw a labelled vertical hairline, e.g. where one source
+	// hands over to the next.
+	Markers []SVGMarker
+
+	// Bands shade a range around the series, e.g. the spread between the
+	// rain providers around their blend. Drawn under the series.
+	Bands []SVGBand
+}
+
+// SVGSpan is a [From, To] time range shaded behind the series.
+type SVGSpan struct {
+	From, To time.Time
+}
+
+// SVGMarker is a labelled vertical hairline at Time.
+type SVGMarker struct {
+	Time  time.Time
+	Label string
+}
+
+// SVGBand shades the range between Lo and Hi over time.
+type SVGBand struct {
+	Color string
+	Data  []SVGBandPoint
+}
+
+// SVGBandPoint is one step of an SVGBand.
+type SVGBandPoint struct {
+	Time   time.Time
+	Lo, Hi float64
 }
 
 // SVGSunEvent is a sunrise or sunset marker drawn as a vertical hairline +
@@ -40,13 +78,20 @@ type SVGSunEvent struct {
 	Time time.Time
 }
 
-// RenderLineChartSVG returns an inline SVG <svg> element containing a
-// time-series line chart for one or more series. The result is marked as safe
-// HTML so it can be embedded directly in a template.
-//
-// Axis/text colors use `currentColor`, so the surrounding CSS controls them
-// and the chart respects light/dark theme.
-func RenderLineChartSVG(series []SVGSeries, opts SVGOpts) template.HTML {
+// lineChartLayout is the geometry shared by RenderLineChartSVG and
+// RenderLineChartPNG: padding, the combined extents of the series, and the
+// "nice" y range and ticks. Empty is set when no series has data.
+type lineChartLayout struct {
+	Opts                   SVGOpts
+	PadL, PadR, PadT, PadB int
+	PlotW, PlotH           int
+	MinT, MaxT             time.Time
+	YLo, YHi, TickStep     float64
+	TickFmt                string
+	Empty                  bool
+}
+
+func newLineChartLayout(series []SVGSeries, opts SVGOpts) lineChartLayout {
 	if opts.Width == 0 {
 		opts.Width = 640
 	}
@@ -59,47 +104,47 @@ func RenderLineChartSVG(series []SVGSeries, opts SVGOpts) template.HTML {
 
 	// padL must fit the widest y-axis label. With "nice" tick values we top
 	// out at strings like "100" or "12.5" — ~5 chars × ~7 px + 6 px gap.
-	const padL, padR, padT, padB = 44, 12, 22, 28
-	plotW := opts.Width - padL - padR
-	plotH := opts.Height - padT - padB
-
-	var b strings.Builder
-	fmt.Fprintf(&b,
-		`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" preserveAspectRatio="xMidYMid meet" role="img" aria-label="forecast chart" style="width:100%%;height:auto;font:12px system-ui,sans-serif">`,
-		opts.Width, opts.Height)
+	l := lineChartLayout{Opts: opts, PadL: 44, PadR: 12, PadT: 22, PadB: 28}
+	l.PlotW = opts.Width - l.PadL - l.PadR
+	l.PlotH = opts.Height - l.PadT - l.PadB
 
 	// Combined extents across non-empty series.
 	var (
-		minT, maxT time.Time
 		minV, maxV float64
 		any        bool
 	)
+	extend := func(t time.Time, v float64) {
+		if !any {
+			l.MinT, l.MaxT, minV, maxV, any = t, t, v, v, true
+			return
+		}
+		if t.Before(l.MinT) {
+			l.MinT = t
+		}
+		if t.After(l.MaxT) {
+			l.MaxT = t
+		}
+		if v < minV {
+			minV = v
+		}
+		if v > maxV {
+			maxV = v
+		}
+	}
 	for _, s := range series {
 		for _, p := range s.Data {
-			if !any {
-				minT, maxT, minV, maxV, any = p.Time, p.Time, p.Value, p.Value, true
-				continue
-			}
-			if p.Time.Before(minT) {
-				minT = p.Time
-			}
-			if p.Time.After(maxT) {
-				maxT = p.Time
-			}
-			if p.Value < minV {
-				minV = p.Value
-			}
-			if p.Value > maxV {
-				maxV = p.Value
-			}
+			extend(p.Time, p.Value)
+		}
+	}
+	for _, b := range opts.Bands {
+		for _, p := range b.Data {
+			extend(p.Time, p.Lo)
+			extend(p.Time, p.Hi)
 		}
 	}
-
 	if !any {
-		fmt.Fprintf(&b,
-			`<text x="%d" y="%d" text-anchor="middle" fill="currentColor" opacity="0.6">no data</text></svg>`,
-			opts.Width/2, opts.Height/2)
-		return template.HTML(b.String())
+		l.Empty = true
+		return l
 	}
 
 	// Pad Y range so lines don't sit on the axes; ensure non-zero span.
@@ -120,29 +165,109 @@ func RenderLineChartSVG(series []SVGSeries, opts SVGOpts) template.HTML {
 	}
 	// Snap yLo/yHi to "nice" round values (1/2/5 × 10^k) so tick labels read
 	// like 0, 0.5, 1.0 rather than 0.02, 0.04, …
-	tickStep := niceStep(yHi-yLo, 5)
-	yLo = math.Floor(yLo/tickStep) * tickStep
-	yHi = math.Ceil(yHi/tickStep) * tickStep
-	if yHi == yLo {
-		yHi = yLo + tickStep
+	l.TickStep = niceStep(yHi-yLo, 5)
+	l.YLo = math.Floor(yLo/l.TickStep) * l.TickStep
+	l.YHi = math.Ceil(yHi/l.TickStep) * l.TickStep
+	if l.YHi == l.YLo {
+		l.YHi = l.YLo + l.TickStep
 	}
 	// Decimal places appropriate for the tick step.
 	tickDecimals := 0
-	if tickStep < 1 {
-		tickDecimals = int(math.Ceil(-math.Log10(tickStep)))
+	if l.TickStep < 1 {
+		tickDecimals = int(math.Ceil(-math.Log10(l.TickStep)))
 	}
-	tickFmt := fmt.Sprintf("%%.%df", tickDecimals)
+	l.TickFmt = fmt.Sprintf("%%.%df", tickDecimals)
+	return l
+}
 
-	xPx := func(t time.Time) float64 {
-		span := float64(maxT.Sub(minT))
-		if span == 0 {
-			return float64(padL)
-		}
-		return float64(padL) + float64(t.Sub(minT))/span*float64(plotW)
+// X maps a time onto the plot.
+func (l lineChartLayout) X(t time.Time) float64 {
+	span := float64(l.MaxT.Sub(l.MinT))
+	if span == 0 {
+		return float64(l.PadL)
 	}
-	yPx := func(v float64) float64 {
-		return float64(padT) + float64(plotH) - (v-yLo)/(yHi-yLo)*float64(plotH)
+	return float64(l.PadL) + float64(t.Sub(l.MinT))/span*float64(l.PlotW)
+}
+
+// Y maps a value onto the plot.
+func (l lineChartLayout) Y(v float64) float64 {
+	return float64(l.PadT) + float64(l.PlotH) - (v-l.YLo)/(l.YHi-l.YLo)*float64(l.PlotH)
+}
+
+// YTicks walks YLo → YHi in TickStep increments.
+func (l lineChartLayout) YTicks() []float64 {
+	var out []float64
+	for v := l.YLo; v <= l.YHi+l.TickStep/2; v += l.TickStep {
+		out = append(out, v)
+	}
+	return out
+}
+
+// XTicks picks a step that gives ~4-7 labels across the visible span.
+// A 2-hour chart on hour-only ticks ends up with two labels (e.g. 12:00,
+// 13:00); 30-minute ticks here keep the chart readable.
+func (l lineChartLayout) XTicks() []time.Time {
+	step := pickTimeTickStep(l.MaxT.Sub(l.MinT))
+	startTick := l.MinT.Truncate(step)
+	if startTick.Before(l.MinT) {
+		startTick = startTick.Add(step)
+	}
+	var out []time.Time
+	for t := startTick; !t.After(l.MaxT); t = t.Add(step) {
+		out = append(out, t)
+	}
+	return out
+}
+
+// Span clips a highlight to the chart's x range; ok is false when nothing
+// of it is visible.
+func (l lineChartLayout) Span(h SVGSpan) (from, to time.Time, ok bool) {
+	from, to = h.From, h.To
+	if from.Before(l.MinT) {
+		from = l.MinT
+	}
+	if to.After(l.MaxT) {
+		to = l.MaxT
+	}
+	return from, to, to.After(from)
+}
+
+// Visible reports whether t falls within the chart's x range.
+func (l lineChartLayout) Visible(t time.Time) bool {
+	return !t.Before(l.MinT) && !t.After(l.MaxT)
+}
+
+// sunGlyph is the marker drawn for a sun event.
+func sunGlyph(kind string) string {
+	if kind == "sunset" {
+		return "↓"
+	}
+	return "↑"
+}
+
+// RenderLineChartSVG returns an inline SVG <svg> element containing a
+// time-series line chart for one or more series. The result is marked as safe
+// HTML so it can be embedded directly in a template.
+//
+// Axis/text colors use `currentColor`, so the surrounding CSS controls them
+// and the chart respects light/dark theme.
+func RenderLineChartSVG(series []SVGSeries, opts SVGOpts) template.HTML {
+	l := newLineChartLayout(series, opts)
+	opts = l.Opts
+	padL, padT, plotW, plotH := l.PadL, l.PadT, l.PlotW, l.PlotH
+
+	var b strings.Builder
+	fmt.Fprintf(&b,
+		`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" preserveAspectRatio="xMidYMid meet" role="img" aria-label="forecast chart" style="width:100%%;height:auto;font:12px system-ui,sans-serif">`,
+		opts.Width, opts.Height)
+
+	if l.Empty {
+		fmt.Fprintf(&b,
+			`<text x="%d" y="%d" text-anchor="middle" fill="currentColor" opacity="0.6">no data</text></svg>`,
+			opts.Width/2, opts.Height/2)
+		return template.HTML(b.String())
 	}
+	xPx, yPx := l.X, l.Y
 
 	// Axes.
 	fmt.Fprintf(&b,
@@ -150,24 +275,45 @@ func RenderLineChartSVG(series []SVGSeries, opts SVGOpts) template.HTML {
 		padL, padT, padL, padT+plotH,
 		padL, padT+plotH, padL+plotW, padT+plotH)
 
+	for _, h := range opts.Highlights {
+		from, to, ok := l.Span(h)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b,
+			`<rect x="%.1f" y="%d" width="%.1f" height="%d" fill="#f59e0b" fill-opacity="0.15"/>`,
+			xPx(from), padT, xPx(to)-xPx(from), plotH)
+	}
+
 	// Sun event markers — vertical hairline plus a small glyph above the
 	// top of the plot. Drawn before the series so the rain lines overlay
 	// cleanly; the glyph above sits clear of everything.
 	for _, ev := range opts.SunEvents {
-		if ev.Time.Before(minT) || ev.Time.After(maxT) {
+		if !l.Visible(ev.Time) {
 			continue
 		}
 		x := xPx(ev.Time)
 		fmt.Fprintf(&b,
 			`<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="currentColor" stroke-opacity="0.35" stroke-dasharray="2,3"/>`,
 			x, padT, x, padT+plotH)
-		glyph := "↑"
-		if ev.Kind == "sunset" {
-			glyph = "↓"
-		}
 		fmt.Fprintf(&b,
 			`<text x="%.1f" y="%d" text-anchor="middle" fill="currentColor" opacity="0.75">%s %s</text>`,
-			x, padT-6, glyph, template.HTMLEscapeString(ev.Time.Format(opts.XTimeFormat)))
+			x, padT-6, sunGlyph(ev.Kind), template.HTMLEscapeString(ev.Time.Format(opts.XTimeFormat)))
+	}
+
+	// Markers: like the sun events, but solid and labelled to the right of
+	// the line so the label reads as "what follows".
+	for _, m := range opts.Markers {
+		if !l.Visible(m.Time) {
+			continue
+		}
+		x := xPx(m.Time)
+		fmt.Fprintf(&b,
+			`<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="currentColor" stroke-opacity="0.5"/>`,
+			x, padT, x, padT+plotH)
+		fmt.Fprintf(&b,
+			`<text x="%.1f" y="%d" text-anchor="start" fill="currentColor" opacity="0.75">%s</text>`,
+			x+4, padT+12, template.HTMLEscapeString(m.Label))
 	}
 
 	// Unit caption above the y-axis (printed once instead of on every tick).
@@ -177,26 +323,17 @@ func RenderLineChartSVG(series []SVGSeries, opts SVGOpts) template.HTML {
 			padL-6, padT-6, template.HTMLEscapeString(strings.TrimSpace(opts.YUnit)))
 	}
 
-	// Y ticks: walk yLo → yHi in tickStep increments.
-	for v := yLo; v <= yHi+tickStep/2; v += tickStep {
+	for _, v := range l.YTicks() {
 		y := yPx(v)
 		fmt.Fprintf(&b,
 			`<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="currentColor" stroke-opacity="0.1"/>`,
 			padL, y, padL+plotW, y)
 		fmt.Fprintf(&b,
 			`<text x="%d" y="%.1f" text-anchor="end" dominant-baseline="middle" fill="currentColor" opacity="0.7">%s</text>`,
-			padL-6, y, template.HTMLEscapeString(fmt.Sprintf(tickFmt, v)))
+			padL-6, y, template.HTMLEscapeString(fmt.Sprintf(l.TickFmt, v)))
 	}
 
-	// X ticks: pick a step that gives ~4-7 labels across the visible span.
-	// A 2-hour chart on hour-only ticks ends up with two labels (e.g. 12:00,
-	// 13:00); 30-minute ticks here keep the chart readable.
-	step := pickTimeTickStep(maxT.Sub(minT))
-	startTick := minT.Truncate(step)
-	if startTick.Before(minT) {
-		startTick = startTick.Add(step)
-	}
-	for t := startTick; !t.After(maxT); t = t.Add(step) {
+	for _, t := range l.XTicks() {
 		x := xPx(t)
 		fmt.Fprintf(&b,
 			`<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="currentColor" stroke-opacity="0.15"/>`,
@@ -206,6 +343,24 @@ func RenderLineChartSVG(series []SVGSeries, opts SVGOpts) template.HTML {
 			x, padT+plotH+16, template.HTMLEscapeString(t.Format(opts.XTimeFormat)))
 	}
 
+	// Bands go under the series: the hi edge left to right, then the lo edge
+	// back.
+	for _, band := range opts.Bands {
+		if len(band.Data) < 2 {
+			continue
+		}
+		var pts strings.Builder
+		for _, p := range band.Data {
+			fmt.Fprintf(&pts, "%.1f,%.1f ", xPx(p.Time), yPx(p.Hi))
+		}
+		for i := len(band.Data) - 1; i >= 0; i-- {
+			fmt.Fprintf(&pts, "%.1f,%.1f ", xPx(band.Data[i].Time), yPx(band.Data[i].Lo))
+		}
+		fmt.Fprintf(&b,
+			`<polygon fill="%s" fill-opacity="0.15" stroke="none" points="%s"/>`,
+			template.HTMLEscapeString(band.Color), strings.TrimSpace(pts.String()))
+	}
+
 	// Series lines (plus optional filled area underneath).
 	yBase := float64(padT + plotH)
 	for _, s := range series {
@@ -219,7 +374,7 @@ func RenderLineChartSVG(series []SVGSeries, opts SVGOpts) template.HTML {
 			}
 			fmt.Fprintf(&pts, "%.1f,%.1f", xPx(p.Time), yPx(p.Value))
 		}
-		if opts.FillArea && len(s.Data) >= 2 {
+		if opts.FillArea && !s.NoFill && len(s.Data) >= 2 {
 			// Build a closed polygon: walk the points then drop to the
 			// baseline at the right edge and left edge. ~22% alpha so the
 			// fill adds visual weight without overpowering the stroke.
@@ -231,9 +386,13 @@ func RenderLineChartSVG(series []SVGSeries, opts SVGOpts) template.HTML {
 				`<polygon fill="%s" fill-opacity="0.22" stroke="none" points="%s"/>`,
 				template.HTMLEscapeString(s.Color), area.String())
 		}
+		dash := ""
+		if s.Dashed {
+			dash = ` stroke-dasharray="6,4"`
+		}
 		fmt.Fprintf(&b,
-			`<polyline fill="none" stroke="%s" stroke-width="2" stroke-linecap="round" stroke-linejoin="round" points="%s"/>`,
-			template.HTMLEscapeString(s.Color), pts.String())
+			`<polyline fill="none" stroke="%s" stroke-width="2" stroke-linecap="round" stroke-linejoin="round"%s points="%s"/>`,
+			template.HTMLEscapeString(s.Color), dash, pts.String())
 	}
 
 	b.WriteString(`</svg>`)
@@ -305,6 +464,66 @@ type GridOpts struct {
 	CellSize int     // px per cell in the viewBox; default 22
 	StepKm   float64 // optional — when >0, axis labels show ±km on edges
 	Title    string  // optional caption shown above the grid
+	Paths    []GridPath
+}
+
+// GridPath is a polyline drawn over the grid, e.g. a suggested loop. Points
+// are fractional (row, col) positions where (0, 0) is the centre of the
+// top-left cell. Label, when set, is written at the second point so the
+// direction of travel reads from the start.
+type GridPath struct {
+	Points [][2]float64
+	Color  string
+	Label  string
+	Dashed bool
+}
+
+// heatGridLayout is the geometry shared by RenderHeatGridSVG and
+// RenderHeatGridPNG.
+type heatGridLayout struct {
+	Opts       GridOpts
+	Rows, Cols int
+	Mid        int
+	PadL, PadT int
+	W, H       int
+}
+
+func newHeatGridLayout(cells [][]GridCell, opts GridOpts) heatGridLayout {
+	if opts.CellSize == 0 {
+		opts.CellSize = 22
+	}
+	l := heatGridLayout{Opts: opts, Rows: len(cells), Cols: len(cells[0]), PadL: 36, PadT: 16}
+	l.Mid = l.Rows / 2
+	padR, padB := 16, 16
+	if opts.Title != "" {
+		l.PadT += 18
+	}
+	l.W = l.PadL + l.Cols*opts.CellSize + padR
+	l.H = l.PadT + l.Rows*opts.CellSize + padB
+	return l
+}
+
+// Cell is the top-left corner of cell (r, c).
+func (l heatGridLayout) Cell(r, c int) (x, y int) {
+	return l.PadL + c*l.Opts.CellSize, l.PadT + r*l.Opts.CellSize
+}
+
+// Point maps a fractional (row, col) path point onto the grid.
+func (l heatGridLayout) Point(pt [2]float64) (x, y float64) {
+	return float64(l.PadL) + (pt[1]+0.5)*float64(l.Opts.CellSize),
+		float64(l.PadT) + (pt[0]+0.5)*float64(l.Opts.CellSize)
+}
+
+// RowLabels are the km labels along the left edge: top row (north edge),
+// bottom row (south edge), plus middle = start. Only edges and centre, to
+// reduce clutter.
+func (l heatGridLayout) RowLabels() map[int]string {
+	km := int(float64(l.Mid) * l.Opts.StepKm)
+	return map[int]string{
+		0:          fmt.Sprintf("+%d km", km),
+		l.Mid:      "start",
+		l.Rows - 1: fmt.Sprintf("-%d km", km),
+	}
 }
 
 // RenderHeatGridSVG draws cells[row][col] as a square grid. Row 0 is at the
@@ -314,26 +533,15 @@ func RenderHeatGridSVG(cells [][]GridCell, opts GridOpts) template.HTML {
 	if len(cells) == 0 || len(cells[0]) == 0 {
 		return template.HTML(`<svg viewBox="0 0 1 1"></svg>`)
 	}
-	if opts.CellSize == 0 {
-		opts.CellSize = 22
-	}
-	rows := len(cells)
-	cols := len(cells[0])
-	mid := rows / 2
-
-	padL := 36
-	padT := 16
-	padR, padB := 16, 16
-	if opts.Title != "" {
-		padT += 18
-	}
-	w := padL + cols*opts.CellSize + padR
-	h := padT + rows*opts.CellSize + padB
+	l := newHeatGridLayout(cells, opts)
+	opts = l.Opts
+	rows, cols, mid := l.Rows, l.Cols, l.Mid
+	padL, padT := l.PadL, l.PadT
 
 	var b strings.Builder
 	fmt.Fprintf(&b,
 		`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" preserveAspectRatio="xMidYMid meet" role="img" style="width:100%%;height:auto;max-width:520px;font:11px system-ui,sans-serif">`,
-		w, h)
+		l.W, l.H)
 
 	if opts.Title != "" {
 		fmt.Fprintf(&b,
@@ -341,15 +549,9 @@ func RenderHeatGridSVG(cells [][]GridCell, opts GridOpts) template.HTML {
 			padL, template.HTMLEscapeString(opts.Title))
 	}
 
-	// Axis: N/S km labels along left edge (one per row, only at edges and center to reduce clutter).
+	// Axis: N/S km labels along left edge.
 	if opts.StepKm > 0 {
-		// Top row (north edge) and bottom row (south edge), plus middle = start.
-		labelRows := map[int]string{
-			0:        fmt.Sprintf("+%d km", int(float64(mid)*opts.StepKm)),
-			mid:      "start",
-			rows - 1: fmt.Sprintf("-%d km", int(float64(mid)*opts.StepKm)),
-		}
-		for r, txt := range labelRows {
+		for r, txt := range l.RowLabels() {
 			y := padT + r*opts.CellSize + opts.CellSize/2 + 4
 			fmt.Fprintf(&b,
 				`<text x="%d" y="%d" text-anchor="end" fill="currentColor" opacity="0.7">%s</text>`,
@@ -369,8 +571,7 @@ func RenderHeatGridSVG(cells [][]GridCell, opts GridOpts) template.HTML {
 	for r := 0; r < rows; r++ {
 		for c := 0; c < cols; c++ {
 			cell := cells[r][c]
-			x := padL + c*opts.CellSize
-			y := padT + r*opts.CellSize
+			x, y := l.Cell(r, c)
 			fmt.Fprintf(&b,
 				`<rect x="%d" y="%d" width="%d" height="%d" fill="%s"`,
 				x, y, opts.CellSize, opts.CellSize, template.HTMLEscapeString(cell.Color))
@@ -412,8 +613,7 @@ func RenderHeatGridSVG(cells [][]GridCell, opts GridOpts) template.HTML {
 			if !cells[r][c].Water {
 				continue
 			}
-			x := padL + c*opts.CellSize
-			y := padT + r*opts.CellSize
+			x, y := l.Cell(r, c)
 			// Stroke each side that faces land (or an out-of-grid neighbour
 			// that is not itself water, i.e. the inland map edge).
 			if !water(r-1, c) { // top
@@ -435,6 +635,39 @@ func RenderHeatGridSVG(cells [][]GridCell, opts GridOpts) template.HTML {
 		}
 	}
 
+	// Paths go over everything, later ones underneath earlier ones so the
+	// first (best) stays fully visible where they overlap.
+	for i := len(opts.Paths) - 1; i >= 0; i-- {
+		p := opts.Paths[i]
+		if len(p.Points) < 2 {
+			continue
+		}
+		xy := l.Point
+		b.WriteString(`<polyline fill="none" stroke-linejoin="round" points="`)
+		for j, pt := range p.Points {
+			x, y := xy(pt)
+			if j > 0 {
+				b.WriteByte(' ')
+			}
+			fmt.Fprintf(&b, "%.1f,%.1f", x, y)
+		}
+		width := 3
+		if i > 0 {
+			width = 2
+		}
+		fmt.Fprintf(&b, `" stroke="%s" stroke-width="%d"`, template.HTMLEscapeString(p.Color), width)
+		if p.Dashed {
+			b.WriteString(` stroke-dasharray="6 4"`)
+		}
+		b.WriteString(`/>`)
+		if p.Label != "" {
+			x, y := xy(p.Points[1])
+			fmt.Fprintf(&b,
+				`<circle cx="%.1f" cy="%.1f" r="8" fill="%s"/><text x="%.1f" y="%.1f" text-anchor="middle" dominant-baseline="central" fill="#fff" font-weight="700">%s</text>`,
+				x, y, template.HTMLEscapeString(p.Color), x, y+1, template.HTMLEscapeString(p.Label))
+		}
+	}
+
 	b.WriteString(`</svg>`)
 	return template.HTML(b.String())
 }
@@ -2,7 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"log/slog"
 	"math"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -16,6 +19,10 @@ var (
 	FlagTodayStart  string
 	FlagTodayRadius float64
 	FlagTodayGrid   int
+	FlagTodayLoopKm float64
+	FlagTodaySpeed  float64
+	FlagTodayGPX    string
+	FlagTodayPNG    string
 )
 
 // Shared defaults for the today ride-window heatmap. The CLI flags and the
@@ -32,7 +39,14 @@ var todayCmd = &cobra.Command{
 	Long: `today renders a compact weather heatmap around your location covering the
 next few hours. Each cell's background colour tells you when rain arrives
 during your ride window; the symbol shows the wind direction and strength.
-Useful for "it's 10am, I'm thinking about a ride tonight — where's dry?"`,
+Useful for "it's 10am, I'm thinking about a ride tonight — where's dry?"
+For a ride starting within two hours, the first hours' rain comes from
+tracking the cells on the radar rather than the hourly model alone.
+
+With --loop-km it also plans loops of that length from your door: out-and-
+backs and triangles, scored hour by hour against the rain and wind in each
+cell they pass through at --speed, preferring the ones that head out into the
+wind and come home with it, dry. --gpx writes the best one as a GPX track.`,
 	RunE: runToday,
 }
 
@@ -42,6 +56,10 @@ func init() {
 	todayCmd.Flags().StringVar(&FlagTodayStart, "start", "", "ride start time HH:MM (default: now + 30 min rounded up)")
 	todayCmd.Flags().Float64Var(&FlagTodayRadius, "radius", todayDefaultRadius, "map radius in km")
 	todayCmd.Flags().IntVar(&FlagTodayGrid, "grid", todayDefaultGrid, "heatmap resolution (NxN; odd, clamped to ≥5)")
+	todayCmd.Flags().Float64Var(&FlagTodayLoopKm, "loop-km", 0, "also plan loops of this length, km (0 = off)")
+	todayCmd.Flags().Float64Var(&FlagTodaySpeed, "speed", todayDefaultSpeed, "average riding speed for timing loops, km/h")
+	todayCmd.Flags().StringVar(&FlagTodayGPX, "gpx", "", "write the best loop to this GPX file (needs --loop-km)")
+	todayCmd.Flags().StringVar(&FlagTodayPNG, "png", "", "also write the heatmap (and loops) to this PNG file")
 }
 
 // todayCell is the scored result for one grid cell over the ride window.
@@ -52,6 +70,8 @@ type todayCell struct {
 	WindSpeed   float64 `json:"windSpeed"`   // km/h, midpoint hour sustained wind
 	Sea         bool    `json:"sea"`
 	NoData      bool    `json:"noData"`
+
+	Hours []todayHour `json:"-"` // one per hour of the ride window, for the loop scorer
 }
 
 // hourlyWind holds a single hour's wind observation for the evolution strip.
@@ -60,6 +80,14 @@ type hourlyWind struct {
 	Speed   float64 `json:"speed"`   // km/h, sustained
 }
 
+// todayHour is one hour of one cell's weather inside the ride window. OK is
+// false when the forecast has no such hour.
+type todayHour struct {
+	hourlyWind
+	Precip float64 // mm/h
+	OK     bool
+}
+
 // sectorEvolution is the per-hour wind sequence for one compass sector,
 // sampled at half-radius from the start.
 type sectorEvolution struct {
@@ -79,6 +107,7 @@ type todayResult struct {
 	StartTime   time.Time         `json:"startTime"`
 	WindowHours int               `json:"windowHours"`
 	Sectors     []sectorEvolution `json:"sectors"` // 8 entries in compass order: N, NE, E, SE, S, SW, W, NW
+	Nowcast     bool              `json:"nowcast"` // the first hours' rain comes from the radar-motion nowcast
 }
 
 func runToday(cmd *cobra.Command, args []string) error {
@@ -89,6 +118,12 @@ func runToday(cmd *cobra.Command, args []string) error {
 	if FlagTodayRadius <= 0 {
 		return fmt.Errorf("--radius must be positive")
 	}
+	if FlagTodayLoopKm < 0 || FlagTodaySpeed <= 0 {
+		return fmt.Errorf("--loop-km and --speed must be positive")
+	}
+	if FlagTodayGPX != "" && FlagTodayLoopKm == 0 {
+		return fmt.Errorf("--gpx needs --loop-km")
+	}
 
 	startTime, err := resolveTodayStart()
 	if err != nil {
@@ -111,13 +146,52 @@ func runToday(cmd *cobra.Command, args []string) error {
 	prog := NewCLIProgress("forecast cells")
 	result := runTodayGrid(loc.Latitude, loc.Longitude, startTime, FlagTodayHours, todayGridSize(), FlagTodayRadius, prog)
 	prog.Finish()
-	renderToday(result)
+	renderToday(os.Stdout, result)
 	fmt.Println()
-	renderWindEvolution(result)
+	renderWindEvolution(os.Stdout, result)
 	fmt.Println()
-	renderTodayLegend()
+	renderTodayLegend(os.Stdout)
 	fmt.Println()
-	printTodayRecommendation(result)
+	printTodayRecommendation(os.Stdout, result)
+	if FlagTodayLoopKm > 0 {
+		fmt.Println()
+		if err := printTodayLoops(result, loc); err != nil {
+			return err
+		}
+	}
+	if FlagTodayPNG != "" {
+		loops := PlanTodayLoops(result, FlagTodayLoopKm, FlagTodaySpeed)
+		cells, opts := todayGrid(result, loopPaths(result, loops))
+		img, err := RenderHeatGridPNG(cells, opts, RasterOpts{DPI: cliPNGDPI})
+		return writePNGFile(FlagTodayPNG, img, err)
+	}
+	return nil
+}
+
+// printTodayLoops lists the best loops and writes the first to --gpx.
+func printTodayLoops(r todayResult, loc Location) error {
+	loops := PlanTodayLoops(r, FlagTodayLoopKm, FlagTodaySpeed)
+	b, rst := termplt.ColorBold, termplt.ColorReset
+	if len(loops) == 0 {
+		fmt.Printf("%sNo %.0f km loop fits on land inside the map%s — try a larger --radius or a shorter loop.\n",
+			termplt.ColorRed, FlagTodayLoopKm, rst)
+		return nil
+	}
+	fmt.Printf("%sLoops of %.0f km at %.0f km/h:%s\n", b, FlagTodayLoopKm, FlagTodaySpeed, rst)
+	for i, l := range loops {
+		fmt.Printf("  %d. %s\n", i+1, describeLoop(l))
+	}
+	if FlagTodayGPX == "" {
+		return nil
+	}
+	data, err := loopGPX(loops[0], fmt.Sprintf("%s %s", loc.Description, loops[0].Name()))
+	if err != nil {
+		return fmt.Errorf("encode gpx: %w", err)
+	}
+	if err := os.WriteFile(FlagTodayGPX, data, 0o644); err != nil {
+		return fmt.Errorf("write gpx: %w", err)
+	}
+	fmt.Printf("\nLoop 1 written to %s\n", FlagTodayGPX)
 	return nil
 }
 
@@ -194,6 +268,7 @@ func runTodayGrid(startLat, startLon float64, startTime time.Time, windowHours,
 
 	type cellData struct {
 		Row, Col int
+		Lat, Lon float64
 		Data     *OpenMeteoData
 		Err      error
 	}
@@ -209,10 +284,11 @@ func runTodayGrid(startLat, startLon float64, startTime time.Time, windowHours,
 			defer func() { <-sem }()
 			defer prog.Inc(1)
 			data, err := GetOpenMeteoRange(c.Lat, c.Lon, startDate, endDate)
-			results[i] = cellData{Row: c.Row, Col: c.Col, Data: data, Err: err}
+			results[i] = cellData{Row: c.Row, Col: c.Col, Lat: c.Lat, Lon: c.Lon, Data: data, Err: err}
 		}(i, c)
 	}
 	wg.Wait()
+	nowcast := todayRadarNowcast(startLat, startLon, startTime)
 
 	out := todayResult{
 		StepKm:      stepKm,
@@ -222,6 +298,7 @@ func runTodayGrid(startLat, startLon float64, startTime time.Time, windowHours,
 		StartLon:    startLon,
 		StartTime:   startTime,
 		WindowHours: windowHours,
+		Nowcast:     nowcast != nil,
 		Cells:       make([][]todayCell, gridSize),
 	}
 	for row := 0; row < gridSize; row++ {
@@ -260,14 +337,25 @@ func runTodayGrid(startLat, startLon float64, startTime time.Time, windowHours,
 		// Score sea cells too — the weather over water is meaningful for
 		// reading fronts approaching from the sea. The Sea flag just marks
 		// the cell visually and keeps it out of the "best direction" pick.
-		cell := scoreRideCell(rd.Data.Hourly, startTime, windowHours)
+		hourly := rd.Data.Hourly
+		if nowcast != nil {
+			hourly = blendRadarNowcast(hourly, nowcast, rd.Lat, rd.Lon)
+		}
+		cell := scoreRideCell(hourly, startTime, windowHours)
 		if rd.Data.IsSea() {
 			cell.Sea = true
 		}
+		hours := extractTodayHours(hourly, startTime, windowHours)
+		if !cell.NoData {
+			cell.Hours = hours
+		}
 		out.Cells[rd.Row][rd.Col] = cell
 
 		if si, ok := sectorRC[[2]int{rd.Row, rd.Col}]; ok {
-			out.Sectors[si].Wind = extractHourlyWind(rd.Data.Hourly, startTime, windowHours)
+			out.Sectors[si].Wind = make([]hourlyWind, len(hours))
+			for i, h := range hours {
+				out.Sectors[si].Wind[i] = h.hourlyWind
+			}
 			if rd.Data.IsSea() {
 				out.Sectors[si].OverWater = true
 			}
@@ -276,24 +364,44 @@ func runTodayGrid(startLat, startLon float64, startTime time.Time, windowHours,
 	return out
 }
 
-// extractHourlyWind pulls per-hour wind for the ride window at one cell.
-// BlowsTo is converted from Open-Meteo's meteorological "comes-from" convention.
-func extractHourlyWind(hourly []HourlyForecast, startTime time.Time, windowHours int) []hourlyWind {
+// todayRadarNowcast returns the radar-motion nowcast for a ride starting
+// within its horizon, nil otherwise or when the radar is unavailable — the
+// model's hourly rain then stands on its own.
+func todayRadarNowcast(lat, lon float64, startTime time.Time) *RadarNowcast {
+	if time.Until(startTime) >= radarNowcastHorizon {
+		return nil
+	}
+	n, err := GetRadarNowcast(lat, lon)
+	if err != nil {
+		slog.Debug("today: radar nowcast unavailable", "err", err)
+		return nil
+	}
+	return n
+}
+
+// extractTodayHours pulls per-hour wind and rain for the ride window at one
+// cell. BlowsTo is converted from Open-Meteo's meteorological "comes-from"
+// convention.
+func extractTodayHours(hourly []HourlyForecast, startTime time.Time, windowHours int) []todayHour {
 	const hourKey = "2006-01-02T15"
 	byHour := make(map[string]HourlyForecast, len(hourly))
 	for _, h := range hourly {
 		byHour[h.Time.Format(hourKey)] = h
 	}
-	out := make([]hourlyWind, 0, windowHours)
+	out := make([]todayHour, 0, windowHours)
 	for i := 0; i < windowHours; i++ {
 		target := startTime.Add(time.Duration(i) * time.Hour).Format(hourKey)
 		if h, ok := byHour[target]; ok {
-			out = append(out, hourlyWind{
-				BlowsTo: math.Mod(h.WindDirection+180, 360),
-				Speed:   h.WindSpeed,
+			out = append(out, todayHour{
+				hourlyWind: hourlyWind{
+					BlowsTo: math.Mod(h.WindDirection+180, 360),
+					Speed:   h.WindSpeed,
+				},
+				Precip: h.Precipitation,
+				OK:     true,
 			})
 		} else {
-			out = append(out, hourlyWind{})
+			out = append(out, todayHour{})
 		}
 	}
 	return out
@@ -349,22 +457,46 @@ func scoreRideCell(hourly []HourlyForecast, startTime time.Time, windowHours int
 	return cell
 }
 
+// todayHourView narrows r to hour i of the ride window: each cell shows that
+// hour's rain and wind instead of the window's peak and midpoint. The TUI
+// scrubs through the window with it.
+func todayHourView(r todayResult, i int) todayResult {
+	out := r
+	out.StartTime = r.StartTime.Add(time.Duration(i) * time.Hour)
+	out.WindowHours = 1
+	out.Cells = make([][]todayCell, len(r.Cells))
+	for row := range r.Cells {
+		out.Cells[row] = make([]todayCell, len(r.Cells[row]))
+		for col, c := range r.Cells[row] {
+			if i < len(c.Hours) && c.Hours[i].OK {
+				h := c.Hours[i]
+				c.MaxPrecip, c.WindBlowsTo, c.WindSpeed = h.Precip, h.BlowsTo, h.Speed
+			} else {
+				c.NoData = true
+			}
+			c.Hours = c.Hours[min(i, len(c.Hours)):min(i+1, len(c.Hours))]
+			out.Cells[row][col] = c
+		}
+	}
+	return out
+}
+
 // ---------- rendering ----------
 
-func renderToday(r todayResult) {
+func renderToday(w io.Writer, r todayResult) {
 	gridSize := r.Grid
 	mid := gridSize / 2
 	for row := 0; row < gridSize; row++ {
-		fmt.Print("  ")
+		fmt.Fprint(w, "  ")
 		for col := 0; col < gridSize; col++ {
 			isStart := row == mid && col == mid
-			fmt.Print(todayCellGlyph(r.Cells[row][col], isStart))
+			fmt.Fprint(w, todayCellGlyph(r.Cells[row][col], isStart))
 		}
 		northCells := mid - row
-		fmt.Printf("  %+4d km\n", int(float64(northCells)*r.StepKm))
+		fmt.Fprintf(w, "  %+4d km\n", int(float64(northCells)*r.StepKm))
 	}
 	// Bottom axis.
-	fmt.Print("  ")
+	fmt.Fprint(w, "  ")
 	eastEdge := int(float64(mid) * r.StepKm)
 	left := fmt.Sprintf("-%d km W", eastEdge)
 	right := fmt.Sprintf("+%d km E", eastEdge)
@@ -379,7 +511,7 @@ func renderToday(r todayResult) {
 	if rightGap < 1 {
 		rightGap = 1
 	}
-	fmt.Printf("%s%s%s%s%s\n", left, strings.Repeat(" ", leftGap), mark, strings.Repeat(" ", rightGap), right)
+	fmt.Fprintf(w, "%s%s%s%s%s\n", left, strings.Repeat(" ", leftGap), mark, strings.Repeat(" ", rightGap), right)
 }
 
 // todayCellGlyph returns the 2-char rendered cell.
@@ -485,7 +617,7 @@ func todayWindGlyph(c todayCell) string {
 // mid-radius, one arrow per hour of the ride window. The arrow points where
 // the wind pushes you at that hour; · means calm (≤10 km/h, direction
 // meaningless). Lets you see at a glance whether your tailwind will hold.
-func renderWindEvolution(r todayResult) {
+func renderWindEvolution(w io.Writer, r todayResult) {
 	if len(r.Sectors) == 0 {
 		return
 	}
@@ -498,75 +630,75 @@ func renderWindEvolution(r todayResult) {
 
 	b := termplt.ColorBold
 	rst := termplt.ColorReset
-	fmt.Printf("%sWind evolution at ~%.0f km out%s — arrow = where wind pushes you, %s·%s = calm:\n",
+	fmt.Fprintf(w, "%sWind evolution at ~%.0f km out%s — arrow = where wind pushes you, %s·%s = calm:\n",
 		b, sampleKm, rst, termplt.ColorCyan, rst)
 
 	// Header row: "      HH  HH  HH  ..."  (6 chars for label area, 4 per hour).
-	fmt.Print("        ")
+	fmt.Fprint(w, "        ")
 	for i := 0; i < r.WindowHours; i++ {
 		h := r.StartTime.Add(time.Duration(i) * time.Hour).Hour()
-		fmt.Printf("%02d  ", h)
+		fmt.Fprintf(w, "%02d  ", h)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 
 	for _, sec := range r.Sectors {
 		// Sector name is cyan when over water so the row reads as "water"
 		// from the very first column, matching how individual cells are tinted.
 		if sec.OverWater {
-			fmt.Printf("  %s%-4s%s  ", termplt.ColorCyan, sec.Name, rst)
+			fmt.Fprintf(w, "  %s%-4s%s  ", termplt.ColorCyan, sec.Name, rst)
 		} else {
-			fmt.Printf("  %-4s  ", sec.Name)
+			fmt.Fprintf(w, "  %-4s  ", sec.Name)
 		}
 		if sec.NoData {
-			fmt.Println("(no data)")
+			fmt.Fprintln(w, "(no data)")
 			continue
 		}
 		if sec.OverWater {
 			// Still show evolution — weather over water is meaningful even if
 			// the sector itself isn't rideable. Tint cyan so you remember.
-			fmt.Print(termplt.ColorCyan)
+			fmt.Fprint(w, termplt.ColorCyan)
 		}
-		for _, w := range sec.Wind {
+		for _, wind := range sec.Wind {
 			glyph := "·"
-			if w.Speed > windCalmKmh {
-				glyph = CompassArrow(w.BlowsTo)
+			if wind.Speed > windCalmKmh {
+				glyph = CompassArrow(wind.BlowsTo)
 			}
-			fmt.Print(glyph + "   ")
+			fmt.Fprint(w, glyph+"   ")
 		}
 		if sec.OverWater {
-			fmt.Print(rst + "  (water)")
+			fmt.Fprint(w, rst+"  (water)")
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 }
 
-func renderTodayLegend() {
+func renderTodayLegend(w io.Writer) {
 	rst := termplt.ColorReset
 	b := termplt.ColorBold
 	sw := func(bg, body string) string { return bg + body + rst }
-	fmt.Println(b + "Legend" + rst + " — background = rain amount, symbol = wind:")
-	fmt.Println()
-	fmt.Printf(b+"  Rain (cell background) — peak over the %d-hour window"+rst+"\n", FlagTodayHours)
-	fmt.Printf("    %s    no rain at all\n", sw(termplt.ColorBackgroundBrightGreen, "   "))
-	fmt.Printf("    %s    light rain (under %.1f mm/h)\n", sw(termplt.ColorBackgroundYellow, "   "), lightRainMm)
-	fmt.Printf("    %s    rain (shown as %s ✗ %s)\n",
+	fmt.Fprintln(w, b+"Legend"+rst+" — background = rain amount, symbol = wind:")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, b+"  Rain (cell background) — peak over the %d-hour window"+rst+"\n", FlagTodayHours)
+	fmt.Fprintf(w, "    %s    no rain at all\n", sw(termplt.ColorBackgroundBrightGreen, "   "))
+	fmt.Fprintf(w, "    %s    light rain (under %.1f mm/h)\n", sw(termplt.ColorBackgroundYellow, "   "), lightRainMm)
+	fmt.Fprintf(w, "    %s    rain (shown as %s ✗ %s)\n",
 		sw(termplt.ColorBackgroundRed, "   "),
 		termplt.ColorBackgroundRed+b+termplt.ColorWhite, rst)
-	fmt.Println()
-	fmt.Println(b + "  Wind — arrow points where wind pushes you, marker is strength" + rst)
-	fmt.Printf("    %s    calm, ≤%.0f km/h\n", sw(termplt.ColorBackgroundGreen, " · "), windCalmKmh)
-	fmt.Printf("    %s    breezy, %.0f–%.0f km/h\n", sw(termplt.ColorBackgroundGreen, "→·"), windCalmKmh, windBreezyKmh)
-	fmt.Printf("    %s    windy, %.0f–%.0f km/h\n", sw(termplt.ColorBackgroundGreen, "→~"), windBreezyKmh, windWindyKmh)
-	fmt.Printf("    %s    strong, %.0f–%.0f km/h\n", sw(termplt.ColorBackgroundGreen, "→≈"), windWindyKmh, windStrongKmh)
-	fmt.Println()
-	fmt.Println(b + "  Overrides" + rst)
-	fmt.Printf("    %s    over water, dry (weather still shown; you can't ride there)\n",
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, b+"  Wind — arrow points where wind pushes you, marker is strength"+rst)
+	fmt.Fprintf(w, "    %s    calm, ≤%.0f km/h\n", sw(termplt.ColorBackgroundGreen, " · "), windCalmKmh)
+	fmt.Fprintf(w, "    %s    breezy, %.0f–%.0f km/h\n", sw(termplt.ColorBackgroundGreen, "→·"), windCalmKmh, windBreezyKmh)
+	fmt.Fprintf(w, "    %s    windy, %.0f–%.0f km/h\n", sw(termplt.ColorBackgroundGreen, "→~"), windBreezyKmh, windWindyKmh)
+	fmt.Fprintf(w, "    %s    strong, %.0f–%.0f km/h\n", sw(termplt.ColorBackgroundGreen, "→≈"), windWindyKmh, windStrongKmh)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, b+"  Overrides"+rst)
+	fmt.Fprintf(w, "    %s    over water, dry (weather still shown; you can't ride there)\n",
 		termplt.ColorBackgroundBrightGreen+termplt.ColorCyan+"→·"+rst)
-	fmt.Printf("    %s    over water, raining (cyan ✗ on rain background)\n",
+	fmt.Fprintf(w, "    %s    over water, raining (cyan ✗ on rain background)\n",
 		termplt.ColorBackgroundRed+b+termplt.ColorCyan+" ✗"+rst)
-	fmt.Printf("    %s    your starting point\n",
+	fmt.Fprintf(w, "    %s    your starting point\n",
 		termplt.ColorBackgroundBrightGreen+b+termplt.ColorWhite+" ●"+rst)
-	fmt.Printf("    %s    no data from the forecast provider (try refreshing)\n",
+	fmt.Fprintf(w, "    %s    no data from the forecast provider (try refreshing)\n",
 		termplt.ColorBackgroundBrightBlack+"  "+rst)
 }
 
@@ -638,18 +770,18 @@ func RecommendToday(r todayResult) TodayRecommendation {
 	return out
 }
 
-func printTodayRecommendation(r todayResult) {
+func printTodayRecommendation(w io.Writer, r todayResult) {
 	rec := RecommendToday(r)
 	b := termplt.ColorBold
 	rst := termplt.ColorReset
 	if len(rec.Rideable) == 0 {
-		fmt.Println(termplt.ColorRed + "No rideable direction found — everything around you is water or missing data." + termplt.ColorReset)
+		fmt.Fprintln(w, termplt.ColorRed+"No rideable direction found — everything around you is water or missing data."+termplt.ColorReset)
 		return
 	}
-	fmt.Printf("%sBest:%s head %s — %s, %s.\n",
+	fmt.Fprintf(w, "%sBest:%s head %s — %s, %s.\n",
 		b, rst, rec.Best.Name, describeDry(rec.Best.DryHours, r.WindowHours), describeWind(rec.Best.Tailwind, rec.Best.Cell.WindSpeed))
 	if rec.Worst.Name != rec.Best.Name && rec.Worst.DryHours < r.WindowHours {
-		fmt.Printf("%sAvoid:%s %s — %s.\n",
+		fmt.Fprintf(w, "%sAvoid:%s %s — %s.\n",
 			b, rst, rec.Worst.Name, describeDry(rec.Worst.DryHours, r.WindowHours))
 	}
 }
@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Loop generation for `weather today --loop-km` and /today?loop=. Candidate
+// loops are straight-line shapes — out-and-backs and triangles — laid over
+// the today grid; real roads add some distance, but where the rain and the
+// wind are is what decides between them.
+const (
+	todayDefaultSpeed = 25.0 // km/h, average riding speed for timing a loop
+	loopBearings      = 16   // first-leg headings tried, every 22.5°
+	loopSampleKm      = 1.0  // loops are scored every this many km
+	loopWetPenalty    = 50.0 // km/h of late tailwind a fully wet loop costs
+	loopShow          = 3    // loops drawn and listed
+	loopMinSeparation = 45.0 // degrees between the first legs of listed loops
+)
+
+// loopShapes are the candidate shapes: an out-and-back, and triangles with
+// the given angle at the start, ridden either way round.
+var loopShapes = []struct {
+	Kind      string
+	ApexDeg   float64
+	Clockwise bool
+}{
+	{"out-and-back", 0, false},
+	{"triangle", 60, true},
+	{"triangle", 60, false},
+	{"triangle", 90, true},
+	{"triangle", 90, false},
+}
+
+// TodayLoop is one scored candidate loop. Waypoints start and end at the
+// start point; their Time is when the rider passes them at the planned speed.
+type TodayLoop struct {
+	Kind      string        `json:"kind"`    // "out-and-back" or "triangle"
+	Heading   float64       `json:"heading"` // bearing of the first leg
+	Clockwise bool          `json:"clockwise,omitempty"`
+	Waypoints []trackPoint  `json:"waypoints"`
+	LengthKm  float64       `json:"lengthKm"`
+	Duration  time.Duration `json:"-"` // the last waypoint's Time says when it ends
+
+	WetKm        float64 `json:"wetKm"`        // km ridden in forecast rain
+	OutTailwind  float64 `json:"outTailwind"`  // km/h, average over the first half
+	HomeTailwind float64 `json:"homeTailwind"` // km/h, average over the second half
+	Score        float64 `json:"score"`
+	Overrun      bool    `json:"overrun"` // the loop takes longer than the ride window
+}
+
+// Name is a short label such as "triangle NW→E" (first leg, then the leg
+// across) or "out-and-back SE".
+func (l TodayLoop) Name() string {
+	if l.Kind != "triangle" || len(l.Waypoints) < 3 {
+		return fmt.Sprintf("%s %s", l.Kind, CompassName(l.Heading))
+	}
+	a, b := l.Waypoints[1], l.Waypoints[2]
+	across := InitialBearing(a.Lat, a.Lon, b.Lat, b.Lon)
+	return fmt.Sprintf("triangle %s→%s", CompassName(l.Heading), CompassName(across))
+}
+
+// loopWaypoints lays out a shape of lengthKm with its first leg on heading.
+// For a triangle with apex angle θ at the start and two equal sides s, the
+// far side is 2s·sin(θ/2), so s = L / (2 + 2·sin(θ/2)).
+func loopWaypoints(lat, lon, heading, lengthKm, apexDeg float64, clockwise bool) []trackPoint {
+	start := trackPoint{Lat: lat, Lon: lon}
+	if apexDeg == 0 {
+		var far trackPoint
+		far.Lat, far.Lon = DestinationPoint(lat, lon, heading, lengthKm/2)
+		return []trackPoint{start, far, start}
+	}
+	side := lengthKm / (2 + 2*math.Sin(apexDeg/2*math.Pi/180))
+	turn := apexDeg
+	if !clockwise {
+		turn = -apexDeg
+	}
+	var a, b trackPoint
+	a.Lat, a.Lon = DestinationPoint(lat, lon, heading, side)
+	b.Lat, b.Lon = DestinationPoint(lat, lon, math.Mod(heading+turn+360, 360), side)
+	return []trackPoint{start, a, b, start}
+}
+
+// gridPos maps a point to fractional (row, col) in the today grid, using the
+// same flat projection runTodayGrid lays the cells out with.
+func (r todayResult) gridPos(lat, lon float64) (row, col float64) {
+	lonFactor := 111.0 * math.Cos(r.StartLat*math.Pi/180)
+	if lonFactor < 1 {
+		lonFactor = 1
+	}
+	mid := float64(r.Grid / 2)
+	northKm := (lat - r.StartLat) * 111.0
+	eastKm := (lon - r.StartLon) * lonFactor
+	return mid - northKm/r.StepKm, mid + eastKm/r.StepKm
+}
+
+// scoreLoop rides the loop through the grid every loopSampleKm: each sample
+// takes the rain and wind of the cell it falls in at the hour the rider gets
+// there. It reports false when the loop leaves the map, crosses water or
+// reaches a cell without data. Tailwind counts more the later it comes —
+// from half weight at the start to one and a half at the finish — so
+// heading out into the wind while fresh and riding home with it wins.
+func scoreLoop(r todayResult, l *TodayLoop, speedKmh float64) bool {
+	type leg struct {
+		from           trackPoint
+		bearing, km    float64
+		startKm, endKm float64
+	}
+	var legs []leg
+	total := 0.0
+	for i := 1; i < len(l.Waypoints); i++ {
+		a, b := l.Waypoints[i-1], l.Waypoints[i]
+		km := HaversineKm(a.Lat, a.Lon, b.Lat, b.Lon)
+		legs = append(legs, leg{a, InitialBearing(a.Lat, a.Lon, b.Lat, b.Lon), km, total, total + km})
+		total += km
+	}
+	if total <= 0 {
+		return false
+	}
+	l.LengthKm = total
+	l.Duration = time.Duration(total / speedKmh * float64(time.Hour))
+	for i := range l.Waypoints {
+		km := 0.0
+		if i > 0 {
+			km = legs[i-1].endKm
+		}
+		l.Waypoints[i].Time = r.StartTime.Add(time.Duration(km / speedKmh * float64(time.Hour))).Round(time.Second)
+	}
+
+	n := int(math.Ceil(total / loopSampleKm))
+	step := total / float64(n)
+	var weighted, wetKm, outTail, homeTail, outKm, homeKm float64
+	li := 0
+	for i := 0; i < n; i++ {
+		x := (float64(i) + 0.5) * step // sample at the middle of each step
+		for li < len(legs)-1 && x > legs[li].endKm {
+			li++
+		}
+		lg := legs[li]
+		lat, lon := DestinationPoint(lg.from.Lat, lg.from.Lon, lg.bearing, x-lg.startKm)
+		row, col := r.gridPos(lat, lon)
+		ri, ci := int(math.Round(row)), int(math.Round(col))
+		if ri < 0 || ri >= r.Grid || ci < 0 || ci >= r.Grid {
+			return false
+		}
+		cell := r.Cells[ri][ci]
+		if cell.Sea || cell.NoData || len(cell.Hours) == 0 {
+			return false
+		}
+		// Hour 0 is the clock hour the ride starts in.
+		hi := int(float64(r.StartTime.Minute())/60 + x/speedKmh)
+		if hi >= len(cell.Hours) {
+			hi = len(cell.Hours) - 1
+			l.Overrun = true
+		}
+		h := cell.Hours[hi]
+		if !h.OK {
+			return false
+		}
+		tail := tailwindComponent(h.Speed, h.BlowsTo+180, lg.bearing)
+		weighted += tail * (0.5 + x/total) * step
+		if x < total/2 {
+			outTail += tail * step
+			outKm += step
+		} else {
+			homeTail += tail * step
+			homeKm += step
+		}
+		if h.Precip > rainThresholdMm {
+			wetKm += step
+		}
+	}
+	l.WetKm = wetKm
+	if outKm > 0 {
+		l.OutTailwind = outTail / outKm
+	}
+	if homeKm > 0 {
+		l.HomeTailwind = homeTail / homeKm
+	}
+	l.Score = weighted/total - loopWetPenalty*wetKm/total
+	return true
+}
+
+// PlanTodayLoops builds every candidate loop of lengthKm around the start,
+// scores those that stay on land inside the map, and returns up to loopShow
+// of the best with first legs at least loopMinSeparation apart, best first.
+func PlanTodayLoops(r todayResult, lengthKm, speedKmh float64) []TodayLoop {
+	if lengthKm <= 0 || speedKmh <= 0 || r.Grid == 0 {
+		return nil
+	}
+	var all []TodayLoop
+	for i := 0; i < loopBearings; i++ {
+		heading := float64(i) * 360 / loopBearings
+		for _, sh := range loopShapes {
+			l := TodayLoop{
+				Kind:      sh.Kind,
+				Heading:   heading,
+				Clockwise: sh.Clockwise,
+				Waypoints: loopWaypoints(r.StartLat, r.StartLon, heading, lengthKm, sh.ApexDeg, sh.Clockwise),
+			}
+			if scoreLoop(r, &l, speedKmh) {
+				all = append(all, l)
+			}
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Score > all[j].Score })
+
+	var out []TodayLoop
+	for _, l := range all {
+		distinct := true
+		for _, o := range out {
+			d := math.Abs(math.Mod(l.Heading-o.Heading+540, 360) - 180)
+			if d < loopMinSeparation {
+				distinct = false
+				break
+			}
+		}
+		if distinct {
+			out = append(out, l)
+		}
+		if len(out) == loopShow {
+			break
+		}
+	}
+	return out
+}
+
+// describeLoop is the one-line summary the CLI and the web page print.
+func describeLoop(l TodayLoop) string {
+	rain := "dry all the way"
+	if l.WetKm > 0 {
+		rain = fmt.Sprintf("%.0f km in rain", l.WetKm)
+	}
+	s := fmt.Sprintf("%s, %.0f km, about %s — %s; out %s, home %s",
+		l.Name(), l.LengthKm, formatLoopDuration(l.Duration), rain, windLabel(l.OutTailwind), windLabel(l.HomeTailwind))
+	if l.Overrun {
+		s += " (runs past the forecast window)"
+	}
+	return s
+}
+
+func formatLoopDuration(d time.Duration) string {
+	d = d.Round(5 * time.Minute)
+	return fmt.Sprintf("%dh%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// loopGPX encodes a loop as a GPX 1.1 track, with a point every
+// loopSampleKm so a head unit draws it without routing, each timed for the
+// planned speed.
+func loopGPX(l TodayLoop, name string) ([]byte, error) {
+	type gpxPt struct {
+		Lat  float64 `xml:"lat,attr"`
+		Lon  float64 `xml:"lon,attr"`
+		Time string  `xml:"time,omitempty"`
+	}
+	type gpxDoc struct {
+		XMLName xml.Name `xml:"gpx"`
+		Version string   `xml:"version,attr"`
+		Creator string   `xml:"creator,attr"`
+		NS      string   `xml:"xmlns,attr"`
+		Name    string   `xml:"trk>name"`
+		Points  []gpxPt  `xml:"trk>trkseg>trkpt"`
+	}
+	doc := gpxDoc{Version: "1.1", Creator: "weather", NS: "http://www.topografix.com/GPX/1/1", Name: name}
+	add := func(lat, lon float64, t time.Time) {
+		doc.Points = append(doc.Points, gpxPt{
+			Lat:  math.Round(lat*1e6) / 1e6,
+			Lon:  math.Round(lon*1e6) / 1e6,
+			Time: t.UTC().Format(time.RFC3339),
+		})
+	}
+	for i := 1; i < len(l.Waypoints); i++ {
+		a, b := l.Waypoints[i-1], l.Waypoints[i]
+		km := HaversineKm(a.Lat, a.Lon, b.Lat, b.Lon)
+		bearing := InitialBearing(a.Lat, a.Lon, b.Lat, b.Lon)
+		n := int(math.Ceil(km / loopSampleKm))
+		for j := 0; j < n; j++ {
+			f := float64(j) / float64(n)
+			lat, lon := DestinationPoint(a.Lat, a.Lon, bearing, km*f)
+			add(lat, lon, a.Time.Add(time.Duration(float64(b.Time.Sub(a.Time))*f)))
+		}
+	}
+	if n := len(l.Waypoints); n > 0 {
+		end := l.Waypoints[n-1]
+		add(end.Lat, end.Lon, end.Time)
+	}
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// loopPaths turns loops into grid overlays for RenderHeatGridSVG, best first.
+func loopPaths(r todayResult, loops []TodayLoop) []GridPath {
+	colors := []string{"#1d4ed8", "#7c3aed", "#db2777"}
+	var out []GridPath
+	for i, l := range loops {
+		p := GridPath{Color: colors[i%len(colors)], Label: fmt.Sprint(i + 1), Dashed: i > 0}
+		for _, w := range l.Waypoints {
+			row, col := r.gridPos(w.Lat, w.Lon)
+			p.Points = append(p.Points, [2]float64{row, col})
+		}
+		out = append(out, p)
+	}
+	return out
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testLoopStart = time.Date(2026, 6, 12, 14, 0, 0, 0, time.UTC)
+
+// testLoopGrid is a 21×21 today grid with 20 km/h from the north everywhere
+// and rain east of the start; with sea, water all round the start cell.
+func testLoopGrid(sea bool) todayResult {
+	const grid = 21
+	r := todayResult{Grid: grid, StepKm: 5, RadiusKm: 50, StartLat: 52, StartLon: 5, StartTime: testLoopStart, WindowHours: 4}
+	r.Cells = make([][]todayCell, grid)
+	for row := range r.Cells {
+		r.Cells[row] = make([]todayCell, grid)
+		for col := range r.Cells[row] {
+			c := todayCell{Sea: sea && (row != grid/2 || col != grid/2)}
+			for h := 0; h < r.WindowHours; h++ {
+				th := todayHour{hourlyWind: hourlyWind{BlowsTo: 180, Speed: 20}, OK: true}
+				if col > grid/2+1 {
+					th.Precip = 1
+				}
+				c.Hours = append(c.Hours, th)
+			}
+			r.Cells[row][col] = c
+		}
+	}
+	return r
+}
+
+func TestTodayLoops(t *testing.T) {
+	tests := []struct {
+		name     string
+		sea      bool
+		loops    int
+		lengthKm float64       // of the best loop
+		duration time.Duration // of the best loop
+	}{
+		{"out into the wind, home with it, around the rain", false, loopShow, 40, 2 * time.Hour},
+		{"nothing to ride over water", true, 0, 0, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			loops := PlanTodayLoops(testLoopGrid(tc.sea), 40, 20)
+			if len(loops) != tc.loops {
+				t.Fatalf("PlanTodayLoops returned %d loops, want %d", len(loops), tc.loops)
+			}
+			if tc.loops == 0 {
+				return
+			}
+			best := loops[0]
+			if best.WetKm != 0 || best.OutTailwind >= 0 || best.HomeTailwind <= 0 {
+				t.Fatalf("best loop %s: wet %.1f km, out %.1f, home %.1f; want dry, out into the wind and home with it",
+					best.Name(), best.WetKm, best.OutTailwind, best.HomeTailwind)
+			}
+			end := best.Waypoints[len(best.Waypoints)-1].Time
+			if math.Abs(best.LengthKm-tc.lengthKm) > 0.5 || !end.Equal(testLoopStart.Add(tc.duration)) {
+				t.Fatalf("best loop is %.1f km ending %v, want %v km after %v", best.LengthKm, end, tc.lengthKm, tc.duration)
+			}
+		})
+	}
+}
+
+// TestLoopGPX checks that the GPX export reads back as a track with the
+// planned times.
+func TestLoopGPX(t *testing.T) {
+	loops := PlanTodayLoops(testLoopGrid(false), 40, 20)
+	if len(loops) == 0 {
+		t.Fatal("PlanTodayLoops found no loop")
+	}
+	data, err := loopGPX(loops[0], "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pts, err := parseTrack("loop.gpx", strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pts) < 40 || !pts[0].Time.Equal(testLoopStart) || !pts[len(pts)-1].Time.Equal(testLoopStart.Add(2*time.Hour)) {
+		t.Fatalf("GPX read back as %d points from %v to %v", len(pts), pts[0].Time, pts[len(pts)-1].Time)
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// upstreamDefaults are the upstream sources the config's "upstreams" map can
+// point elsewhere, e.g. at a caching proxy or a self-hosted tile server.
+// Templated URLs use {z}/{x}/{y} for the slippy-map tile.
+var upstreamDefaults = map[string]string{
+	// RainViewer's index of radar frames, global and keyless; each entry
+	// points at a tile set on its own host.
+	"rainviewer": "https://api.rainviewer.com/public/weather-maps.json",
+	// The base map under the radar. OpenStreetMap's tile policy asks for an
+	// identifying User-Agent and light use, which the tile cache keeps to.
+	"basemap": "https://tile.openstreetmap.org/{z}/{x}/{y}.png",
+}
+
+// upstreamUserAgent identifies the server to tile hosts, as their usage
+// policies require.
+const upstreamUserAgent = "weather-forecast-server/1.0 (self-hosted; Go net/http)"
+
+// upstreamURL returns the configured URL for a named upstream, falling back
+// to its default. A config that can't be read keeps the default: the server
+// must not stop serving maps over a typo, and loadConfig's error surfaces on
+// every other command anyway. It runs per tile request, so it reads the
+// cached config.
+func upstreamURL(name string) string {
+	cfg, err := cachedConfig()
+	if err != nil {
+		slog.Debug("upstream: config unreadable, using default", "name", name, "err", err)
+	} else if u := cfg.Upstreams[name]; u != "" {
+		return u
+	}
+	return upstreamDefaults[name]
+}
+
+// getUpstream GETs url and returns the body, requiring a 200.
+func getUpstream(url, what string) ([]byte, error) {
+	slog.Debug("upstream: requesting", "what", what, "url", url)
+	client := &http.Client{Timeout: 10 * time.Second}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s request: %w", what, err)
+	}
+	req.Header.Set("User-Agent", upstreamUserAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", what, err)
+	}
+	defer closeBody(resp.Body, what+" response body")
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: unexpected status code %d", what, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: read body: %w", what, err)
+	}
+	return body, nil
+}
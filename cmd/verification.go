@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	verifyHourlyDays   = 4                // Open-Meteo lead days snapshotted (day 0 … day 3)
+	verifyHourlyEvery  = time.Hour        // Open-Meteo snapshots at most this often per place
+	verifyArchiveEvery = 6 * time.Hour    // reanalysis re-fetched at most this often per place
+	verifyArchiveDays  = 10               // how far back the reanalysis fetch reaches
+	verifyNowcastStep  = 5 * time.Minute  // nowcast/radar resolution pairs are matched on
+	verifyNowcastLead  = 30 * time.Minute // nowcast lead bucket width
+	verifyWetMmH       = 0.1              // precipitation at or above this counts as wet
+	verifyCompactEvery = 24 * time.Hour   // the store is compacted at most this often
+)
+
+// recorder snapshots forecasts for the saved places into a verificationStore
+// and collects the observations they are later scored against. It is safe
+// for one goroutine at a time; serve runs it from a single ticker.
+type recorder struct {
+	store       *verificationStore
+	places      []Location
+	lastHourly  map[string]time.Time
+	lastArchive map[string]time.Time
+	stored      map[string]float64 // observation key → the value last stored
+}
+
+// recordStats counts what one RecordOnce pass stored.
+type recordStats struct {
+	Snapshots    int
+	Observations int
+	Errors       int
+}
+
+// newRecorder loads the observations already stored so a pass only appends
+// new or revised ones.
+func newRecorder(store *verificationStore, places []Location) (*recorder, error) {
+	r := &recorder{
+		store:       store,
+		places:      places,
+		lastHourly:  map[string]time.Time{},
+		lastArchive: map[string]time.Time{},
+	}
+	if err := r.loadStored(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *recorder) loadStored() error {
+	obs, err := r.store.Observations()
+	if err != nil {
+		return err
+	}
+	r.stored = make(map[string]float64, len(obs))
+	for _, o := range obs {
+		r.stored[o.key()] = o.Value
+	}
+	return nil
+}
+
+// RecordOnce runs one pass over every place: both nowcasts every time, the
+// Open-Meteo hourly forecast once per verifyHourlyEvery, Buienradar's past
+// radar values every time, and the reanalysis once per verifyArchiveEvery.
+// The nowcasts are fetched past their caches, so a snapshot is the forecast
+// as it stood at now. A failing provider is logged and counted, never fatal
+// to the pass. Once per verifyCompactEvery the store drops what is older
+// than verifyRetention.
+func (r *recorder) RecordOnce(now time.Time) (recordStats, error) {
+	var stats recordStats
+	var snaps []forecastSnapshot
+	var obs []observation
+	fail := func(what string, loc Location, err error) {
+		stats.Errors++
+		slog.Debug("record: fetch failed", "what", what, "place", loc.Description, "err", err)
+	}
+
+	for _, loc := range r.places {
+		place := loc.Description
+		for _, nc := range []struct {
+			provider string
+			get      func(float64, float64) (*Forecast, error)
+		}{
+			{providerBuienalarm, getBuinealarmForecastUncached},
+			{providerBuienradar, getBuineradarForecastUncached},
+		} {
+			f, err := nc.get(loc.Latitude, loc.Longitude)
+			if err != nil {
+				fail(nc.provider, loc, err)
+				continue
+			}
+			snaps = append(snaps, forecastSnapshot{
+				Place: place, Provider: nc.provider, Variable: varPrecip, IssuedAt: now, Points: f.Data,
+			})
+		}
+
+		if now.Sub(r.lastHourly[place]) >= verifyHourlyEvery {
+			data, err := GetOpenMeteoRange(loc.Latitude, loc.Longitude, now, now.AddDate(0, 0, verifyHourlyDays-1))
+			if err != nil {
+				fail(providerOpenMeteo, loc, err)
+			} else {
+				temp := forecastSnapshot{Place: place, Provider: providerOpenMeteo, Variable: varTemp, IssuedAt: now}
+				precip := forecastSnapshot{Place: place, Provider: providerOpenMeteo, Variable: varPrecip, IssuedAt: now}
+				for _, h := range data.Hourly {
+					if h.Time.After(now) {
+						temp.Points = append(temp.Points, ForecastDataPoint{Time: h.Time, Value: h.Temperature})
+						precip.Points = append(precip.Points, ForecastDataPoint{Time: h.Time, Value: h.Precipitation})
+					}
+				}
+				snaps = append(snaps, temp, precip)
+				r.lastHourly[place] = now
+			}
+		}
+
+		radar, err := GetBuineradarObserved(loc.Latitude, loc.Longitude)
+		if err != nil {
+			fail("radar", loc, err)
+		}
+		for _, p := range radar {
+			obs = r.addObservation(obs, observation{Place: place, Source: sourceRadar, Variable: varPrecip, Time: p.Time, Value: p.Value})
+		}
+
+		if now.Sub(r.lastArchive[place]) >= verifyArchiveEvery {
+			hours, err := getOpenMeteoArchive(loc.Latitude, loc.Longitude, now.AddDate(0, 0, -verifyArchiveDays), now)
+			if err != nil {
+				fail("archive", loc, err)
+			} else {
+				for _, h := range hours {
+					obs = r.addObservation(obs, observation{Place: place, Source: sourceArchive, Variable: varTemp, Time: h.Time, Value: h.Temperature})
+					if h.HasPrecip {
+						obs = r.addObservation(obs, observation{Place: place, Source: sourceArchive, Variable: varPrecip, Time: h.Time, Value: h.Precipitation})
+					}
+				}
+				r.lastArchive[place] = now
+			}
+		}
+	}
+
+	if err := r.store.AppendSnapshots(snaps); err != nil {
+		return stats, err
+	}
+	if err := r.store.AppendObservations(obs); err != nil {
+		return stats, err
+	}
+	stats.Snapshots, stats.Observations = len(snaps), len(obs)
+
+	if now.Sub(r.store.CompactedAt()) >= verifyCompactEvery {
+		if err := r.store.Compact(now.Add(-verifyRetention)); err != nil {
+			return stats, err
+		}
+		if err := r.loadStored(); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// addObservation appends o unless the store already holds the same value;
+// a revised one (the radar corrects recent frames) is appended again and
+// wins on read.
+func (r *recorder) addObservation(obs []observation, o observation) []observation {
+	if v, ok := r.stored[o.key()]; ok && v == o.Value {
+		return obs
+	}
+	r.stored[o.key()] = o.Value
+	return append(obs, o)
+}
+
+// recordPlaces resolves the saved places from the config; with none saved it
+// falls back to the single location from --lat/--lon/--name (or IP).
+func recordPlaces() ([]Location, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Places) == 0 {
+		loc, err := ResolveLocation()
+		if err != nil {
+			return nil, fmt.Errorf("no saved places and no location: %w", err)
+		}
+		return []Location{loc}, nil
+	}
+	out := make([]Location, 0, len(cfg.Places))
+	for _, p := range cfg.Places {
+		loc, err := p.Location()
+		if err != nil {
+			return nil, err
+		}
+		loc.Description = p.Name // store under the name the user chose
+		out = append(out, loc)
+	}
+	return out, nil
+}
+
+// ---------- scoring ----------
+
+// verificationMetric is the error of one provider's forecasts of one variable
+// at one lead-time bucket, over every matched forecast/observation pair.
+type verificationMetric struct {
+	Provider string  `json:"provider"`
+	Variable string  `json:"variable"`
+	Lead     string  `json:"lead"` // "0–30 min", "day 2", …
+	N        int     `json:"n"`
+	MAE      float64 `json:"mae"`
+	Bias     float64 `json:"bias"` // mean forecast − observed
+	RMSE     float64 `json:"rmse"`
+	Agree    float64 `json:"agree"` // precip only: share of pairs on the same side of verifyWetMmH; -1 for temp
+
+	leadOrder int
+	sumAbs    float64
+	sum       float64
+	sumSq     float64
+	agreeN    int
+}
+
+// computeVerification pairs every snapshot point with the observation of the
+// same place, variable and time, and aggregates the errors per provider,
+// variable and lead bucket. Nowcasts are scored against Buienradar's radar
+// on a 5-minute grid, Open-Meteo against the reanalysis by the hour. place
+// filters to one saved place; empty scores them all together.
+func computeVerification(snaps []forecastSnapshot, obs []observation, place string) []verificationMetric {
+	truth := map[string]float64{}
+	for _, o := range obs {
+		truth[truthKey(o.Place, o.Source, o.Variable, o.Time)] = o.Value
+	}
+
+	buckets := map[string]*verificationMetric{}
+	for _, s := range snaps {
+		if place != "" && s.Place != place {
+			continue
+		}
+		source, step := sourceArchive, time.Hour
+		if s.Provider != providerOpenMeteo {
+			source, step = sourceRadar, verifyNowcastStep
+		}
+		for _, p := range s.Points {
+			// Points before the issue time are the provider's recent past,
+			// not a forecast, and would land in the first lead bucket.
+			if p.Time.Before(s.IssuedAt) {
+				continue
+			}
+			observed, ok := truth[truthKey(s.Place, source, s.Variable, p.Time.Round(step))]
+			if !ok {
+				continue
+			}
+			lead, order := leadBucket(s.Provider, p.Time.Sub(s.IssuedAt))
+			key := fmt.Sprintf("%s|%s|%03d", s.Provider, s.Variable, order)
+			m, ok := buckets[key]
+			if !ok {
+				m = &verificationMetric{Provider: s.Provider, Variable: s.Variable, Lead: lead, leadOrder: order}
+				buckets[key] = m
+			}
+			diff := p.Value - observed
+			m.N++
+			m.sum += diff
+			m.sumAbs += math.Abs(diff)
+			m.sumSq += diff * diff
+			if (p.Value >= verifyWetMmH) == (observed >= verifyWetMmH) {
+				m.agreeN++
+			}
+		}
+	}
+
+	out := make([]verificationMetric, 0, len(buckets))
+	for _, m := range buckets {
+		n := float64(m.N)
+		m.MAE, m.Bias, m.RMSE = m.sumAbs/n, m.sum/n, math.Sqrt(m.sumSq/n)
+		m.Agree = -1
+		if m.Variable == varPrecip {
+			m.Agree = float64(m.agreeN) / n
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Variable != b.Variable {
+			return a.Variable < b.Variable
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.leadOrder < b.leadOrder
+	})
+	return out
+}
+
+func truthKey(place, source, variable string, t time.Time) string {
+	return fmt.Sprintf("%s|%s|%s|%d", place, source, variable, t.Unix())
+}
+
+// leadBucket groups a lead time: half-hour buckets for the 2 h nowcasts,
+// whole days for Open-Meteo. order sorts the buckets.
+func leadBucket(provider string, lead time.Duration) (label string, order int) {
+	if provider == providerOpenMeteo {
+		day := int(lead / (24 * time.Hour))
+		return fmt.Sprintf("day %d", day), day
+	}
+	i := int(lead / verifyNowcastLead)
+	from := time.Duration(i) * verifyNowcastLead
+	return fmt.Sprintf("%d–%d min", int(from.Minutes()), int((from + verifyNowcastLead).Minutes())), i
+}
+
+// verificationPlaces lists the distinct places in the snapshots, sorted.
+func verificationPlaces(snaps []forecastSnapshot) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, s := range snaps {
+		if !seen[s.Place] {
+			seen[s.Place] = true
+			out = append(out, s.Place)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// runRecorder records every interval for as long as the process runs.
+// Started by serve --record-every; a failed pass is logged and retried on the
+// next tick.
+func runRecorder(r *recorder, every time.Duration) {
+	tick := time.NewTicker(every)
+	defer tick.Stop()
+	for {
+		stats, err := r.RecordOnce(time.Now())
+		if err != nil {
+			slog.Warn("record: pass failed", "err", err)
+		} else {
+			slog.Debug("record: pass done", "snapshots", stats.Snapshots, "observations", stats.Observations, "errors", stats.Errors)
+		}
+		<-tick.C
+	}
+}
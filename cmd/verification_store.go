@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Providers, observation sources and variables used by the verification
+// store. Precipitation is a rate in mm/h for the nowcasts and radar, and mm
+// over the preceding hour for Open-Meteo — the same number for an hour.
+const (
+	providerBuienalarm = "buienalarm"
+	providerBuienradar = "buienradar"
+	providerOpenMeteo  = "open-meteo"
+
+	sourceRadar   = "radar"              // Buienradar's measured past values
+	sourceArchive = "open-meteo-archive" // reanalysis from the historical API
+
+	varPrecip = "precip"
+	varTemp   = "temp"
+)
+
+// verifyRetention is how long the store keeps snapshots (by issue time) and
+// observations: a season of scores, without the files growing for ever.
+const verifyRetention = 90 * 24 * time.Hour
+
+// forecastSnapshot is one provider's forecast of one variable for one saved
+// place, as it stood at IssuedAt.
+type forecastSnapshot struct {
+	Place    string              `json:"place"`
+	Provider string              `json:"provider"`
+	Variable string              `json:"variable"`
+	IssuedAt time.Time           `json:"issuedAt"`
+	Points   []ForecastDataPoint `json:"points"`
+}
+
+// observation is one measured (or reanalysed) value for a saved place.
+type observation struct {
+	Place    string    `json:"place"`
+	Source   string    `json:"source"`
+	Variable string    `json:"variable"`
+	Time     time.Time `json:"time"`
+	Value    float64   `json:"value"`
+}
+
+func (o observation) key() string {
+	return fmt.Sprintf("%s|%s|%s|%d", o.Place, o.Source, o.Variable, o.Time.Unix())
+}
+
+// verificationStore is the append-only JSONL archive behind forecast
+// verification: forecasts.jsonl holds snapshots and observations.jsonl the
+// values they are scored against, both under dataDir()/verification.
+// Compact trims both to the retention window.
+type verificationStore struct {
+	dir string
+	mu  sync.Mutex // serialises appends from the serve recorder and handlers
+}
+
+// openVerificationStore creates the store directory if needed.
+func openVerificationStore() (*verificationStore, error) {
+	base, err := dataDir()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(base, "verification")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create verification store: %w", err)
+	}
+	return &verificationStore{dir: dir}, nil
+}
+
+func (s *verificationStore) snapshotsPath() string { return filepath.Join(s.dir, "forecasts.jsonl") }
+func (s *verificationStore) observationsPath() string {
+	return filepath.Join(s.dir, "observations.jsonl")
+}
+func (s *verificationStore) compactedPath() string { return filepath.Join(s.dir, "compacted") }
+
+// AppendSnapshots adds snapshots to forecasts.jsonl.
+func (s *verificationStore) AppendSnapshots(snaps []forecastSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return appendJSONL(s.snapshotsPath(), snaps)
+}
+
+// AppendObservations adds observations to observations.jsonl. Callers dedupe
+// first; Observations also drops repeats on read, so a duplicate is harmless.
+func (s *verificationStore) AppendObservations(obs []observation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return appendJSONL(s.observationsPath(), obs)
+}
+
+// Snapshots reads every stored snapshot.
+func (s *verificationStore) Snapshots() ([]forecastSnapshot, error) {
+	return readJSONL[forecastSnapshot](s.snapshotsPath())
+}
+
+// Observations reads every stored observation, keeping the latest value when
+// one was recorded more than once (the radar revises recent frames).
+func (s *verificationStore) Observations() ([]observation, error) {
+	all, err := readJSONL[observation](s.observationsPath())
+	if err != nil {
+		return nil, err
+	}
+	idx := map[string]int{}
+	out := all[:0]
+	for _, o := range all {
+		if i, ok := idx[o.key()]; ok {
+			out[i] = o
+			continue
+		}
+		idx[o.key()] = len(out)
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+// CompactedAt is when Compact last ran, zero if never. It's kept as the
+// modification time of a stamp file, so a record run from cron sees it too.
+func (s *verificationStore) CompactedAt() time.Time {
+	fi, err := os.Stat(s.compactedPath())
+	if err != nil {
+		return time.Time{}
+	}
+	return fi.ModTime()
+}
+
+// Compact rewrites both files without the snapshots issued and the
+// observations taken before cutoff, and without superseded observations.
+// Each file is replaced in one rename, so a reader sees either version.
+func (s *verificationStore) Compact(cutoff time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snaps, err := s.Snapshots()
+	if err != nil {
+		return err
+	}
+	keptSnaps := snaps[:0]
+	for _, sn := range snaps {
+		if !sn.IssuedAt.Before(cutoff) {
+			keptSnaps = append(keptSnaps, sn)
+		}
+	}
+	obs, err := s.Observations()
+	if err != nil {
+		return err
+	}
+	keptObs := obs[:0]
+	for _, o := range obs {
+		if !o.Time.Before(cutoff) {
+			keptObs = append(keptObs, o)
+		}
+	}
+	if err := rewriteJSONL(s.snapshotsPath(), keptSnaps); err != nil {
+		return err
+	}
+	if err := rewriteJSONL(s.observationsPath(), keptObs); err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.compactedPath(), []byte(time.Now().Format(time.RFC3339)+"\n"), 0o644); err != nil {
+		return fmt.Errorf("stamp compaction: %w", err)
+	}
+	return nil
+}
+
+// rewriteJSONL replaces path with recs through a temporary file.
+func rewriteJSONL[T any](path string, recs []T) error {
+	tmp := path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove %s: %w", tmp, err)
+	}
+	if err := appendJSONL(tmp, recs); err != nil {
+		return err
+	}
+	if len(recs) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("remove %s: %w", path, err)
+		}
+		return nil
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace %s: %w", path, err)
+	}
+	return nil
+}
+
+func appendJSONL[T any](path string, recs []T) error {
+	if len(recs) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, r := range recs {
+		if err := enc.Encode(r); err != nil {
+			closeBody(f, path)
+			return fmt.Errorf("encode %s: %w", path, err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		closeBody(f, path)
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", path, err)
+	}
+	return nil
+}
+
+// readJSONL decodes one T per line. A missing file is empty; a corrupt line
+// (say, a write cut short by a crash) is skipped and trace-logged.
+func readJSONL[T any](path string) ([]T, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer closeBody(f, path)
+
+	var out []T
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		var v T
+		if err := json.Unmarshal(sc.Bytes(), &v); err != nil {
+			slog.Log(context.Background(), LevelTrace, "verification: skipping bad line", "file", path, "line", line, "err", err)
+			continue
+		}
+		out = append(out, v)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return out, nil
+}
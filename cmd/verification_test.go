@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestComputeVerification(t *testing.T) {
+	issued := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return issued.Add(d) }
+	snaps := []forecastSnapshot{
+		{Place: "home", Provider: providerBuienalarm, Variable: varPrecip, IssuedAt: issued, Points: []ForecastDataPoint{
+			{Time: at(-5 * time.Minute), Value: 4.0}, // before issue, not a forecast: skipped
+			{Time: at(10 * time.Minute), Value: 1.0},
+			{Time: at(20*time.Minute + 30*time.Second), Value: 0}, // rounds onto the 20 min radar frame
+			{Time: at(45 * time.Minute), Value: 2.0},              // no observation: skipped
+		}},
+		{Place: "home", Provider: providerOpenMeteo, Variable: varTemp, IssuedAt: issued, Points: []ForecastDataPoint{
+			{Time: at(26 * time.Hour), Value: 15},
+		}},
+		{Place: "work", Provider: providerBuienalarm, Variable: varPrecip, IssuedAt: issued, Points: []ForecastDataPoint{
+			{Time: at(10 * time.Minute), Value: 5.0},
+		}},
+	}
+	obs := []observation{
+		{Place: "home", Source: sourceRadar, Variable: varPrecip, Time: at(-5 * time.Minute), Value: 0},
+		{Place: "home", Source: sourceRadar, Variable: varPrecip, Time: at(10 * time.Minute), Value: 0.5},
+		{Place: "home", Source: sourceRadar, Variable: varPrecip, Time: at(20 * time.Minute), Value: 0.5},
+		{Place: "home", Source: sourceArchive, Variable: varTemp, Time: at(26 * time.Hour), Value: 17},
+		{Place: "work", Source: sourceRadar, Variable: varPrecip, Time: at(10 * time.Minute), Value: 5.0},
+	}
+
+	tests := []struct {
+		name     string
+		place    string
+		metric   int
+		provider string
+		lead     string
+		n        int
+		mae      float64
+		bias     float64
+		rmse     float64
+		agree    float64
+	}{
+		{"nowcast", "home", 0, providerBuienalarm, "0–30 min", 2, 0.5, 0, 0.5, 0.5},
+		{"open-meteo temperature", "home", 1, providerOpenMeteo, "day 1", 1, 2, -2, 2, -1},
+		{"all places together", "", 0, providerBuienalarm, "0–30 min", 3, 1.0 / 3, 0, math.Sqrt(0.5 / 3), 2.0 / 3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := computeVerification(snaps, obs, tc.place)
+			if len(got) != 2 {
+				t.Fatalf("computeVerification() returned %d metrics, want 2: %+v", len(got), got)
+			}
+			m := got[tc.metric]
+			if m.Provider != tc.provider || m.Lead != tc.lead || m.N != tc.n {
+				t.Fatalf("metric = %+v, want %s %s over %d pairs", m, tc.provider, tc.lead, tc.n)
+			}
+			for _, v := range []struct {
+				name      string
+				got, want float64
+			}{{"MAE", m.MAE, tc.mae}, {"bias", m.Bias, tc.bias}, {"RMSE", m.RMSE, tc.rmse}, {"agree", m.Agree, tc.agree}} {
+				if math.Abs(v.got-v.want) > 1e-9 {
+					t.Fatalf("%s = %v, want %v", v.name, v.got, v.want)
+				}
+			}
+		})
+	}
+}
+
+func TestVerificationStore(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-verifyRetention - time.Hour)
+	radar := func(at time.Time, v float64) observation {
+		return observation{Place: "home", Source: sourceRadar, Variable: varPrecip, Time: at, Value: v}
+	}
+	tests := []struct {
+		name      string
+		stored    []observation
+		add       []observation
+		appended  int
+		compact   bool
+		wantObs   []float64 // values read back, in store order
+		wantSnaps int
+	}{
+		{"identical value skipped", []observation{radar(now, 1)}, []observation{radar(now, 1)}, 0, false, []float64{1}, 2},
+		{"revision wins", []observation{radar(now, 1)}, []observation{radar(now, 2)}, 1, false, []float64{2}, 2},
+		{"compaction drops old", []observation{radar(old, 1), radar(now, 1)}, []observation{radar(now, 3)}, 1, true, []float64{3}, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &verificationStore{dir: t.TempDir()}
+			snaps := []forecastSnapshot{
+				{Place: "home", Provider: providerBuienalarm, Variable: varPrecip, IssuedAt: old},
+				{Place: "home", Provider: providerBuienalarm, Variable: varPrecip, IssuedAt: now},
+			}
+			if err := store.AppendSnapshots(snaps); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.AppendObservations(tc.stored); err != nil {
+				t.Fatal(err)
+			}
+			r, err := newRecorder(store, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var obs []observation
+			for _, o := range tc.add {
+				obs = r.addObservation(obs, o)
+			}
+			if len(obs) != tc.appended {
+				t.Fatalf("addObservation appended %d, want %d", len(obs), tc.appended)
+			}
+			if err := store.AppendObservations(obs); err != nil {
+				t.Fatal(err)
+			}
+			if tc.compact {
+				if err := store.Compact(now.Add(-verifyRetention)); err != nil {
+					t.Fatal(err)
+				}
+				if store.CompactedAt().IsZero() {
+					t.Fatal("CompactedAt() is zero after Compact")
+				}
+			}
+			got, err := store.Observations()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var vals []float64
+			for _, o := range got {
+				vals = append(vals, o.Value)
+			}
+			if len(vals) != len(tc.wantObs) {
+				t.Fatalf("observations = %v, want %v", vals, tc.wantObs)
+			}
+			for i := range vals {
+				if vals[i] != tc.wantObs[i] {
+					t.Fatalf("observations = %v, want %v", vals, tc.wantObs)
+				}
+			}
+			gotSnaps, err := store.Snapshots()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(gotSnaps) != tc.wantSnaps {
+				t.Fatalf("%d snapshots, want %d", len(gotSnaps), tc.wantSnaps)
+			}
+		})
+	}
+}
@@ -22,12 +22,12 @@ self.addEventListener("fetch", (event) => {
   const url = new URL(req.url);
 
   // Network-first for page navigations (/, /hourly, /forecast, /today,
-  // /multiday), the API, and the live radar GIF; fall back to the last good
+  // /multiday), the API, and the live radar loops; fall back to the last good
   // response when offline. The radar loop refreshes upstream every few
   // minutes, so it must never be served from the long-lived shell cache —
   // stale-while-revalidate there pinned yesterday's frame and a manual
   // refresh just re-served the same stale copy.
-  if (req.mode === "navigate" || url.pathname.startsWith("/api/") || url.pathname === "/radar.gif") {
+  if (req.mode === "navigate" || url.pathname.startsWith("/api/") || url.pathname === "/radar" || url.pathname === "/radar.gif") {
     event.respondWith(
       fetch(req)
         .then((res) => {